@@ -0,0 +1,16 @@
+// Package assets bundles static files used when rendering documents and
+// validating itineraries
+package assets
+
+import "embed"
+
+// Fonts holds the TrueType fonts embedded into generated PDFs
+//
+//go:embed fonts
+var Fonts embed.FS
+
+// Reference holds the airport and airline reference data as CSV files and
+// the default visa rules
+//
+//go:embed reference
+var Reference embed.FS
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"vigovia-task/models"
+	"vigovia-task/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ApprovalHandler handles HTTP requests for itinerary approvals
+type ApprovalHandler struct {
+	service *services.ApprovalService
+}
+
+// NewApprovalHandler creates a new instance of ApprovalHandler
+func NewApprovalHandler(service *services.ApprovalService) *ApprovalHandler {
+	return &ApprovalHandler{
+		service: service,
+	}
+}
+
+// ListApprovals handles GET /itineraries/:id/approvals
+func (h *ApprovalHandler) ListApprovals(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"approvals": h.service.ListApprovals(c.Param("id"))})
+}
+
+// RequestApproval handles POST /itineraries/:id/approvals
+func (h *ApprovalHandler) RequestApproval(c *gin.Context) {
+	var req models.RequestApprovalRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	approval, err := h.service.RequestApproval(c.Param("id"), c.GetString("userID"), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, approval)
+}
+
+// RespondApproval handles POST /itineraries/:id/approvals/:approvalId/respond
+func (h *ApprovalHandler) RespondApproval(c *gin.Context) {
+	var req models.RespondApprovalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	approval, err := h.service.Respond(c.Param("id"), c.Param("approvalId"), c.GetString("userID"), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, approval)
+}
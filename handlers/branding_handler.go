@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"vigovia-task/models"
+	"vigovia-task/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BrandingHandler handles HTTP requests for agency branding profiles
+type BrandingHandler struct {
+	service   *services.BrandingService
+	renderers *services.RendererRegistry
+}
+
+// NewBrandingHandler creates a new instance of BrandingHandler
+func NewBrandingHandler(service *services.BrandingService, renderers *services.RendererRegistry) *BrandingHandler {
+	return &BrandingHandler{
+		service:   service,
+		renderers: renderers,
+	}
+}
+
+// CreateProfile handles POST /branding
+func (h *BrandingHandler) CreateProfile(c *gin.Context) {
+	var req models.BrandingProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	profile, err := h.service.CreateProfile(c.GetString("userID"), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, profile)
+}
+
+// ListProfiles handles GET /branding
+func (h *BrandingHandler) ListProfiles(c *gin.Context) {
+	profiles := h.service.ListProfiles(c.GetString("userID"))
+	c.JSON(http.StatusOK, gin.H{"profiles": profiles})
+}
+
+// GetProfile handles GET /branding/:id
+func (h *BrandingHandler) GetProfile(c *gin.Context) {
+	profile, err := h.service.GetProfile(c.GetString("userID"), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// UpdateProfile handles PUT /branding/:id
+func (h *BrandingHandler) UpdateProfile(c *gin.Context) {
+	var req models.BrandingProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	profile, err := h.service.UpdateProfile(c.GetString("userID"), c.Param("id"), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// DeleteProfile handles DELETE /branding/:id
+func (h *BrandingHandler) DeleteProfile(c *gin.Context) {
+	if err := h.service.DeleteProfile(c.GetString("userID"), c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Branding profile deleted successfully"})
+}
+
+// PreviewProfile handles GET /branding/:id/preview?format=pdf|html|md. The
+// id "default" previews the built-in theme.
+func (h *BrandingHandler) PreviewProfile(c *gin.Context) {
+	profileID := c.Param("id")
+	if profileID == services.DefaultBrandingProfile().ID {
+		profileID = ""
+	}
+
+	theme := services.DefaultBrandingProfile()
+	if profileID != "" {
+		resolved, err := h.service.ResolveTheme(c.GetString("userID"), profileID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		theme = resolved
+	}
+
+	_, renderer, err := h.renderers.Negotiate(c.Query("format"), c.GetHeader("Accept"))
+	if err != nil {
+		c.JSON(negotiationStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	layout, err := pageLayoutFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	document, err := renderer.Render(services.PreviewItinerary(), services.RenderOptions{Theme: theme, Layout: layout})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=branding-preview%s", renderer.FileExtension()))
+	c.Data(http.StatusOK, renderer.ContentType(), document)
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"vigovia-task/models"
+	"vigovia-task/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CatalogueHandler handles HTTP requests for the activity catalogue
+type CatalogueHandler struct {
+	service *services.CatalogueService
+}
+
+// NewCatalogueHandler creates a new instance of CatalogueHandler
+func NewCatalogueHandler(service *services.CatalogueService) *CatalogueHandler {
+	return &CatalogueHandler{
+		service: service,
+	}
+}
+
+// CreateEntry handles POST /catalogue
+func (h *CatalogueHandler) CreateEntry(c *gin.Context) {
+	var req models.CatalogueEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, err := h.service.CreateEntry(c.GetString("userID"), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// Search handles GET /catalogue?q=&tag=&location=&period=
+func (h *CatalogueHandler) Search(c *gin.Context) {
+	entries := h.service.Search(c.GetString("userID"), &models.CatalogueQuery{
+		Query:    c.Query("q"),
+		Tag:      c.Query("tag"),
+		Location: c.Query("location"),
+		Period:   c.Query("period"),
+	})
+	c.JSON(http.StatusOK, gin.H{"catalogue": entries})
+}
+
+// GetEntry handles GET /catalogue/:id
+func (h *CatalogueHandler) GetEntry(c *gin.Context) {
+	entry, err := h.service.GetEntry(c.GetString("userID"), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// UpdateEntry handles PUT /catalogue/:id
+func (h *CatalogueHandler) UpdateEntry(c *gin.Context) {
+	var req models.CatalogueEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, err := h.service.UpdateEntry(c.GetString("userID"), c.Param("id"), &req)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, services.ErrCatalogueEntryReadOnly) {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// DeleteEntry handles DELETE /catalogue/:id
+func (h *CatalogueHandler) DeleteEntry(c *gin.Context) {
+	if err := h.service.DeleteEntry(c.GetString("userID"), c.Param("id")); err != nil {
+		status := http.StatusNotFound
+		if errors.Is(err, services.ErrCatalogueEntryReadOnly) {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Catalogue entry deleted successfully"})
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"vigovia-task/models"
+	"vigovia-task/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CollaboratorHandler handles HTTP requests for sharing itineraries
+type CollaboratorHandler struct {
+	access *services.AccessService
+}
+
+// NewCollaboratorHandler creates a new instance of CollaboratorHandler
+func NewCollaboratorHandler(access *services.AccessService) *CollaboratorHandler {
+	return &CollaboratorHandler{
+		access: access,
+	}
+}
+
+// ListCollaborators handles GET /itineraries/:id/collaborators
+func (h *CollaboratorHandler) ListCollaborators(c *gin.Context) {
+	collaborators, err := h.access.ListCollaborators(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, collaborators)
+}
+
+// InviteCollaborator handles POST /itineraries/:id/collaborators
+func (h *CollaboratorHandler) InviteCollaborator(c *gin.Context) {
+	var req models.InviteCollaboratorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	grant, err := h.access.Invite(c.Param("id"), c.GetString("userID"), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, grant)
+}
+
+// RevokeCollaborator handles DELETE /itineraries/:id/collaborators/:grantId
+func (h *CollaboratorHandler) RevokeCollaborator(c *gin.Context) {
+	err := h.access.Revoke(c.Param("id"), c.GetString("userID"), c.Param("grantId"))
+	switch {
+	case errors.Is(err, services.ErrAccessDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Collaborator removed successfully"})
+}
+
+// ListInvitations handles GET /itineraries/invitations
+func (h *CollaboratorHandler) ListInvitations(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"invitations": h.access.Invitations(c.GetString("userID"))})
+}
+
+// AcceptInvitation handles POST /itineraries/invitations/:grantId/accept
+func (h *CollaboratorHandler) AcceptInvitation(c *gin.Context) {
+	grant, err := h.access.AcceptInvitation(c.GetString("userID"), c.Param("grantId"))
+	if err != nil {
+		invitationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, grant)
+}
+
+// DeclineInvitation handles POST /itineraries/invitations/:grantId/decline
+func (h *CollaboratorHandler) DeclineInvitation(c *gin.Context) {
+	if err := h.access.DeclineInvitation(c.GetString("userID"), c.Param("grantId")); err != nil {
+		invitationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation declined"})
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"vigovia-task/models"
+	"vigovia-task/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CommentHandler handles HTTP requests for itinerary comments
+type CommentHandler struct {
+	service *services.CommentService
+}
+
+// NewCommentHandler creates a new instance of CommentHandler
+func NewCommentHandler(service *services.CommentService) *CommentHandler {
+	return &CommentHandler{
+		service: service,
+	}
+}
+
+// ListComments handles GET /itineraries/:id/comments?day_id=&activity_id=
+func (h *CommentHandler) ListComments(c *gin.Context) {
+	comments := h.service.ListComments(c.Param("id"), c.Query("day_id"), c.Query("activity_id"))
+	c.JSON(http.StatusOK, gin.H{"comments": comments})
+}
+
+// AddComment handles POST /itineraries/:id/comments
+func (h *CommentHandler) AddComment(c *gin.Context) {
+	var req models.CreateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := h.service.AddComment(c.Param("id"), c.GetString("userID"), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+// UpdateComment handles PUT /itineraries/:id/comments/:commentId
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
+	var req models.UpdateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := h.service.UpdateComment(c.Param("id"), c.GetString("userID"), c.Param("commentId"), &req)
+	switch {
+	case errors.Is(err, services.ErrAccessDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+// DeleteComment handles DELETE /itineraries/:id/comments/:commentId
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	err := h.service.DeleteComment(c.Param("id"), c.GetString("userID"), c.Param("commentId"))
+	switch {
+	case errors.Is(err, services.ErrAccessDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted successfully"})
+}
@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"net/http"
+
+	"vigovia-task/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetHotel handles GET /itineraries/:id/hotels/:hotelId
+func (h *ItineraryHandler) GetHotel(c *gin.Context) {
+	component, err := h.service.GetHotel(c.Param("id"), c.Param("hotelId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if !seesInternalNotes(c) {
+		component.InternalNotes = ""
+	}
+
+	c.JSON(http.StatusOK, component)
+}
+
+// AddHotel handles POST /itineraries/:id/hotels
+func (h *ItineraryHandler) AddHotel(c *gin.Context) {
+	var component models.Hotel
+	if err := c.ShouldBindJSON(&component); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	itinerary, err := h.service.AddHotel(c.Param("id"), &component)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, itinerary)
+}
+
+// UpdateHotel handles PUT /itineraries/:id/hotels/:hotelId
+func (h *ItineraryHandler) UpdateHotel(c *gin.Context) {
+	var component models.Hotel
+	if err := c.ShouldBindJSON(&component); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	itinerary, err := h.service.UpdateHotel(c.Param("id"), c.Param("hotelId"), &component)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, itinerary)
+}
+
+// DeleteHotel handles DELETE /itineraries/:id/hotels/:hotelId
+func (h *ItineraryHandler) DeleteHotel(c *gin.Context) {
+	itinerary, err := h.service.DeleteHotel(c.Param("id"), c.Param("hotelId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, itinerary)
+}
+
+// GetFlight handles GET /itineraries/:id/flights/:flightId
+func (h *ItineraryHandler) GetFlight(c *gin.Context) {
+	component, err := h.service.GetFlight(c.Param("id"), c.Param("flightId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if !seesInternalNotes(c) {
+		component.InternalNotes = ""
+	}
+
+	c.JSON(http.StatusOK, component)
+}
+
+// AddFlight handles POST /itineraries/:id/flights
+func (h *ItineraryHandler) AddFlight(c *gin.Context) {
+	var component models.Flight
+	if err := c.ShouldBindJSON(&component); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	itinerary, err := h.service.AddFlight(c.Param("id"), &component)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, itinerary)
+}
+
+// UpdateFlight handles PUT /itineraries/:id/flights/:flightId
+func (h *ItineraryHandler) UpdateFlight(c *gin.Context) {
+	var component models.Flight
+	if err := c.ShouldBindJSON(&component); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	itinerary, err := h.service.UpdateFlight(c.Param("id"), c.Param("flightId"), &component)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, itinerary)
+}
+
+// DeleteFlight handles DELETE /itineraries/:id/flights/:flightId
+func (h *ItineraryHandler) DeleteFlight(c *gin.Context) {
+	itinerary, err := h.service.DeleteFlight(c.Param("id"), c.Param("flightId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, itinerary)
+}
+
+// GetTransfer handles GET /itineraries/:id/transfers/:transferId
+func (h *ItineraryHandler) GetTransfer(c *gin.Context) {
+	component, err := h.service.GetTransfer(c.Param("id"), c.Param("transferId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if !seesInternalNotes(c) {
+		component.InternalNotes = ""
+	}
+
+	c.JSON(http.StatusOK, component)
+}
+
+// AddTransfer handles POST /itineraries/:id/transfers
+func (h *ItineraryHandler) AddTransfer(c *gin.Context) {
+	var component models.Transfer
+	if err := c.ShouldBindJSON(&component); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	itinerary, err := h.service.AddTransfer(c.Param("id"), &component)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, itinerary)
+}
+
+// UpdateTransfer handles PUT /itineraries/:id/transfers/:transferId
+func (h *ItineraryHandler) UpdateTransfer(c *gin.Context) {
+	var component models.Transfer
+	if err := c.ShouldBindJSON(&component); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	itinerary, err := h.service.UpdateTransfer(c.Param("id"), c.Param("transferId"), &component)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, itinerary)
+}
+
+// DeleteTransfer handles DELETE /itineraries/:id/transfers/:transferId
+func (h *ItineraryHandler) DeleteTransfer(c *gin.Context) {
+	itinerary, err := h.service.DeleteTransfer(c.Param("id"), c.Param("transferId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, itinerary)
+}
+
+// GetPaymentInstallment handles GET /itineraries/:id/payments/:paymentId
+func (h *ItineraryHandler) GetPaymentInstallment(c *gin.Context) {
+	component, err := h.service.GetPaymentInstallment(c.Param("id"), c.Param("paymentId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if !seesInternalNotes(c) {
+		component.InternalNotes = ""
+	}
+
+	c.JSON(http.StatusOK, component)
+}
+
+// AddPaymentInstallment handles POST /itineraries/:id/payments
+func (h *ItineraryHandler) AddPaymentInstallment(c *gin.Context) {
+	var component models.PaymentInstallment
+	if err := c.ShouldBindJSON(&component); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	itinerary, err := h.service.AddPaymentInstallment(c.Param("id"), &component)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, itinerary)
+}
+
+// UpdatePaymentInstallment handles PUT /itineraries/:id/payments/:paymentId
+func (h *ItineraryHandler) UpdatePaymentInstallment(c *gin.Context) {
+	var component models.PaymentInstallment
+	if err := c.ShouldBindJSON(&component); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	itinerary, err := h.service.UpdatePaymentInstallment(c.Param("id"), c.Param("paymentId"), &component)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, itinerary)
+}
+
+// DeletePaymentInstallment handles DELETE /itineraries/:id/payments/:paymentId
+func (h *ItineraryHandler) DeletePaymentInstallment(c *gin.Context) {
+	itinerary, err := h.service.DeletePaymentInstallment(c.Param("id"), c.Param("paymentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, itinerary)
+}
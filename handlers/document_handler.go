@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"vigovia-task/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DocumentHandler handles HTTP requests for archived export documents
+type DocumentHandler struct {
+	itineraries *services.ItineraryService
+	documents   *services.DocumentService
+}
+
+// NewDocumentHandler creates a new instance of DocumentHandler
+func NewDocumentHandler(itineraries *services.ItineraryService, documents *services.DocumentService) *DocumentHandler {
+	return &DocumentHandler{
+		itineraries: itineraries,
+		documents:   documents,
+	}
+}
+
+// ListDocuments handles GET /itineraries/:id/documents
+func (h *DocumentHandler) ListDocuments(c *gin.Context) {
+	id := c.Param("id")
+
+	if _, err := h.itineraries.GetItinerary(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	documents := h.documents.ListDocuments(id, seesInternalNotes(c))
+	c.JSON(http.StatusOK, gin.H{"documents": documents})
+}
+
+// DownloadDocument handles GET /itineraries/:id/documents/:documentId
+func (h *DocumentHandler) DownloadDocument(c *gin.Context) {
+	document, data, err := h.documents.OpenDocument(c.Param("id"), c.Param("documentId"), seesInternalNotes(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", document.FileName))
+	c.Header("ETag", fmt.Sprintf("%q", document.Checksum))
+	c.Data(http.StatusOK, document.ContentType, data)
+}
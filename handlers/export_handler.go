@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"vigovia-task/models"
+	"vigovia-task/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportHandler handles HTTP requests for asynchronous export jobs
+type ExportHandler struct {
+	itineraries *services.ItineraryService
+	service     *services.ExportService
+	access      *services.AccessService
+}
+
+// NewExportHandler creates a new instance of ExportHandler
+func NewExportHandler(itineraries *services.ItineraryService, service *services.ExportService, access *services.AccessService) *ExportHandler {
+	return &ExportHandler{
+		itineraries: itineraries,
+		service:     service,
+		access:      access,
+	}
+}
+
+// CreateExport handles POST /itineraries/:id/exports
+func (h *ExportHandler) CreateExport(c *gin.Context) {
+	if _, err := h.itineraries.GetItinerary(c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.ExportJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Internal {
+		if err := services.CheckInternalNotes(c.GetString("itineraryRole")); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	job, err := h.service.Enqueue(c.GetString("userID"), c.Param("id"), &req)
+	respondQueued(c, job, err)
+}
+
+// CreateBulkExport handles POST /itineraries/bulk-exports, which queues the
+// ZIP archive of POST /itineraries/bulk-export as a batch job
+func (h *ExportHandler) CreateBulkExport(c *gin.Context) {
+	var req models.BulkExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	itineraries, ok := bulkExportItineraries(c, h.access, h.itineraries, &req)
+	if !ok {
+		return
+	}
+
+	job, err := h.service.EnqueueBulk(c.GetString("userID"), itineraries, &req)
+	respondQueued(c, job, err)
+}
+
+// respondQueued answers a request that queued an export job
+func respondQueued(c *gin.Context, job *models.ExportJob, err error) {
+	switch {
+	case errors.Is(err, services.ErrExportQueueFull):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrTooManyExports):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Location", fmt.Sprintf("/api/exports/%s", job.ID))
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetExport handles GET /exports/:job
+func (h *ExportHandler) GetExport(c *gin.Context) {
+	job, err := h.service.GetJob(c.GetString("userID"), c.Param("job"))
+	if err != nil {
+		c.JSON(jobErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// DownloadExport handles GET /exports/:job/download
+func (h *ExportHandler) DownloadExport(c *gin.Context) {
+	job, err := h.service.GetJob(c.GetString("userID"), c.Param("job"))
+	if err != nil {
+		c.JSON(jobErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	if job.Status != models.ExportJobSucceeded {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("export job %s is %s", job.ID, job.Status)})
+		return
+	}
+
+	document, data, err := h.service.OpenResult(c.GetString("userID"), job.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", document.FileName))
+	c.Data(http.StatusOK, document.ContentType, data)
+}
+
+// jobErrorStatus answers 403 Forbidden when the user has lost the access a
+// job needs and 404 Not Found otherwise
+func jobErrorStatus(err error) int {
+	if errors.Is(err, services.ErrAccessDenied) {
+		return http.StatusForbidden
+	}
+	return http.StatusNotFound
+}
@@ -1,7 +1,14 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"vigovia-task/models"
 	"vigovia-task/services"
@@ -13,13 +20,23 @@ import (
 type ItineraryHandler struct {
 	service    *services.ItineraryService
 	pdfService *services.PDFService
+	renderers  *services.RendererRegistry
+	branding   *services.BrandingService
+	documents  *services.DocumentService
+	cache      *services.RenderCache
+	access     *services.AccessService
 }
 
 // NewItineraryHandler creates a new instance of ItineraryHandler
-func NewItineraryHandler(service *services.ItineraryService, pdfService *services.PDFService) *ItineraryHandler {
+func NewItineraryHandler(service *services.ItineraryService, pdfService *services.PDFService, renderers *services.RendererRegistry, branding *services.BrandingService, documents *services.DocumentService, cache *services.RenderCache, access *services.AccessService) *ItineraryHandler {
 	return &ItineraryHandler{
 		service:    service,
 		pdfService: pdfService,
+		renderers:  renderers,
+		branding:   branding,
+		documents:  documents,
+		cache:      cache,
+		access:     access,
 	}
 }
 
@@ -60,13 +77,25 @@ func (h *ItineraryHandler) GetItinerary(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
+	if !seesInternalNotes(c) {
+		itinerary = services.ClientView(itinerary)
+	}
 
 	c.JSON(http.StatusOK, itinerary)
 }
 
-// ListItineraries handles GET /itineraries
+// ListItineraries handles GET /itineraries, listing the itineraries the user
+// owns or collaborates on and, for organization members, every itinerary of
+// their organization and the personal ones they kept out of it
 func (h *ItineraryHandler) ListItineraries(c *gin.Context) {
-	itineraries := h.service.ListItineraries()
+	userID := c.GetString("userID")
+	tenant := h.access.Tenant(userID)
+	itineraries := h.service.ListItineraries(tenant)
+	if tenant != "" {
+		itineraries = append(itineraries, h.service.ListItineraries("")...)
+	}
+	itineraries = h.access.Visible(userID, itineraries)
+	itineraries = h.access.HideInternalNotes(userID, itineraries)
 	c.JSON(http.StatusOK, gin.H{"itineraries": itineraries})
 }
 
@@ -80,12 +109,8 @@ func (h *ItineraryHandler) UpdateItinerary(c *gin.Context) {
 		return
 	}
 
-	// Get authenticated user ID from context if available
-	userID, exists := c.Get("userID")
-	if exists {
-		// Override the user_id from request with authenticated user's ID
-		req.UserID = userID.(string)
-	}
+	// Editors update shared itineraries too, so the owner never changes here
+	req.UserID = ""
 
 	itinerary, err := h.service.UpdateItinerary(id, &req)
 	if err != nil {
@@ -132,6 +157,203 @@ func (h *ItineraryHandler) AddActivity(c *gin.Context) {
 	c.JSON(http.StatusOK, itinerary)
 }
 
+// InsertDay handles POST /itineraries/:id/days
+func (h *ItineraryHandler) InsertDay(c *gin.Context) {
+	var req models.InsertDayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	itinerary, err := h.service.InsertDay(c.Param("id"), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, itinerary)
+}
+
+// GetDay handles GET /itineraries/:id/days/:dayId
+func (h *ItineraryHandler) GetDay(c *gin.Context) {
+	day, err := h.service.GetDay(c.Param("id"), c.Param("dayId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if !seesInternalNotes(c) {
+		day = day.WithoutInternalNotes()
+	}
+
+	c.JSON(http.StatusOK, day)
+}
+
+// UpdateDay handles PUT /itineraries/:id/days/:dayId
+func (h *ItineraryHandler) UpdateDay(c *gin.Context) {
+	var day models.DayPlan
+	if err := c.ShouldBindJSON(&day); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	itinerary, err := h.service.UpdateDay(c.Param("id"), c.Param("dayId"), &day)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, itinerary)
+}
+
+// DeleteDay handles DELETE /itineraries/:id/days/:dayId
+func (h *ItineraryHandler) DeleteDay(c *gin.Context) {
+	itinerary, err := h.service.DeleteDay(c.Param("id"), c.Param("dayId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, itinerary)
+}
+
+// ReorderDays handles PUT /itineraries/:id/days/order
+func (h *ItineraryHandler) ReorderDays(c *gin.Context) {
+	var req models.ReorderDaysRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	itinerary, err := h.service.ReorderDays(c.Param("id"), req.Order)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, itinerary)
+}
+
+// SwapDays handles POST /itineraries/:id/days/swap
+func (h *ItineraryHandler) SwapDays(c *gin.Context) {
+	var req models.SwapDaysRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	itinerary, err := h.service.SwapDays(c.Param("id"), req.First, req.Second)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, itinerary)
+}
+
+// GetActivity handles GET /itineraries/:id/activities/:activityId
+func (h *ItineraryHandler) GetActivity(c *gin.Context) {
+	activity, err := h.service.GetActivity(c.Param("id"), c.Param("activityId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if !seesInternalNotes(c) {
+		activity.InternalNotes = ""
+	}
+
+	c.JSON(http.StatusOK, activity)
+}
+
+// UpdateActivity handles PUT /itineraries/:id/activities/:activityId
+func (h *ItineraryHandler) UpdateActivity(c *gin.Context) {
+	var activity models.Activity
+	if err := c.ShouldBindJSON(&activity); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	itinerary, err := h.service.UpdateActivity(c.Param("id"), c.Param("activityId"), &activity)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, itinerary)
+}
+
+// DeleteActivity handles DELETE /itineraries/:id/activities/:activityId
+func (h *ItineraryHandler) DeleteActivity(c *gin.Context) {
+	itinerary, err := h.service.DeleteActivity(c.Param("id"), c.Param("activityId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, itinerary)
+}
+
+// MoveActivity handles POST /itineraries/:id/activities/:activityId/move
+func (h *ItineraryHandler) MoveActivity(c *gin.Context) {
+	var req models.MoveActivityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	itinerary, err := h.service.MoveActivity(c.Param("id"), c.Param("activityId"), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, itinerary)
+}
+
+// CloneItinerary handles POST /itineraries/:id/clone
+func (h *ItineraryHandler) CloneItinerary(c *gin.Context) {
+	var req models.CloneItineraryRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	itinerary, err := h.service.CloneItinerary(c.Param("id"), c.GetString("userID"), seesInternalNotes(c), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, itinerary)
+}
+
+// ProposeTransfers handles GET /itineraries/:id/transfer-proposals
+func (h *ItineraryHandler) ProposeTransfers(c *gin.Context) {
+	proposals, err := h.service.ProposeTransfers(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"proposals": proposals})
+}
+
+// AcceptTransferProposals handles POST /itineraries/:id/transfer-proposals/accept
+func (h *ItineraryHandler) AcceptTransferProposals(c *gin.Context) {
+	var req models.AcceptTransferProposalsRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	itinerary, err := h.service.AcceptTransferProposals(c.Param("id"), req.ProposalIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, itinerary)
+}
+
 // ExportPDF handles GET /itineraries/:id/export-pdf
 func (h *ItineraryHandler) ExportPDF(c *gin.Context) {
 	id := c.Param("id")
@@ -142,12 +364,261 @@ func (h *ItineraryHandler) ExportPDF(c *gin.Context) {
 		return
 	}
 
-	pdfBytes, err := h.pdfService.GeneratePDF(itinerary)
+	theme, err := h.branding.ResolveTheme(itinerary.UserID, c.Query("theme"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	layout, err := pageLayoutFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	internal, ok := operationsCopyFromQuery(c)
+	if !ok {
+		return
+	}
+
+	rendered, err := h.cache.Render(itinerary, services.FormatPDF, h.pdfService, services.RenderOptions{Theme: theme, Layout: layout, Internal: internal})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	if !h.archiveIfRequested(c, itinerary, services.FormatPDF, h.pdfService, rendered.Data, internal) {
+		return
+	}
+	if notModified(c, rendered) {
+		return
+	}
+
 	c.Header("Content-Disposition", "attachment; filename=itinerary.pdf")
-	c.Data(http.StatusOK, "application/pdf", pdfBytes)
+	c.Data(http.StatusOK, "application/pdf", rendered.Data)
+}
+
+// Export handles GET /itineraries/:id/export?format=pdf|html|md
+func (h *ItineraryHandler) Export(c *gin.Context) {
+	id := c.Param("id")
+
+	format, renderer, err := h.renderers.Negotiate(c.Query("format"), c.GetHeader("Accept"))
+	if err != nil {
+		c.JSON(negotiationStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	itinerary, err := h.service.GetItinerary(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	theme, err := h.branding.ResolveTheme(itinerary.UserID, c.Query("theme"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	layout, err := pageLayoutFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	internal, ok := operationsCopyFromQuery(c)
+	if !ok {
+		return
+	}
+
+	rendered, err := h.cache.Render(itinerary, format, renderer, services.RenderOptions{Theme: theme, Layout: layout, Internal: internal})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !h.archiveIfRequested(c, itinerary, format, renderer, rendered.Data, internal) {
+		return
+	}
+	c.Header("Vary", "Accept")
+	if notModified(c, rendered) {
+		return
+	}
+
+	disposition := "attachment"
+	if format == services.FormatHTML {
+		disposition = "inline"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("%s; filename=%s", disposition, services.ExportFileName(itinerary, renderer)))
+	c.Data(http.StatusOK, renderer.ContentType(), rendered.Data)
+}
+
+// negotiationStatus answers 406 when the Accept header refuses every export
+// format and 400 for an unsupported format parameter
+func negotiationStatus(err error) int {
+	if errors.Is(err, services.ErrNotAcceptable) {
+		return http.StatusNotAcceptable
+	}
+	return http.StatusBadRequest
+}
+
+// pageLayoutFromQuery reads the page_size, orientation, margin and layout
+// query parameters of an export request
+func pageLayoutFromQuery(c *gin.Context) (services.PageLayout, error) {
+	return services.NewPageLayout(c.Query("page_size"), c.Query("orientation"), c.Query("margin"), c.Query("layout"))
+}
+
+// operationsCopyFromQuery reads the internal query parameter, which asks for
+// the operations copy of an export. Only roles that may read internal notes
+// get one. It returns false after writing an error response.
+func operationsCopyFromQuery(c *gin.Context) (bool, bool) {
+	internal, err := strconv.ParseBool(c.DefaultQuery("internal", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "internal must be true or false"})
+		return false, false
+	}
+	if internal {
+		if err := services.CheckInternalNotes(c.GetString("itineraryRole")); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return false, false
+		}
+	}
+	return internal, true
+}
+
+// seesInternalNotes reports whether the caller's role on the itinerary named
+// in the path lets them read its internal notes
+func seesInternalNotes(c *gin.Context) bool {
+	return services.SeesInternalNotes(c.GetString("itineraryRole"))
+}
+
+// BulkExport handles POST /itineraries/bulk-export. The ZIP is streamed, so
+// every check happens before the first byte is written.
+func (h *ItineraryHandler) BulkExport(c *gin.Context) {
+	var req models.BulkExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	itineraries, ok := bulkExportItineraries(c, h.access, h.service, &req)
+	if !ok {
+		return
+	}
+
+	margin := ""
+	if req.Margin != 0 {
+		margin = strconv.FormatFloat(req.Margin, 'f', -1, 64)
+	}
+	layout, err := services.NewPageLayout(req.PageSize, req.Orientation, margin, req.Layout)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	items := make([]services.BulkExportItem, 0, len(itineraries))
+	for _, itinerary := range itineraries {
+		theme, err := h.branding.ResolveTheme(itinerary.UserID, req.Theme)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		items = append(items, services.BulkExportItem{Itinerary: itinerary, Options: services.RenderOptions{Theme: theme, Layout: layout, Internal: req.Internal}})
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", services.BulkExportFileName(time.Now())))
+	c.Status(http.StatusOK)
+	if err := h.pdfService.WriteZip(c.Writer, items, h.cache); err != nil {
+		// Headers are already sent; abort the connection so the client sees a
+		// truncated archive rather than a corrupt one
+		log.Printf("bulk export failed: %v", err)
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// bulkExportItineraries resolves the itineraries named or matched by a bulk
+// export request that the user may export. It returns false after writing an
+// error response.
+func bulkExportItineraries(c *gin.Context, access *services.AccessService, itineraries *services.ItineraryService, req *models.BulkExportRequest) ([]*models.Itinerary, bool) {
+	var selected []*models.Itinerary
+	switch {
+	case len(req.IDs) > 0:
+		for _, id := range req.IDs {
+			itinerary, role, err := access.Authorize(id, c.GetString("userID"), models.PermissionView)
+			if err == nil && req.Internal {
+				err = services.CheckInternalNotes(role)
+			}
+			if errors.Is(err, services.ErrAccessDenied) {
+				c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+				return nil, false
+			}
+			if err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return nil, false
+			}
+			selected = append(selected, itinerary)
+		}
+	case req.Filter != nil:
+		userID := c.GetString("userID")
+		selected = access.Visible(userID, itineraries.FindItineraries(access.Tenant(userID), req.Filter))
+		if req.Internal {
+			for _, itinerary := range selected {
+				if err := services.CheckInternalNotes(access.Role(itinerary, userID)); err != nil {
+					c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("itinerary %s: %v", itinerary.ID, err)})
+					return nil, false
+				}
+			}
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ids or filter is required"})
+		return nil, false
+	}
+
+	if len(selected) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no itineraries match the request"})
+		return nil, false
+	}
+	if len(selected) > services.MaxBulkExportItineraries {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("at most %d itineraries can be exported at once", services.MaxBulkExportItineraries)})
+		return nil, false
+	}
+	return selected, true
+}
+
+// archiveIfRequested stores the rendered document when the request carries
+// archive=true and reports the new document ID in the X-Document-ID header.
+// It returns false after writing an error response.
+func (h *ItineraryHandler) archiveIfRequested(c *gin.Context, itinerary *models.Itinerary, format string, renderer services.Renderer, data []byte, internal bool) bool {
+	archive, err := strconv.ParseBool(c.DefaultQuery("archive", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "archive must be true or false"})
+		return false
+	}
+	if !archive {
+		return true
+	}
+
+	document, err := h.documents.Archive(itinerary, format, renderer, data, internal)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+
+	c.Header("X-Document-ID", document.ID)
+	return true
+}
+
+// notModified sets the ETag of a rendered document and answers 304 Not
+// Modified when the client already holds it
+func notModified(c *gin.Context, rendered *services.RenderedDocument) bool {
+	c.Header("ETag", rendered.ETag)
+	for _, tag := range strings.Split(c.GetHeader("If-None-Match"), ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == rendered.ETag || tag == "*" {
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
 }
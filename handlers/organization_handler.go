@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"vigovia-task/models"
+	"vigovia-task/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OrganizationHandler handles HTTP requests for organizations and their
+// members
+type OrganizationHandler struct {
+	service *services.OrganizationService
+}
+
+// NewOrganizationHandler creates a new instance of OrganizationHandler
+func NewOrganizationHandler(service *services.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{
+		service: service,
+	}
+}
+
+// CreateOrganization handles POST /organizations
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	var req models.OrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	organization, err := h.service.CreateOrganization(c.GetString("userID"), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, organization)
+}
+
+// GetOrganization handles GET /organizations/:id
+func (h *OrganizationHandler) GetOrganization(c *gin.Context) {
+	organization, err := h.service.GetOrganization(c.GetString("userID"), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, organization)
+}
+
+// UpdateOrganization handles PUT /organizations/:id
+func (h *OrganizationHandler) UpdateOrganization(c *gin.Context) {
+	var req models.OrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	organization, err := h.service.UpdateOrganization(c.GetString("userID"), c.Param("id"), &req)
+	if err != nil {
+		organizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, organization)
+}
+
+// ListMembers handles GET /organizations/:id/members
+func (h *OrganizationHandler) ListMembers(c *gin.Context) {
+	members, err := h.service.ListMembers(c.GetString("userID"), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"members": members})
+}
+
+// InviteMember handles POST /organizations/:id/invitations
+func (h *OrganizationHandler) InviteMember(c *gin.Context) {
+	var req models.InviteMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	invitation, err := h.service.InviteMember(c.GetString("userID"), c.Param("id"), &req)
+	if err != nil {
+		organizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitation)
+}
+
+// ListInvitations handles GET /organizations/:id/invitations
+func (h *OrganizationHandler) ListInvitations(c *gin.Context) {
+	invitations, err := h.service.ListInvitations(c.GetString("userID"), c.Param("id"))
+	if err != nil {
+		organizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invitations": invitations})
+}
+
+// RevokeInvitation handles DELETE /organizations/:id/invitations/:invitationId
+func (h *OrganizationHandler) RevokeInvitation(c *gin.Context) {
+	if err := h.service.RevokeInvitation(c.GetString("userID"), c.Param("id"), c.Param("invitationId")); err != nil {
+		organizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation revoked successfully"})
+}
+
+// UserInvitations handles GET /organizations/invitations, listing the
+// invitations sent to the user's email
+func (h *OrganizationHandler) UserInvitations(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"invitations": h.service.UserInvitations(c.GetString("userID"))})
+}
+
+// AcceptInvitation handles POST /organizations/invitations/:invitationId/accept
+func (h *OrganizationHandler) AcceptInvitation(c *gin.Context) {
+	var req models.AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, err := h.service.AcceptInvitation(c.GetString("userID"), c.Param("invitationId"), &req)
+	if err != nil {
+		invitationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+// DeclineInvitation handles POST /organizations/invitations/:invitationId/decline
+func (h *OrganizationHandler) DeclineInvitation(c *gin.Context) {
+	if err := h.service.DeclineInvitation(c.GetString("userID"), c.Param("invitationId")); err != nil {
+		invitationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation declined"})
+}
+
+// UpdateMember handles PUT /organizations/:id/members/:userId
+func (h *OrganizationHandler) UpdateMember(c *gin.Context) {
+	var req models.UpdateMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, err := h.service.UpdateMember(c.GetString("userID"), c.Param("id"), c.Param("userId"), &req)
+	if err != nil {
+		organizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+// RemoveMember handles DELETE /organizations/:id/members/:userId
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	if err := h.service.RemoveMember(c.GetString("userID"), c.Param("id"), c.Param("userId")); err != nil {
+		organizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+}
+
+// ListItineraries handles GET /organizations/:id/itineraries?user_id=
+func (h *OrganizationHandler) ListItineraries(c *gin.Context) {
+	itineraries, err := h.service.ListItineraries(c.GetString("userID"), c.Param("id"), c.Query("user_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"itineraries": itineraries})
+}
+
+// organizationError answers 403 for agents attempting admin actions and 400
+// for anything else that stops a change
+func organizationError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrOrganizationAdminRequired) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
+
+// invitationError answers 404 for an invitation the user cannot see and 400
+// for anything else that stops an answer
+func invitationError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrInvitationNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"vigovia-task/models"
+	"vigovia-task/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ShareHandler handles HTTP requests for share links and the public views
+// they open
+type ShareHandler struct {
+	shares    *services.ShareService
+	renderers *services.RendererRegistry
+	branding  *services.BrandingService
+}
+
+// NewShareHandler creates a new instance of ShareHandler
+func NewShareHandler(shares *services.ShareService, renderers *services.RendererRegistry, branding *services.BrandingService) *ShareHandler {
+	return &ShareHandler{
+		shares:    shares,
+		renderers: renderers,
+		branding:  branding,
+	}
+}
+
+// CreateShareLink handles POST /itineraries/:id/share-links
+func (h *ShareHandler) CreateShareLink(c *gin.Context) {
+	var req models.CreateShareLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	link, err := h.shares.CreateLink(c.Param("id"), c.GetString("userID"), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, link)
+}
+
+// ListShareLinks handles GET /itineraries/:id/share-links
+func (h *ShareHandler) ListShareLinks(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"share_links": h.shares.ListLinks(c.Param("id"))})
+}
+
+// RevokeShareLink handles DELETE /itineraries/:id/share-links/:linkId
+func (h *ShareHandler) RevokeShareLink(c *gin.Context) {
+	link, err := h.shares.RevokeLink(c.Param("id"), c.Param("linkId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, link)
+}
+
+// ViewSharedItinerary handles GET /share/:token?format=json|html|pdf|md.
+// Without a format the Accept header chooses, and JSON is served when it
+// names no document format.
+func (h *ShareHandler) ViewSharedItinerary(c *gin.Context) {
+	var renderer services.Renderer
+	switch format := strings.ToLower(strings.TrimSpace(c.Query("format"))); format {
+	case "json":
+	case "":
+		// A header refusing every document format still accepts JSON
+		_, renderer, _ = h.renderers.FromAccept(c.GetHeader("Accept"))
+	default:
+		var err error
+		if renderer, err = h.renderers.Get(format); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	layout, err := pageLayoutFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	itinerary, ownerID, err := h.shares.Open(c.Param("token"))
+	switch {
+	case errors.Is(err, services.ErrShareLinkRevoked), errors.Is(err, services.ErrShareLinkExpired):
+		c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Shared views must not be cached by browsers or proxies, so that a
+	// revoked link stops working at once
+	c.Header("Cache-Control", "no-store")
+	c.Header("Vary", "Accept")
+	if renderer == nil {
+		c.JSON(http.StatusOK, itinerary)
+		return
+	}
+
+	theme, err := h.branding.ResolveTheme(ownerID, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Redacted copies bypass the render cache, which is keyed by itinerary
+	// revision and would otherwise mix them up with full exports
+	data, err := renderer.Render(itinerary, services.RenderOptions{Theme: theme, Layout: layout})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%s", services.ExportFileName(itinerary, renderer)))
+	c.Data(http.StatusOK, renderer.ContentType(), data)
+}
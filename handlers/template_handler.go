@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net/http"
+
+	"vigovia-task/models"
+	"vigovia-task/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TemplateHandler handles HTTP requests for itinerary templates
+type TemplateHandler struct {
+	service *services.TemplateService
+}
+
+// NewTemplateHandler creates a new instance of TemplateHandler
+func NewTemplateHandler(service *services.TemplateService) *TemplateHandler {
+	return &TemplateHandler{
+		service: service,
+	}
+}
+
+// CreateTemplate handles POST /templates
+func (h *TemplateHandler) CreateTemplate(c *gin.Context) {
+	var req models.CreateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template, err := h.service.CreateTemplate(c.GetString("userID"), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// ListTemplates handles GET /templates?q=&tag=
+func (h *TemplateHandler) ListTemplates(c *gin.Context) {
+	templates := h.service.ListTemplates(c.GetString("userID"), c.Query("q"), c.Query("tag"))
+	c.JSON(http.StatusOK, gin.H{"templates": templates})
+}
+
+// GetTemplate handles GET /templates/:id
+func (h *TemplateHandler) GetTemplate(c *gin.Context) {
+	template, err := h.service.GetTemplate(c.GetString("userID"), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// DeleteTemplate handles DELETE /templates/:id
+func (h *TemplateHandler) DeleteTemplate(c *gin.Context) {
+	if err := h.service.DeleteTemplate(c.GetString("userID"), c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Template deleted successfully"})
+}
+
+// Instantiate handles POST /templates/:id/instantiate
+func (h *TemplateHandler) Instantiate(c *gin.Context) {
+	var req models.InstantiateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	itinerary, err := h.service.Instantiate(c.GetString("userID"), c.Param("id"), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, itinerary)
+}
@@ -12,7 +12,9 @@ func main() {
 
 	router := gin.Default()
 
-	routes.RegisterItineraryRoutes(router)
+	if err := routes.RegisterItineraryRoutes(router); err != nil {
+		log.Fatalf("Failed to configure routes: %v", err)
+	}
 
 	log.Printf("Starting Itinerary Builder API on http://localhost:8080\n")
 
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"vigovia-task/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ItineraryAccess lets a request through only when the authenticated user's
+// role on the itinerary named by the :id path parameter carries the
+// permission. The role is stored in the context as "itineraryRole".
+func ItineraryAccess(access *services.AccessService, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_, role, err := access.Authorize(c.Param("id"), c.GetString("userID"), permission)
+		if errors.Is(err, services.ErrAccessDenied) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+
+		c.Set("itineraryRole", role)
+		c.Next()
+	}
+}
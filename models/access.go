@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Roles a user can hold on an itinerary. The owner is the itinerary's UserID;
+// admins and agents of its organization hold the admin and viewer roles; the
+// others are given by access grants.
+const (
+	RoleOwner    = "owner"
+	RoleAdmin    = "admin"
+	RoleEditor   = "editor"
+	RoleApprover = "approver"
+	RoleViewer   = "viewer"
+)
+
+// Permissions checked against a user's role on an itinerary
+const (
+	PermissionView    = "view"    // read the itinerary and export it
+	PermissionEdit    = "edit"    // change the itinerary's content
+	PermissionApprove = "approve" // sign off on the itinerary
+	PermissionManage  = "manage"  // share or delete the itinerary
+)
+
+// Access grant statuses
+const (
+	GrantPending = "pending" // the invitee has not accepted yet
+	GrantActive  = "active"
+)
+
+// AccessGrant gives a collaborator a role on an itinerary. Collaborators are
+// invited by email, so a grant can be made before the invitee has an account,
+// and only applies once the account using the email accepts it.
+type AccessGrant struct {
+	ID          string    `json:"id"`
+	ItineraryID string    `json:"itinerary_id"`
+	Email       string    `json:"email"`
+	UserID      string    `json:"user_id,omitempty"`
+	Role        string    `json:"role"`
+	Status      string    `json:"status"`
+	InvitedBy   string    `json:"invited_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// InviteCollaboratorRequest invites someone to an itinerary by email. Inviting
+// an email that already has a grant changes its role.
+type InviteCollaboratorRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required"`
+}
+
+// Collaborators lists who can access an itinerary
+type Collaborators struct {
+	Owner         *UserResponse  `json:"owner"`
+	Collaborators []*AccessGrant `json:"collaborators"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Approval statuses. An itinerary's approval status is that of its latest
+// approval request, or empty once a newer revision is saved.
+const (
+	ApprovalPending          = "pending"
+	ApprovalApproved         = "approved"
+	ApprovalChangesRequested = "changes_requested"
+	ApprovalSuperseded       = "superseded" // a newer request or revision replaced it before anyone answered
+)
+
+// Approval asks for sign-off on one revision of an itinerary and records
+// the answer
+type Approval struct {
+	ID              string    `json:"id"`
+	ItineraryID     string    `json:"itinerary_id"`
+	Revision        int       `json:"revision"`
+	Status          string    `json:"status"`
+	Message         string    `json:"message,omitempty"`
+	RequestedBy     string    `json:"requested_by"`
+	RespondedBy     string    `json:"responded_by,omitempty"`
+	ResponseComment string    `json:"response_comment,omitempty"`
+	RespondedAt     time.Time `json:"responded_at,omitzero"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// RequestApprovalRequest asks for approval of an itinerary's current
+// revision
+type RequestApprovalRequest struct {
+	Message string `json:"message"`
+}
+
+// RespondApprovalRequest answers an approval request. Requesting changes
+// needs a comment saying which.
+type RespondApprovalRequest struct {
+	Decision string `json:"decision" binding:"required"`
+	Comment  string `json:"comment"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// BrandingProfile captures an agency's look and feel for exported documents
+type BrandingProfile struct {
+	ID                 string         `json:"id"`
+	UserID             string         `json:"user_id"`
+	Name               string         `json:"name"`
+	IsDefault          bool           `json:"is_default"`
+	Logo               []byte         `json:"logo,omitempty"`
+	LogoFormat         string         `json:"logo_format,omitempty"`
+	Colors             BrandingColors `json:"colors"`
+	Fonts              BrandingFonts  `json:"fonts"`
+	FooterText         string         `json:"footer_text"`
+	Contact            ContactDetails `json:"contact"`
+	TermsAndConditions string         `json:"terms_and_conditions"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+}
+
+// BrandingColors is a palette of hex colours such as "#2980B9"
+type BrandingColors struct {
+	Primary string `json:"primary"`
+	Accent  string `json:"accent"`
+	Heading string `json:"heading"`
+	Divider string `json:"divider"`
+}
+
+// BrandingFonts holds an optional custom TrueType family for PDF output
+type BrandingFonts struct {
+	Family  string `json:"family,omitempty"`
+	Regular []byte `json:"regular,omitempty"`
+	Bold    []byte `json:"bold,omitempty"`
+}
+
+// ContactDetails are the agency contact details printed on documents
+type ContactDetails struct {
+	Phone   string `json:"phone,omitempty"`
+	Email   string `json:"email,omitempty"`
+	Website string `json:"website,omitempty"`
+	Address string `json:"address,omitempty"`
+}
+
+// IsEmpty reports whether no contact detail has been provided
+func (c ContactDetails) IsEmpty() bool {
+	return c.Phone == "" && c.Email == "" && c.Website == "" && c.Address == ""
+}
+
+// BrandingProfileRequest is the request payload for creating or updating a branding profile
+type BrandingProfileRequest struct {
+	Name               string         `json:"name" binding:"required"`
+	IsDefault          bool           `json:"is_default"`
+	Logo               []byte         `json:"logo"`
+	Colors             BrandingColors `json:"colors"`
+	Fonts              BrandingFonts  `json:"fonts"`
+	FooterText         string         `json:"footer_text"`
+	Contact            ContactDetails `json:"contact"`
+	TermsAndConditions string         `json:"terms_and_conditions"`
+}
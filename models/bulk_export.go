@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// ItineraryFilter selects itineraries for a bulk export. Empty fields match
+// every itinerary.
+type ItineraryFilter struct {
+	UserID    string    `json:"user_id"`
+	Location  string    `json:"location"`
+	Title     string    `json:"title"`
+	StartFrom time.Time `json:"start_from"`
+	StartTo   time.Time `json:"start_to"`
+}
+
+// BulkExportRequest is the body of POST /itineraries/bulk-export. Either IDs
+// or Filter selects the itineraries.
+type BulkExportRequest struct {
+	IDs         []string         `json:"ids"`
+	Filter      *ItineraryFilter `json:"filter"`
+	Theme       string           `json:"theme"`
+	PageSize    string           `json:"page_size"`
+	Orientation string           `json:"orientation"`
+	Margin      float64          `json:"margin"`
+	Layout      string           `json:"layout"`
+	Internal    bool             `json:"internal"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// CatalogueEntry is a reusable activity or point of interest. Entries belong
+// to an organization's catalogue, or to the creator's own catalogue when the
+// creator is in no organization.
+type CatalogueEntry struct {
+	ID             string    `json:"id"`
+	OrganizationID string    `json:"organization_id,omitempty"`
+	Title          string    `json:"title"`
+	Description    string    `json:"description"`
+	Location       string    `json:"location"`
+	Duration       string    `json:"duration,omitempty"`
+	Period         string    `json:"period,omitempty"`
+	Price          float64   `json:"price,omitempty"`
+	Currency       string    `json:"currency,omitempty"`
+	Tags           []string  `json:"tags,omitempty"`
+	Coordinates    *GeoPoint `json:"coordinates,omitempty"`
+	CreatedBy      string    `json:"created_by"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// InCatalogueOf reports whether the entry is in the catalogue of an
+// organization, or of userID when organizationID is empty
+func (e *CatalogueEntry) InCatalogueOf(organizationID, userID string) bool {
+	if e.OrganizationID != "" || organizationID != "" {
+		return e.OrganizationID == organizationID
+	}
+	return e.CreatedBy == userID
+}
+
+// CatalogueEntryRequest creates or replaces a catalogue entry
+type CatalogueEntryRequest struct {
+	Title       string    `json:"title" binding:"required"`
+	Description string    `json:"description"`
+	Location    string    `json:"location"`
+	Duration    string    `json:"duration"`
+	Period      string    `json:"period"`
+	Price       float64   `json:"price"`
+	Currency    string    `json:"currency"`
+	Tags        []string  `json:"tags"`
+	Coordinates *GeoPoint `json:"coordinates"`
+}
+
+// CatalogueQuery searches a catalogue. Query matches the title, description,
+// location and tags; empty fields match every entry.
+type CatalogueQuery struct {
+	Query    string
+	Tag      string
+	Location string
+	Period   string
+}
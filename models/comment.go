@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Comment is a remark on an itinerary, or on one of its days or activities.
+// A reply names the comment it answers and shares its target.
+type Comment struct {
+	ID          string     `json:"id"`
+	ItineraryID string     `json:"itinerary_id"`
+	ParentID    string     `json:"parent_id,omitempty"`
+	DayID       string     `json:"day_id,omitempty"`
+	ActivityID  string     `json:"activity_id,omitempty"`
+	AuthorID    string     `json:"author_id"`
+	AuthorName  string     `json:"author_name"`
+	Body        string     `json:"body"`
+	Replies     []*Comment `json:"replies,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// CreateCommentRequest posts a comment. Without a day or activity ID the
+// comment is on the whole itinerary; replies take their target from the
+// comment they answer.
+type CreateCommentRequest struct {
+	Body       string `json:"body" binding:"required"`
+	ParentID   string `json:"parent_id"`
+	DayID      string `json:"day_id"`
+	ActivityID string `json:"activity_id"`
+}
+
+// UpdateCommentRequest changes a comment's text
+type UpdateCommentRequest struct {
+	Body string `json:"body" binding:"required"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Document records an exported file kept in document storage.
+type Document struct {
+	ID          string    `json:"id"`
+	ItineraryID string    `json:"itinerary_id"`
+	UserID      string    `json:"user_id"`
+	Format      string    `json:"format"`
+	Internal    bool      `json:"internal,omitempty"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Checksum    string    `json:"checksum"`
+	StorageKey  string    `json:"storage_key"`
+	CreatedAt   time.Time `json:"created_at"`
+}
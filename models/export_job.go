@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// ExportJob status values
+const (
+	ExportJobQueued    = "queued"
+	ExportJobRunning   = "running"
+	ExportJobSucceeded = "succeeded"
+	ExportJobFailed    = "failed"
+)
+
+// ExportJob tracks an asynchronous export of an itinerary, or of several
+// itineraries into one ZIP archive for a batch job.
+type ExportJob struct {
+	ID           string     `json:"id"`
+	ItineraryID  string     `json:"itinerary_id,omitempty"`
+	ItineraryIDs []string   `json:"itinerary_ids,omitempty"`
+	UserID       string     `json:"user_id"`
+	Format       string     `json:"format"`
+	Internal     bool       `json:"internal,omitempty"`
+	Status       string     `json:"status"`
+	Progress     int        `json:"progress"`
+	Attempts     int        `json:"attempts"`
+	MaxAttempts  int        `json:"max_attempts"`
+	Error        string     `json:"error,omitempty"`
+	DocumentID   string     `json:"document_id,omitempty"`
+	DownloadURL  string     `json:"download_url,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	StartedAt    *time.Time `json:"started_at,omitempty"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+}
+
+// Itineraries returns the IDs of the itineraries the job exports
+func (j *ExportJob) Itineraries() []string {
+	if j.ItineraryIDs != nil {
+		return j.ItineraryIDs
+	}
+	return []string{j.ItineraryID}
+}
+
+// ExportJobRequest is the optional body of POST /itineraries/:id/exports.
+// Internal asks for the operations copy with internal notes.
+type ExportJobRequest struct {
+	Format      string  `json:"format"`
+	Theme       string  `json:"theme"`
+	PageSize    string  `json:"page_size"`
+	Orientation string  `json:"orientation"`
+	Margin      float64 `json:"margin"`
+	Layout      string  `json:"layout"`
+	Internal    bool    `json:"internal"`
+}
@@ -11,111 +11,367 @@ const (
 
 // Itinerary represents a complete travel plan with all supporting sections.
 type Itinerary struct {
-	ID          string               `json:"id"`
-	UserID      string               `json:"user_id"`
-	Title       string               `json:"title"`
-	Description string               `json:"description"`
-	StartDate   time.Time            `json:"start_date"`
-	EndDate     time.Time            `json:"end_date"`
-	Location    string               `json:"location"`
-	Hotels      []Hotel              `json:"hotels"`
-	Flights     []Flight             `json:"flights"`
-	Transfers   []Transfer           `json:"transfers"`
-	Days        []DayPlan            `json:"days"`
-	PaymentPlan []PaymentInstallment `json:"payment_plan"`
-	Inclusions  []string             `json:"inclusions"`
-	Exclusions  []string             `json:"exclusions"`
-	CreatedAt   time.Time            `json:"created_at"`
-	UpdatedAt   time.Time            `json:"updated_at"`
+	ID             string               `json:"id"`
+	UserID         string               `json:"user_id"`
+	OrganizationID string               `json:"organization_id,omitempty"`
+	Title          string               `json:"title"`
+	Description    string               `json:"description"`
+	StartDate      time.Time            `json:"start_date"`
+	EndDate        time.Time            `json:"end_date"`
+	Location       string               `json:"location"`
+	Destination    string               `json:"destination_country,omitempty"`
+	Hotels         []Hotel              `json:"hotels"`
+	Flights        []Flight             `json:"flights"`
+	Transfers      []Transfer           `json:"transfers"`
+	Days           []DayPlan            `json:"days"`
+	PaymentPlan    []PaymentInstallment `json:"payment_plan"`
+	Inclusions     []string             `json:"inclusions"`
+	Exclusions     []string             `json:"exclusions"`
+	Travellers     []Traveller          `json:"travellers"`
+	Journeys       []Journey            `json:"journeys,omitempty"`
+	Warnings       []TravelWarning      `json:"warnings,omitempty"`
+	InternalNotes  string               `json:"internal_notes,omitempty"`
+	Revision       int                  `json:"revision"`
+	ApprovalStatus string               `json:"approval_status,omitempty"`
+	CreatedAt      time.Time            `json:"created_at"`
+	UpdatedAt      time.Time            `json:"updated_at"`
 }
 
+// WithoutInternalNotes returns a copy of the itinerary with the agency's
+// internal notes removed from it and from every component. The copy shares
+// no component slices with the original.
+func (i *Itinerary) WithoutInternalNotes() *Itinerary {
+	stripped := *i
+	stripped.InternalNotes = ""
+
+	stripped.Hotels = append([]Hotel(nil), i.Hotels...)
+	for idx := range stripped.Hotels {
+		stripped.Hotels[idx].InternalNotes = ""
+	}
+	stripped.Flights = append([]Flight(nil), i.Flights...)
+	for idx := range stripped.Flights {
+		stripped.Flights[idx].InternalNotes = ""
+	}
+	stripped.Transfers = append([]Transfer(nil), i.Transfers...)
+	for idx := range stripped.Transfers {
+		stripped.Transfers[idx].InternalNotes = ""
+	}
+	stripped.PaymentPlan = append([]PaymentInstallment(nil), i.PaymentPlan...)
+	for idx := range stripped.PaymentPlan {
+		stripped.PaymentPlan[idx].InternalNotes = ""
+	}
+	stripped.Days = make([]DayPlan, len(i.Days))
+	for idx := range i.Days {
+		stripped.Days[idx] = *i.Days[idx].WithoutInternalNotes()
+	}
+	return &stripped
+}
+
+// WithoutInternalNotes returns a copy of the day plan with the internal
+// notes removed from it and from its activities
+func (d *DayPlan) WithoutInternalNotes() *DayPlan {
+	stripped := *d
+	stripped.InternalNotes = ""
+	stripped.Activities = append([]Activity(nil), d.Activities...)
+	for idx := range stripped.Activities {
+		stripped.Activities[idx].InternalNotes = ""
+	}
+	return &stripped
+}
+
+// Meal plans offered with a hotel stay
+const (
+	MealPlanEP  = "EP"  // European plan: room only
+	MealPlanCP  = "CP"  // Continental plan: breakfast
+	MealPlanMAP = "MAP" // Modified American plan: breakfast and one meal
+	MealPlanAP  = "AP"  // American plan: all meals
+)
+
 // Hotel captures accommodation details inside an itinerary.
 type Hotel struct {
-	Name     string    `json:"name"`
-	City     string    `json:"city"`
-	CheckIn  time.Time `json:"check_in"`
-	CheckOut time.Time `json:"check_out"`
-	Nights   int       `json:"nights"`
+	ID                 string      `json:"id"`
+	Name               string      `json:"name"`
+	City               string      `json:"city"`
+	Address            string      `json:"address,omitempty"`
+	Phone              string      `json:"phone,omitempty"`
+	Coordinates        *GeoPoint   `json:"coordinates,omitempty"`
+	CheckIn            time.Time   `json:"check_in"`
+	CheckOut           time.Time   `json:"check_out"`
+	Nights             int         `json:"nights"`
+	MealPlan           string      `json:"meal_plan,omitempty"`
+	ConfirmationNumber string      `json:"confirmation_number,omitempty"`
+	Rooms              []HotelRoom `json:"rooms,omitempty"`
+	InternalNotes      string      `json:"internal_notes,omitempty"`
+}
+
+// HotelRoom is a booked room, or Quantity identical rooms, of a hotel stay
+// with the travellers staying in it.
+type HotelRoom struct {
+	Label        string   `json:"label"`
+	RoomType     string   `json:"room_type,omitempty"`
+	Quantity     int      `json:"quantity,omitempty"`
+	Occupancy    int      `json:"occupancy,omitempty"`
+	TravellerIDs []string `json:"traveller_ids,omitempty"`
+}
+
+// Capacity returns the number of guests the rooms sleep, or 0 when the
+// occupancy is not known.
+func (r HotelRoom) Capacity() int {
+	return r.Quantity * r.Occupancy
+}
+
+// GeoPoint is a WGS84 latitude and longitude in decimal degrees.
+type GeoPoint struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
 }
 
-// Flight captures air travel segments of an itinerary.
+// Cabin classes of a flight
+const (
+	CabinEconomy        = "economy"
+	CabinPremiumEconomy = "premium_economy"
+	CabinBusiness       = "business"
+	CabinFirst          = "first"
+)
+
+// Flight captures air travel segments of an itinerary. Flights sharing a
+// JourneyID are legs of one connecting journey.
 type Flight struct {
-	Airline          string    `json:"airline"`
-	FlightNumber     string    `json:"flight_number"`
-	DepartureCity    string    `json:"departure_city"`
-	DepartureAirport string    `json:"departure_airport"`
-	DepartureTime    time.Time `json:"departure_time"`
-	ArrivalCity      string    `json:"arrival_city"`
-	ArrivalAirport   string    `json:"arrival_airport"`
-	ArrivalTime      time.Time `json:"arrival_time"`
+	ID                string    `json:"id"`
+	Airline           string    `json:"airline"`
+	FlightNumber      string    `json:"flight_number"`
+	JourneyID         string    `json:"journey_id,omitempty"`
+	BookingReference  string    `json:"booking_reference,omitempty"`
+	CabinClass        string    `json:"cabin_class,omitempty"`
+	Seat              string    `json:"seat,omitempty"`
+	BaggageAllowance  string    `json:"baggage_allowance,omitempty"`
+	DepartureCity     string    `json:"departure_city"`
+	DepartureAirport  string    `json:"departure_airport"`
+	DepartureTerminal string    `json:"departure_terminal,omitempty"`
+	DepartureTime     time.Time `json:"departure_time"`
+	ArrivalCity       string    `json:"arrival_city"`
+	ArrivalAirport    string    `json:"arrival_airport"`
+	ArrivalTerminal   string    `json:"arrival_terminal,omitempty"`
+	ArrivalTime       time.Time `json:"arrival_time"`
+	TravellerIDs      []string  `json:"traveller_ids,omitempty"`
+	InternalNotes     string    `json:"internal_notes,omitempty"`
 }
 
-// Transfer represents a ground transfer such as car or shuttle.
+// Journey is a connecting trip made of the flights sharing a JourneyID, in
+// departure order. It is derived from the flights whenever they are saved.
+type Journey struct {
+	ID            string    `json:"id"`
+	FlightIndexes []int     `json:"flight_indexes"`
+	Origin        string    `json:"origin"`
+	Destination   string    `json:"destination"`
+	DepartureTime time.Time `json:"departure_time"`
+	ArrivalTime   time.Time `json:"arrival_time"`
+	Layovers      []Layover `json:"layovers,omitempty"`
+}
+
+// Layover is the connection between two legs of a journey.
+type Layover struct {
+	ArrivalAirport           string `json:"arrival_airport"`
+	DepartureAirport         string `json:"departure_airport"`
+	DurationMinutes          int    `json:"duration_minutes"`
+	MinimumConnectionMinutes int    `json:"minimum_connection_minutes"`
+}
+
+// ChangesAirport reports whether the connection needs a transfer between airports.
+func (l Layover) ChangesAirport() bool {
+	return l.ArrivalAirport != l.DepartureAirport
+}
+
+// Traveller is a passenger on the itinerary. Passport number, date of birth
+// and emergency contact phone are masked in list responses.
+type Traveller struct {
+	ID                  string           `json:"id"`
+	FirstName           string           `json:"first_name"`
+	LastName            string           `json:"last_name"`
+	DateOfBirth         time.Time        `json:"date_of_birth,omitzero"`
+	Nationality         string           `json:"nationality"`
+	PassportNumber      string           `json:"passport_number"`
+	PassportExpiry      time.Time        `json:"passport_expiry"`
+	DietaryRequirements string           `json:"dietary_requirements,omitempty"`
+	AccessibilityNeeds  string           `json:"accessibility_needs,omitempty"`
+	EmergencyContact    EmergencyContact `json:"emergency_contact"`
+}
+
+// FullName returns the traveller's first and last name.
+func (t Traveller) FullName() string {
+	return t.FirstName + " " + t.LastName
+}
+
+// EmergencyContact is the person to call if something happens to a traveller.
+type EmergencyContact struct {
+	Name         string `json:"name"`
+	Relationship string `json:"relationship"`
+	Phone        string `json:"phone"`
+}
+
+// Vehicle types of a transfer
+const (
+	VehicleSedan   = "sedan"
+	VehicleSUV     = "suv"
+	VehicleVan     = "van"
+	VehicleMinibus = "minibus"
+	VehicleCoach   = "coach"
+)
+
+// Transfer represents a ground transfer such as car or shuttle. A transfer
+// linked to a flight is an airport pickup after that flight lands, and one
+// linked to a hotel must pick up before the hotel's check-in.
 type Transfer struct {
-	Mode       string `json:"mode"`
-	Pickup     string `json:"pickup"`
-	Dropoff    string `json:"dropoff"`
-	PickupTime string `json:"pickup_time"`
-	Notes      string `json:"notes"`
+	ID                        string           `json:"id"`
+	Mode                      string           `json:"mode"`
+	Pickup                    string           `json:"pickup"`
+	Dropoff                   string           `json:"dropoff"`
+	PickupTime                string           `json:"pickup_time"`
+	PickupAt                  time.Time        `json:"pickup_at,omitzero"`
+	FlightNumber              string           `json:"flight_number,omitempty"`
+	PickupAfterLandingMinutes *int             `json:"pickup_after_landing_minutes,omitempty"`
+	HotelName                 string           `json:"hotel_name,omitempty"`
+	VehicleType               string           `json:"vehicle_type,omitempty"`
+	Capacity                  int              `json:"capacity,omitempty"`
+	Vendor                    *TransferContact `json:"vendor,omitempty"`
+	Driver                    *TransferContact `json:"driver,omitempty"`
+	Price                     float64          `json:"price,omitempty"`
+	Currency                  string           `json:"currency,omitempty"`
+	Notes                     string           `json:"notes"`
+	InternalNotes             string           `json:"internal_notes,omitempty"`
+}
+
+// TransferContact is the company or driver running a transfer.
+type TransferContact struct {
+	Name  string `json:"name"`
+	Phone string `json:"phone,omitempty"`
+	Email string `json:"email,omitempty"`
+}
+
+// Kinds of transfer proposal
+const (
+	TransferProposalArrival   = "arrival"
+	TransferProposalDeparture = "departure"
+	TransferProposalIntercity = "intercity"
+)
+
+// TransferProposal is a transfer suggested from an itinerary's flights and
+// hotel stays. Its ID stays the same while those are unchanged.
+type TransferProposal struct {
+	ID       string   `json:"id"`
+	Kind     string   `json:"kind"`
+	Reason   string   `json:"reason"`
+	Transfer Transfer `json:"transfer"`
+}
+
+// AcceptTransferProposalsRequest selects the proposals to add as transfers.
+// No IDs accepts every proposal.
+type AcceptTransferProposalsRequest struct {
+	ProposalIDs []string `json:"proposal_ids"`
 }
 
 // PaymentInstallment describes a single entry in a payment plan.
 type PaymentInstallment struct {
+	ID                string    `json:"id"`
 	InstallmentNumber int       `json:"installment_number"`
 	Amount            float64   `json:"amount"`
 	Currency          string    `json:"currency"`
 	DueDate           time.Time `json:"due_date"`
 	Status            string    `json:"status"`
+	InternalNotes     string    `json:"internal_notes,omitempty"`
 }
 
 // DayPlan represents a single day in the itinerary
 type DayPlan struct {
-	DayNumber  int        `json:"day_number"`
-	Date       time.Time  `json:"date"`
-	Title      string     `json:"title"`
-	Activities []Activity `json:"activities"`
+	ID            string     `json:"id"`
+	DayNumber     int        `json:"day_number"`
+	Date          time.Time  `json:"date"`
+	Title         string     `json:"title"`
+	Activities    []Activity `json:"activities"`
+	InternalNotes string     `json:"internal_notes,omitempty"`
 }
 
-// Activity represents a single activity in a day plan
+// Activity represents a single activity in a day plan. Its ID stays with it
+// when it moves to another day. An activity with a CatalogueID fills in its
+// empty details from that catalogue entry; with CatalogueLink set the
+// entry's details replace its own and follow later changes to the entry.
 type Activity struct {
-	Period      string `json:"period"`
-	Time        string `json:"time"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Location    string `json:"location"`
-	Duration    string `json:"duration"`
+	ID            string `json:"id"`
+	Period        string `json:"period"`
+	Time          string `json:"time"`
+	Title         string `json:"title"`
+	Description   string `json:"description"`
+	Location      string `json:"location"`
+	Duration      string `json:"duration"`
+	InternalNotes string `json:"internal_notes,omitempty"`
+	CatalogueID   string `json:"catalogue_id,omitempty"`
+	CatalogueLink bool   `json:"catalogue_link,omitempty"`
+}
+
+// InsertDayRequest adds a day plan at a position, 1 being the first day. A
+// position of zero or past the last day appends the day.
+type InsertDayRequest struct {
+	Position      int        `json:"position"`
+	Title         string     `json:"title" binding:"required"`
+	Activities    []Activity `json:"activities" binding:"required"`
+	InternalNotes string     `json:"internal_notes"`
+}
+
+// ReorderDaysRequest lists every current day number in the new order
+type ReorderDaysRequest struct {
+	Order []int `json:"order" binding:"required"`
+}
+
+// SwapDaysRequest names two days whose plans trade places
+type SwapDaysRequest struct {
+	First  int `json:"first" binding:"required"`
+	Second int `json:"second" binding:"required"`
+}
+
+// MoveActivityRequest moves an activity to a day, at a 1-based position
+// among that day's activities. A position of zero appends it.
+type MoveActivityRequest struct {
+	DayNumber int `json:"day_number" binding:"required"`
+	Position  int `json:"position"`
 }
 
 // CreateItineraryRequest is the request payload for creating an itinerary
 type CreateItineraryRequest struct {
-	UserID      string               `json:"user_id" binding:"required"`
-	Title       string               `json:"title" binding:"required"`
-	Description string               `json:"description"`
-	StartDate   time.Time            `json:"start_date" binding:"required"`
-	EndDate     time.Time            `json:"end_date" binding:"required"`
-	Location    string               `json:"location" binding:"required"`
-	Hotels      []Hotel              `json:"hotels" binding:"required"`
-	Flights     []Flight             `json:"flights" binding:"required"`
-	Transfers   []Transfer           `json:"transfers" binding:"required"`
-	Days        []DayPlan            `json:"days" binding:"required"`
-	PaymentPlan []PaymentInstallment `json:"payment_plan"`
-	Inclusions  []string             `json:"inclusions"`
-	Exclusions  []string             `json:"exclusions"`
-}
-
-// UpdateItineraryRequest is the request payload for updating an itinerary
+	UserID        string               `json:"user_id" binding:"required"`
+	Title         string               `json:"title" binding:"required"`
+	Description   string               `json:"description"`
+	StartDate     time.Time            `json:"start_date" binding:"required"`
+	EndDate       time.Time            `json:"end_date" binding:"required"`
+	Location      string               `json:"location" binding:"required"`
+	Destination   string               `json:"destination_country"`
+	Hotels        []Hotel              `json:"hotels" binding:"required"`
+	Flights       []Flight             `json:"flights" binding:"required"`
+	Transfers     []Transfer           `json:"transfers" binding:"required"`
+	Days          []DayPlan            `json:"days" binding:"required"`
+	PaymentPlan   []PaymentInstallment `json:"payment_plan"`
+	Inclusions    []string             `json:"inclusions"`
+	Exclusions    []string             `json:"exclusions"`
+	Travellers    []Traveller          `json:"travellers"`
+	InternalNotes string               `json:"internal_notes"`
+}
+
+// UpdateItineraryRequest is the request payload for updating an itinerary.
+// Omitted internal notes are kept and an empty string clears them.
 type UpdateItineraryRequest struct {
-	UserID      string               `json:"user_id"`
-	Title       string               `json:"title"`
-	Description string               `json:"description"`
-	StartDate   time.Time            `json:"start_date"`
-	EndDate     time.Time            `json:"end_date"`
-	Location    string               `json:"location"`
-	Hotels      []Hotel              `json:"hotels"`
-	Flights     []Flight             `json:"flights"`
-	Transfers   []Transfer           `json:"transfers"`
-	Days        []DayPlan            `json:"days"`
-	PaymentPlan []PaymentInstallment `json:"payment_plan"`
-	Inclusions  []string             `json:"inclusions"`
-	Exclusions  []string             `json:"exclusions"`
+	UserID        string               `json:"user_id"`
+	Title         string               `json:"title"`
+	Description   string               `json:"description"`
+	StartDate     time.Time            `json:"start_date"`
+	EndDate       time.Time            `json:"end_date"`
+	Location      string               `json:"location"`
+	Destination   string               `json:"destination_country"`
+	Hotels        []Hotel              `json:"hotels"`
+	Flights       []Flight             `json:"flights"`
+	Transfers     []Transfer           `json:"transfers"`
+	Days          []DayPlan            `json:"days"`
+	PaymentPlan   []PaymentInstallment `json:"payment_plan"`
+	Inclusions    []string             `json:"inclusions"`
+	Exclusions    []string             `json:"exclusions"`
+	Travellers    []Traveller          `json:"travellers"`
+	InternalNotes *string              `json:"internal_notes"`
 }
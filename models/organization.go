@@ -0,0 +1,94 @@
+package models
+
+import "time"
+
+// Roles a member can hold in an organization
+const (
+	OrgRoleAdmin = "admin" // manages the organization and every itinerary in it
+	OrgRoleAgent = "agent"
+)
+
+// Organization is an agency whose members share itineraries. Every
+// itinerary and member belongs to at most one organization, and nothing is
+// visible across organizations.
+type Organization struct {
+	ID        string               `json:"id"`
+	Name      string               `json:"name"`
+	Settings  OrganizationSettings `json:"settings"`
+	CreatedBy string               `json:"created_by"`
+	CreatedAt time.Time            `json:"created_at"`
+	UpdatedAt time.Time            `json:"updated_at"`
+}
+
+// OrganizationSettings are the defaults applied to the organization's new
+// itineraries and exports
+type OrganizationSettings struct {
+	// DefaultCurrency fills in payment installments and priced transfers
+	// that name no currency
+	DefaultCurrency string `json:"default_currency,omitempty"`
+	// BrandingProfileID themes exports of members without a default theme
+	// of their own
+	BrandingProfileID string `json:"branding_profile_id,omitempty"`
+	// Inclusions and Exclusions are used when a new itinerary lists none
+	Inclusions []string `json:"inclusions,omitempty"`
+	Exclusions []string `json:"exclusions,omitempty"`
+}
+
+// OrganizationRequest creates an organization or replaces its name and
+// settings
+type OrganizationRequest struct {
+	Name     string               `json:"name" binding:"required"`
+	Settings OrganizationSettings `json:"settings"`
+	// MovePersonalItems moves the creator's personal itineraries and
+	// catalogue entries into a new organization; it is ignored on update
+	MovePersonalItems bool `json:"move_personal_items,omitempty"`
+}
+
+// Organization invitation statuses
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationDeclined = "declined"
+)
+
+// OrganizationInvitation asks whoever uses an email to join an organization.
+// Nothing changes for the invitee until they accept it.
+type OrganizationInvitation struct {
+	ID               string    `json:"id"`
+	OrganizationID   string    `json:"organization_id"`
+	OrganizationName string    `json:"organization_name"`
+	Email            string    `json:"email"`
+	Role             string    `json:"role"`
+	Status           string    `json:"status"`
+	InvitedBy        string    `json:"invited_by"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// InviteMemberRequest invites an email to an organization. Inviting an
+// email that already has a pending invitation changes its role.
+type InviteMemberRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required"`
+}
+
+// AcceptInvitationRequest accepts an organization invitation. The invitee's
+// personal itineraries and catalogue entries only move into the organization
+// when MovePersonalItems is set; otherwise they stay personal.
+type AcceptInvitationRequest struct {
+	MovePersonalItems bool `json:"move_personal_items"`
+}
+
+// UpdateMemberRequest changes a member's role
+type UpdateMemberRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// OrganizationMember is a user as listed in their organization
+type OrganizationMember struct {
+	UserID   string `json:"user_id"`
+	Email    string `json:"email"`
+	Username string `json:"username"`
+	FullName string `json:"full_name"`
+	Role     string `json:"role"`
+}
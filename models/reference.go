@@ -0,0 +1,28 @@
+package models
+
+// Airport is an entry of the embedded airport reference data
+type Airport struct {
+	IATA        string `json:"iata"`
+	ICAO        string `json:"icao"`
+	Name        string `json:"name"`
+	City        string `json:"city"`
+	CountryCode string `json:"country_code"`
+	Country     string `json:"country"`
+	TimeZone    string `json:"time_zone"`
+}
+
+// Location is the airport's city and country, e.g. "Paris, France"
+func (a Airport) Location() string {
+	if a.City == a.Country {
+		return a.City
+	}
+	return a.City + ", " + a.Country
+}
+
+// Airline is an entry of the embedded airline reference data
+type Airline struct {
+	IATA        string `json:"iata"`
+	ICAO        string `json:"icao"`
+	Name        string `json:"name"`
+	CountryCode string `json:"country_code"`
+}
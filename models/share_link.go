@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Parts of an itinerary a share link can hide from its viewers
+const (
+	ShareHidePrices            = "prices"             // transfer net prices
+	ShareHideVendors           = "vendors"            // transfer vendor contacts
+	ShareHideNotes             = "notes"              // transfer notes written for the agent
+	ShareHideBookingReferences = "booking_references" // hotel confirmation and flight booking numbers
+	ShareHidePaymentPlan       = "payment_plan"
+	ShareHideWarnings          = "warnings" // passport and visa check results
+)
+
+// DefaultShareHidden is what a share link hides when none is chosen
+var DefaultShareHidden = []string{ShareHidePrices, ShareHideVendors, ShareHideNotes, ShareHideWarnings}
+
+// ShareLink gives anyone holding its token read-only access to an itinerary
+// without an account. Revoked links are kept so their view counts remain.
+type ShareLink struct {
+	ID           string    `json:"id"`
+	ItineraryID  string    `json:"itinerary_id"`
+	Token        string    `json:"token"`
+	URL          string    `json:"url"`
+	Hidden       []string  `json:"hidden"`
+	ExpiresAt    time.Time `json:"expires_at,omitzero"`
+	RevokedAt    time.Time `json:"revoked_at,omitzero"`
+	ViewCount    int       `json:"view_count"`
+	LastViewedAt time.Time `json:"last_viewed_at,omitzero"`
+	CreatedBy    string    `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// CreateShareLinkRequest creates a share link. Without hidden the link hides
+// DefaultShareHidden; an empty list hides nothing. Without expires_at the link
+// works until it is revoked.
+type CreateShareLinkRequest struct {
+	ExpiresAt time.Time `json:"expires_at"`
+	Hidden    []string  `json:"hidden"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// ItineraryTemplate is a reusable itinerary. Its dates are kept relative to
+// BaseDate, day 0 of the trip, and move with the start date of every
+// itinerary made from it.
+type ItineraryTemplate struct {
+	ID                string                 `json:"id"`
+	UserID            string                 `json:"user_id"`
+	Name              string                 `json:"name"`
+	Description       string                 `json:"description,omitempty"`
+	Tags              []string               `json:"tags,omitempty"`
+	SourceItineraryID string                 `json:"source_itinerary_id"`
+	BaseDate          time.Time              `json:"base_date"`
+	DurationDays      int                    `json:"duration_days"`
+	Itinerary         CreateItineraryRequest `json:"itinerary"`
+	CreatedAt         time.Time              `json:"created_at"`
+	UpdatedAt         time.Time              `json:"updated_at"`
+}
+
+// CreateTemplateRequest saves an itinerary as a template
+type CreateTemplateRequest struct {
+	ItineraryID string   `json:"itinerary_id" binding:"required"`
+	Name        string   `json:"name" binding:"required"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+}
+
+// InstantiateTemplateRequest creates an itinerary from a template starting
+// on StartDate. Title defaults to the template's itinerary title.
+type InstantiateTemplateRequest struct {
+	StartDate time.Time `json:"start_date" binding:"required"`
+	Title     string    `json:"title"`
+}
+
+// CloneItineraryRequest copies an itinerary, moving its dates when a new
+// start date is given
+type CloneItineraryRequest struct {
+	StartDate time.Time `json:"start_date"`
+	Title     string    `json:"title"`
+}
@@ -0,0 +1,40 @@
+package models
+
+// Visa requirement values of a VisaRule
+const (
+	VisaFree       = "visa_free"
+	VisaOnArrival  = "visa_on_arrival"
+	VisaElectronic = "e_visa"
+	VisaRequired   = "visa_required"
+)
+
+// Travel warning severities
+const (
+	WarningSeverityInfo    = "info"
+	WarningSeverityWarning = "warning"
+	WarningSeverityError   = "error"
+)
+
+// VisaRule states what a national of one country needs to enter another.
+// Nationality "*" applies to every nationality without a rule of its own.
+type VisaRule struct {
+	Nationality string `json:"nationality"`
+	Destination string `json:"destination"`
+	Requirement string `json:"requirement"`
+	MaxStayDays int    `json:"max_stay_days,omitempty"`
+	Notes       string `json:"notes,omitempty"`
+}
+
+// VisaRuleSet is the layout of the visa rules file
+type VisaRuleSet struct {
+	Rules []VisaRule `json:"rules"`
+}
+
+// TravelWarning is a passport, visa or connection issue found on an
+// itinerary. TravellerID is empty for issues that affect every traveller.
+type TravelWarning struct {
+	Code        string `json:"code"`
+	Severity    string `json:"severity"`
+	TravellerID string `json:"traveller_id,omitempty"`
+	Message     string `json:"message"`
+}
@@ -4,13 +4,15 @@ import "time"
 
 // User represents a user account in the system
 type User struct {
-	ID        string    `json:"id"`
-	Email     string    `json:"email"`
-	Username  string    `json:"username"`
-	Password  string    `json:"-"` // Never include password in JSON responses
-	FullName  string    `json:"full_name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID               string    `json:"id"`
+	Email            string    `json:"email"`
+	Username         string    `json:"username"`
+	Password         string    `json:"-"` // Never include password in JSON responses
+	FullName         string    `json:"full_name"`
+	OrganizationID   string    `json:"organization_id,omitempty"`
+	OrganizationRole string    `json:"organization_role,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
 }
 
 // SignupRequest represents the signup request payload
@@ -35,22 +37,26 @@ type AuthResponse struct {
 
 // UserResponse represents a safe user response without sensitive data
 type UserResponse struct {
-	ID        string    `json:"id"`
-	Email     string    `json:"email"`
-	Username  string    `json:"username"`
-	FullName  string    `json:"full_name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID               string    `json:"id"`
+	Email            string    `json:"email"`
+	Username         string    `json:"username"`
+	FullName         string    `json:"full_name"`
+	OrganizationID   string    `json:"organization_id,omitempty"`
+	OrganizationRole string    `json:"organization_role,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
 }
 
 // ToUserResponse converts User to UserResponse
 func (u *User) ToUserResponse() *UserResponse {
 	return &UserResponse{
-		ID:        u.ID,
-		Email:     u.Email,
-		Username:  u.Username,
-		FullName:  u.FullName,
-		CreatedAt: u.CreatedAt,
-		UpdatedAt: u.UpdatedAt,
+		ID:               u.ID,
+		Email:            u.Email,
+		Username:         u.Username,
+		FullName:         u.FullName,
+		OrganizationID:   u.OrganizationID,
+		OrganizationRole: u.OrganizationRole,
+		CreatedAt:        u.CreatedAt,
+		UpdatedAt:        u.UpdatedAt,
 	}
 }
@@ -3,6 +3,7 @@ package routes
 import (
 	"vigovia-task/handlers"
 	"vigovia-task/middleware"
+	"vigovia-task/models"
 	"vigovia-task/services"
 	"vigovia-task/storage"
 
@@ -10,7 +11,7 @@ import (
 )
 
 // RegisterItineraryRoutes registers all itinerary-related routes
-func RegisterItineraryRoutes(router *gin.Engine) {
+func RegisterItineraryRoutes(router *gin.Engine) error {
 	// Initialize storage, services, and handlers
 	store := storage.NewMemoryStore()
 	
@@ -18,10 +19,52 @@ func RegisterItineraryRoutes(router *gin.Engine) {
 	authService := services.NewAuthService(store)
 	authHandler := handlers.NewAuthHandler(authService)
 	
+	// Passport and visa checks use the rules file named by VISA_RULES_FILE
+	visaRules, err := services.VisaRulesFromEnv()
+	if err != nil {
+		return err
+	}
+
 	// Itinerary services and handlers
-	itineraryService := services.NewItineraryService(store)
-	pdfService := services.NewPDFService()
-	itineraryHandler := handlers.NewItineraryHandler(itineraryService, pdfService)
+	accessService := services.NewAccessService(store)
+	itineraryService := services.NewItineraryService(store, services.NewTravelCheckService(visaRules))
+	pdfService, err := services.NewPDFService()
+	if err != nil {
+		return err
+	}
+	renderers := services.NewRendererRegistry(pdfService)
+	brandingService := services.NewBrandingService(store)
+
+	// Archived exports go to the document store selected by DOCUMENT_STORE
+	documentStore, err := storage.DocumentStoreFromEnv()
+	if err != nil {
+		return err
+	}
+	documentService := services.NewDocumentService(store, documentStore)
+	exportService := services.NewExportService(store, renderers, pdfService, brandingService, documentService, accessService, services.DefaultExportServiceConfig())
+
+	// Rendered exports are cached per itinerary revision and dropped as soon
+	// as the itinerary changes
+	renderCache := services.NewRenderCache(services.DefaultRenderCacheBytes)
+	itineraryService.OnChange(renderCache.InvalidateItinerary)
+
+	itineraryHandler := handlers.NewItineraryHandler(itineraryService, pdfService, renderers, brandingService, documentService, renderCache, accessService)
+	brandingHandler := handlers.NewBrandingHandler(brandingService, renderers)
+	documentHandler := handlers.NewDocumentHandler(itineraryService, documentService)
+	exportHandler := handlers.NewExportHandler(itineraryService, exportService, accessService)
+	collaboratorHandler := handlers.NewCollaboratorHandler(accessService)
+	templateHandler := handlers.NewTemplateHandler(services.NewTemplateService(store, itineraryService, accessService))
+
+	// Share links are signed with SHARE_LINK_SECRET
+	shareSecret, err := services.ShareLinkSecretFromEnv()
+	if err != nil {
+		return err
+	}
+	shareHandler := handlers.NewShareHandler(services.NewShareService(store, shareSecret), renderers, brandingService)
+	organizationHandler := handlers.NewOrganizationHandler(services.NewOrganizationService(store, itineraryService, accessService))
+	commentHandler := handlers.NewCommentHandler(services.NewCommentService(store, accessService))
+	approvalHandler := handlers.NewApprovalHandler(services.NewApprovalService(store, itineraryService))
+	catalogueHandler := handlers.NewCatalogueHandler(services.NewCatalogueService(store, accessService, itineraryService))
 
 	// API routes
 	api := router.Group("/api")
@@ -38,17 +81,137 @@ func RegisterItineraryRoutes(router *gin.Engine) {
 		// Itinerary routes (protected)
 		itineraries := api.Group("/itineraries")
 		itineraries.Use(middleware.AuthMiddleware(authService))
+		// Routes for one itinerary check the user's role on it first
+		view := middleware.ItineraryAccess(accessService, models.PermissionView)
+		edit := middleware.ItineraryAccess(accessService, models.PermissionEdit)
+		approve := middleware.ItineraryAccess(accessService, models.PermissionApprove)
+		manage := middleware.ItineraryAccess(accessService, models.PermissionManage)
 		{
 			itineraries.POST("", itineraryHandler.CreateItinerary)
 			itineraries.GET("", itineraryHandler.ListItineraries)
-			itineraries.GET("/:id", itineraryHandler.GetItinerary)
-			itineraries.PUT("/:id", itineraryHandler.UpdateItinerary)
-			itineraries.DELETE("/:id", itineraryHandler.DeleteItinerary)
-			itineraries.POST("/:id/activities", itineraryHandler.AddActivity)
-			itineraries.GET("/:id/export-pdf", itineraryHandler.ExportPDF)
+			itineraries.POST("/bulk-export", itineraryHandler.BulkExport)
+			itineraries.POST("/bulk-exports", exportHandler.CreateBulkExport)
+			itineraries.GET("/invitations", collaboratorHandler.ListInvitations)
+			itineraries.POST("/invitations/:grantId/accept", collaboratorHandler.AcceptInvitation)
+			itineraries.POST("/invitations/:grantId/decline", collaboratorHandler.DeclineInvitation)
+			itineraries.GET("/:id", view, itineraryHandler.GetItinerary)
+			itineraries.PUT("/:id", edit, itineraryHandler.UpdateItinerary)
+			itineraries.DELETE("/:id", manage, itineraryHandler.DeleteItinerary)
+			itineraries.POST("/:id/activities", edit, itineraryHandler.AddActivity)
+			itineraries.GET("/:id/activities/:activityId", view, itineraryHandler.GetActivity)
+			itineraries.PUT("/:id/activities/:activityId", edit, itineraryHandler.UpdateActivity)
+			itineraries.DELETE("/:id/activities/:activityId", edit, itineraryHandler.DeleteActivity)
+			itineraries.POST("/:id/activities/:activityId/move", edit, itineraryHandler.MoveActivity)
+			itineraries.POST("/:id/days", edit, itineraryHandler.InsertDay)
+			itineraries.GET("/:id/days/:dayId", view, itineraryHandler.GetDay)
+			itineraries.PUT("/:id/days/:dayId", edit, itineraryHandler.UpdateDay)
+			itineraries.DELETE("/:id/days/:dayId", edit, itineraryHandler.DeleteDay)
+			itineraries.PUT("/:id/days/order", edit, itineraryHandler.ReorderDays)
+			itineraries.POST("/:id/days/swap", edit, itineraryHandler.SwapDays)
+			itineraries.POST("/:id/hotels", edit, itineraryHandler.AddHotel)
+			itineraries.GET("/:id/hotels/:hotelId", view, itineraryHandler.GetHotel)
+			itineraries.PUT("/:id/hotels/:hotelId", edit, itineraryHandler.UpdateHotel)
+			itineraries.DELETE("/:id/hotels/:hotelId", edit, itineraryHandler.DeleteHotel)
+			itineraries.POST("/:id/flights", edit, itineraryHandler.AddFlight)
+			itineraries.GET("/:id/flights/:flightId", view, itineraryHandler.GetFlight)
+			itineraries.PUT("/:id/flights/:flightId", edit, itineraryHandler.UpdateFlight)
+			itineraries.DELETE("/:id/flights/:flightId", edit, itineraryHandler.DeleteFlight)
+			itineraries.POST("/:id/transfers", edit, itineraryHandler.AddTransfer)
+			itineraries.GET("/:id/transfers/:transferId", view, itineraryHandler.GetTransfer)
+			itineraries.PUT("/:id/transfers/:transferId", edit, itineraryHandler.UpdateTransfer)
+			itineraries.DELETE("/:id/transfers/:transferId", edit, itineraryHandler.DeleteTransfer)
+			itineraries.POST("/:id/payments", edit, itineraryHandler.AddPaymentInstallment)
+			itineraries.GET("/:id/payments/:paymentId", view, itineraryHandler.GetPaymentInstallment)
+			itineraries.PUT("/:id/payments/:paymentId", edit, itineraryHandler.UpdatePaymentInstallment)
+			itineraries.DELETE("/:id/payments/:paymentId", edit, itineraryHandler.DeletePaymentInstallment)
+			itineraries.POST("/:id/clone", view, itineraryHandler.CloneItinerary)
+			itineraries.GET("/:id/transfer-proposals", view, itineraryHandler.ProposeTransfers)
+			itineraries.POST("/:id/transfer-proposals/accept", edit, itineraryHandler.AcceptTransferProposals)
+			itineraries.GET("/:id/export-pdf", view, itineraryHandler.ExportPDF)
+			itineraries.GET("/:id/export", view, itineraryHandler.Export)
+			itineraries.POST("/:id/exports", view, exportHandler.CreateExport)
+			itineraries.GET("/:id/documents", view, documentHandler.ListDocuments)
+			itineraries.GET("/:id/documents/:documentId", view, documentHandler.DownloadDocument)
+			itineraries.GET("/:id/collaborators", view, collaboratorHandler.ListCollaborators)
+			itineraries.POST("/:id/collaborators", manage, collaboratorHandler.InviteCollaborator)
+			itineraries.DELETE("/:id/collaborators/:grantId", view, collaboratorHandler.RevokeCollaborator)
+			itineraries.POST("/:id/share-links", manage, shareHandler.CreateShareLink)
+			itineraries.GET("/:id/share-links", manage, shareHandler.ListShareLinks)
+			itineraries.DELETE("/:id/share-links/:linkId", manage, shareHandler.RevokeShareLink)
+			itineraries.GET("/:id/comments", view, commentHandler.ListComments)
+			itineraries.POST("/:id/comments", view, commentHandler.AddComment)
+			itineraries.PUT("/:id/comments/:commentId", view, commentHandler.UpdateComment)
+			itineraries.DELETE("/:id/comments/:commentId", view, commentHandler.DeleteComment)
+			itineraries.GET("/:id/approvals", view, approvalHandler.ListApprovals)
+			itineraries.POST("/:id/approvals", edit, approvalHandler.RequestApproval)
+			itineraries.POST("/:id/approvals/:approvalId/respond", approve, approvalHandler.RespondApproval)
+		}
+
+		// Export job routes (protected)
+		exports := api.Group("/exports")
+		exports.Use(middleware.AuthMiddleware(authService))
+		{
+			exports.GET("/:job", exportHandler.GetExport)
+			exports.GET("/:job/download", exportHandler.DownloadExport)
+		}
+
+		// Itinerary template routes (protected)
+		templates := api.Group("/templates")
+		templates.Use(middleware.AuthMiddleware(authService))
+		{
+			templates.POST("", templateHandler.CreateTemplate)
+			templates.GET("", templateHandler.ListTemplates)
+			templates.GET("/:id", templateHandler.GetTemplate)
+			templates.DELETE("/:id", templateHandler.DeleteTemplate)
+			templates.POST("/:id/instantiate", templateHandler.Instantiate)
+		}
+
+		// Organization routes (protected)
+		organizations := api.Group("/organizations")
+		organizations.Use(middleware.AuthMiddleware(authService))
+		{
+			organizations.POST("", organizationHandler.CreateOrganization)
+			organizations.GET("/:id", organizationHandler.GetOrganization)
+			organizations.PUT("/:id", organizationHandler.UpdateOrganization)
+			organizations.GET("/:id/members", organizationHandler.ListMembers)
+			organizations.GET("/invitations", organizationHandler.UserInvitations)
+			organizations.POST("/invitations/:invitationId/accept", organizationHandler.AcceptInvitation)
+			organizations.POST("/invitations/:invitationId/decline", organizationHandler.DeclineInvitation)
+			organizations.GET("/:id/invitations", organizationHandler.ListInvitations)
+			organizations.POST("/:id/invitations", organizationHandler.InviteMember)
+			organizations.DELETE("/:id/invitations/:invitationId", organizationHandler.RevokeInvitation)
+			organizations.PUT("/:id/members/:userId", organizationHandler.UpdateMember)
+			organizations.DELETE("/:id/members/:userId", organizationHandler.RemoveMember)
+			organizations.GET("/:id/itineraries", organizationHandler.ListItineraries)
+		}
+
+		// Activity catalogue routes (protected)
+		catalogue := api.Group("/catalogue")
+		catalogue.Use(middleware.AuthMiddleware(authService))
+		{
+			catalogue.POST("", catalogueHandler.CreateEntry)
+			catalogue.GET("", catalogueHandler.Search)
+			catalogue.GET("/:id", catalogueHandler.GetEntry)
+			catalogue.PUT("/:id", catalogueHandler.UpdateEntry)
+			catalogue.DELETE("/:id", catalogueHandler.DeleteEntry)
+		}
+
+		// Branding routes (protected)
+		branding := api.Group("/branding")
+		branding.Use(middleware.AuthMiddleware(authService))
+		{
+			branding.POST("", brandingHandler.CreateProfile)
+			branding.GET("", brandingHandler.ListProfiles)
+			branding.GET("/:id", brandingHandler.GetProfile)
+			branding.PUT("/:id", brandingHandler.UpdateProfile)
+			branding.DELETE("/:id", brandingHandler.DeleteProfile)
+			branding.GET("/:id/preview", brandingHandler.PreviewProfile)
 		}
 	}
 
+	// Shared itinerary views (public, the token is the credential)
+	router.GET("/share/:token", shareHandler.ViewSharedItinerary)
+
 	// Health check and welcome routes
 	router.GET("/", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -65,4 +228,6 @@ func RegisterItineraryRoutes(router *gin.Engine) {
 			"version": "1.0.0",
 		})
 	})
+
+	return nil
 }
@@ -0,0 +1,313 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"vigovia-task/models"
+	"vigovia-task/storage"
+	"vigovia-task/utils"
+)
+
+// ErrAccessDenied is returned when a user's role on an itinerary does not
+// allow what they asked for
+var ErrAccessDenied = errors.New("access to this itinerary denied")
+
+// rolePermissions lists what each role may do with an itinerary
+var rolePermissions = map[string]map[string]bool{
+	models.RoleOwner: {
+		models.PermissionView:    true,
+		models.PermissionEdit:    true,
+		models.PermissionApprove: true,
+		models.PermissionManage:  true,
+	},
+	models.RoleAdmin: {
+		models.PermissionView:    true,
+		models.PermissionEdit:    true,
+		models.PermissionApprove: true,
+		models.PermissionManage:  true,
+	},
+	models.RoleEditor:   {models.PermissionView: true, models.PermissionEdit: true},
+	models.RoleApprover: {models.PermissionView: true, models.PermissionApprove: true},
+	models.RoleViewer:   {models.PermissionView: true},
+}
+
+// SeesInternalNotes reports whether a role may read an itinerary's internal
+// notes. Only roles that can edit it may; viewers and approvers are often
+// the agency's clients.
+func SeesInternalNotes(role string) bool {
+	return rolePermissions[role][models.PermissionEdit]
+}
+
+// CheckInternalNotes returns an ErrAccessDenied error for roles that may not
+// read internal notes
+func CheckInternalNotes(role string) error {
+	if !SeesInternalNotes(role) {
+		return fmt.Errorf("%w: the %s role cannot see internal notes", ErrAccessDenied, role)
+	}
+	return nil
+}
+
+// ClientView returns a copy of the itinerary as roles that cannot edit it
+// see it: without internal notes and with traveller details masked
+func ClientView(itinerary *models.Itinerary) *models.Itinerary {
+	return maskTravellers(itinerary.WithoutInternalNotes())
+}
+
+// AccessService decides who may read, change and share each itinerary
+type AccessService struct {
+	store *storage.MemoryStore
+}
+
+// NewAccessService creates a new instance of AccessService
+func NewAccessService(store *storage.MemoryStore) *AccessService {
+	return &AccessService{
+		store: store,
+	}
+}
+
+// Authorize returns the itinerary and the user's role on it when the role
+// carries the permission. Users with no role get the same error as for a
+// missing itinerary, so they cannot tell which IDs exist.
+func (as *AccessService) Authorize(itineraryID, userID, permission string) (*models.Itinerary, string, error) {
+	itinerary, err := as.store.GetByID(itineraryID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	role := as.Role(itinerary, userID)
+	if role == "" {
+		return nil, "", fmt.Errorf("itinerary with id %s not found", itineraryID)
+	}
+	if !rolePermissions[role][permission] {
+		return nil, role, fmt.Errorf("%w: the %s role cannot %s it", ErrAccessDenied, role, permission)
+	}
+	return itinerary, role, nil
+}
+
+// Role returns the user's role on an itinerary, or "" when they have none.
+// Users never have a role on another organization's itineraries, whatever
+// grants they hold, but keep owning the personal itineraries they did not
+// bring into their organization. Within an organization admins may do
+// anything and agents may view what they have not been given a grant for.
+func (as *AccessService) Role(itinerary *models.Itinerary, userID string) string {
+	user, err := as.store.GetUserByID(userID)
+	if err != nil {
+		return ""
+	}
+	if itinerary.UserID == userID && itinerary.OrganizationID == "" {
+		return models.RoleOwner
+	}
+	if user.OrganizationID != itinerary.OrganizationID {
+		return ""
+	}
+	if itinerary.UserID == userID {
+		return models.RoleOwner
+	}
+	if itinerary.OrganizationID != "" && user.OrganizationRole == models.OrgRoleAdmin {
+		return models.RoleAdmin
+	}
+	if grant := as.userGrant(itinerary.ID, userID); grant != nil {
+		return grant.Role
+	}
+	if itinerary.OrganizationID != "" {
+		return models.RoleViewer
+	}
+	return ""
+}
+
+// Tenant returns the organization whose itineraries a user works with, or
+// "" for personal itineraries
+func (as *AccessService) Tenant(userID string) string {
+	user, err := as.store.GetUserByID(userID)
+	if err != nil {
+		return ""
+	}
+	return user.OrganizationID
+}
+
+// Visible keeps the itineraries the user owns or has been given access to
+func (as *AccessService) Visible(userID string, itineraries []*models.Itinerary) []*models.Itinerary {
+	visible := make([]*models.Itinerary, 0, len(itineraries))
+	for _, itinerary := range itineraries {
+		if as.Role(itinerary, userID) != "" {
+			visible = append(visible, itinerary)
+		}
+	}
+	return visible
+}
+
+// HideInternalNotes removes the internal notes from the itineraries the user
+// may not read them on
+func (as *AccessService) HideInternalNotes(userID string, itineraries []*models.Itinerary) []*models.Itinerary {
+	hidden := make([]*models.Itinerary, 0, len(itineraries))
+	for _, itinerary := range itineraries {
+		if !SeesInternalNotes(as.Role(itinerary, userID)) {
+			itinerary = itinerary.WithoutInternalNotes()
+		}
+		hidden = append(hidden, itinerary)
+	}
+	return hidden
+}
+
+// Invite offers the invited email a role on an itinerary, or changes the
+// role of an email that already has one. The grant stays pending until the
+// account using the email accepts it.
+func (as *AccessService) Invite(itineraryID, inviterID string, req *models.InviteCollaboratorRequest) (*models.AccessGrant, error) {
+	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+	req.Role = strings.ToLower(strings.TrimSpace(req.Role))
+	if err := utils.ValidateInvite(req); err != nil {
+		return nil, err
+	}
+
+	itinerary, err := as.store.GetByID(itineraryID)
+	if err != nil {
+		return nil, err
+	}
+	if owner, err := as.store.GetUserByID(itinerary.UserID); err == nil && strings.EqualFold(owner.Email, req.Email) {
+		return nil, utils.NewValidationError("the owner already has full access to the itinerary")
+	}
+	if invitee, err := as.store.GetUserByEmail(req.Email); err == nil && invitee.OrganizationID != itinerary.OrganizationID {
+		return nil, utils.NewValidationError("collaborators must belong to the itinerary's organization")
+	}
+
+	now := time.Now()
+	for _, existing := range as.store.GetAccessGrantsByItinerary(itineraryID) {
+		if existing.Email != req.Email {
+			continue
+		}
+		grant := *existing
+		grant.Role = req.Role
+		grant.UpdatedAt = now
+		if err := as.store.UpdateAccessGrant(&grant); err != nil {
+			return nil, err
+		}
+		return &grant, nil
+	}
+
+	grant := &models.AccessGrant{
+		ID:          generateID("grant"),
+		ItineraryID: itineraryID,
+		Email:       req.Email,
+		Role:        req.Role,
+		Status:      models.GrantPending,
+		InvitedBy:   inviterID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := as.store.CreateAccessGrant(grant); err != nil {
+		return nil, err
+	}
+	return grant, nil
+}
+
+// ListCollaborators returns an itinerary's owner and access grants
+func (as *AccessService) ListCollaborators(itineraryID string) (*models.Collaborators, error) {
+	itinerary, err := as.store.GetByID(itineraryID)
+	if err != nil {
+		return nil, err
+	}
+
+	collaborators := &models.Collaborators{Collaborators: make([]*models.AccessGrant, 0)}
+	if owner, err := as.store.GetUserByID(itinerary.UserID); err == nil {
+		collaborators.Owner = owner.ToUserResponse()
+	}
+	collaborators.Collaborators = append(collaborators.Collaborators, as.store.GetAccessGrantsByItinerary(itineraryID)...)
+	return collaborators, nil
+}
+
+// Revoke removes an access grant. Users who may manage the itinerary can
+// revoke any grant; collaborators can only give up their own.
+func (as *AccessService) Revoke(itineraryID, userID, grantID string) error {
+	itinerary, err := as.store.GetByID(itineraryID)
+	if err != nil {
+		return err
+	}
+
+	for _, grant := range as.store.GetAccessGrantsByItinerary(itineraryID) {
+		if grant.ID != grantID {
+			continue
+		}
+		own := as.userGrant(itineraryID, userID)
+		if !rolePermissions[as.Role(itinerary, userID)][models.PermissionManage] && (own == nil || own.ID != grantID) {
+			return fmt.Errorf("%w: only the owner can remove other collaborators", ErrAccessDenied)
+		}
+		return as.store.DeleteAccessGrant(grantID)
+	}
+	return utils.NewValidationError(fmt.Sprintf("collaborator %s not found on itinerary", grantID))
+}
+
+// Invitations returns the pending grants sent to userID's email
+func (as *AccessService) Invitations(userID string) []*models.AccessGrant {
+	pending := make([]*models.AccessGrant, 0)
+	user, err := as.store.GetUserByID(userID)
+	if err != nil {
+		return pending
+	}
+	for _, grant := range as.store.GetAccessGrantsByEmail(strings.ToLower(user.Email)) {
+		if grant.Status == models.GrantPending {
+			pending = append(pending, grant)
+		}
+	}
+	return pending
+}
+
+// AcceptInvitation ties a pending grant sent to userID's email to their
+// account, giving them its role
+func (as *AccessService) AcceptInvitation(userID, grantID string) (*models.AccessGrant, error) {
+	user, grant, err := as.pendingGrant(userID, grantID)
+	if err != nil {
+		return nil, err
+	}
+	itinerary, err := as.store.GetByID(grant.ItineraryID)
+	if err != nil {
+		return nil, ErrInvitationNotFound
+	}
+	if user.OrganizationID != itinerary.OrganizationID {
+		return nil, utils.NewValidationError("collaborators must belong to the itinerary's organization")
+	}
+
+	accepted := *grant
+	accepted.UserID = user.ID
+	accepted.Status = models.GrantActive
+	accepted.UpdatedAt = time.Now()
+	if err := as.store.UpdateAccessGrant(&accepted); err != nil {
+		return nil, err
+	}
+	return &accepted, nil
+}
+
+// DeclineInvitation removes a pending grant sent to userID's email
+func (as *AccessService) DeclineInvitation(userID, grantID string) error {
+	_, grant, err := as.pendingGrant(userID, grantID)
+	if err != nil {
+		return err
+	}
+	return as.store.DeleteAccessGrant(grant.ID)
+}
+
+// pendingGrant finds a grant waiting for userID's answer
+func (as *AccessService) pendingGrant(userID, grantID string) (*models.User, *models.AccessGrant, error) {
+	user, err := as.store.GetUserByID(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, grant := range as.Invitations(userID) {
+		if grant.ID == grantID {
+			return user, grant, nil
+		}
+	}
+	return nil, nil, ErrInvitationNotFound
+}
+
+// userGrant finds the grant a user has accepted on an itinerary
+func (as *AccessService) userGrant(itineraryID, userID string) *models.AccessGrant {
+	for _, grant := range as.store.GetAccessGrantsByItinerary(itineraryID) {
+		if grant.UserID == userID {
+			return grant
+		}
+	}
+	return nil
+}
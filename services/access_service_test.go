@@ -0,0 +1,332 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"vigovia-task/models"
+	"vigovia-task/storage"
+)
+
+// addUser stores a user, in an organization when organizationID is set
+func addUser(t *testing.T, store *storage.MemoryStore, id, organizationID, organizationRole string) *models.User {
+	t.Helper()
+	user := &models.User{
+		ID:               id,
+		Email:            id + "@example.com",
+		Username:         id,
+		OrganizationID:   organizationID,
+		OrganizationRole: organizationRole,
+	}
+	if err := store.CreateUser(user); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+// addGrant gives a user a role on an itinerary
+func addGrant(t *testing.T, store *storage.MemoryStore, itineraryID, userID, role string) {
+	t.Helper()
+	grant := &models.AccessGrant{
+		ID:          generateID("acc"),
+		ItineraryID: itineraryID,
+		Email:       userID + "@example.com",
+		UserID:      userID,
+		Role:        role,
+		Status:      models.GrantActive,
+		CreatedAt:   time.Now(),
+	}
+	if err := store.CreateAccessGrant(grant); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// notedItinerary stores an itinerary of ownerID with internal notes on it,
+// a hotel, a day and an activity
+func notedItinerary(t *testing.T, store *storage.MemoryStore, id, ownerID, organizationID string) *models.Itinerary {
+	t.Helper()
+	itinerary := &models.Itinerary{
+		ID:             id,
+		UserID:         ownerID,
+		OrganizationID: organizationID,
+		Title:          "Rajasthan Circuit",
+		InternalNotes:  "net rate 9,200 INR",
+		Hotels:         []models.Hotel{{ID: "hotel-1", Name: "Taj Palace", InternalNotes: "ask for Ravi"}},
+		Days: []models.DayPlan{{
+			ID:            "day-1",
+			DayNumber:     1,
+			InternalNotes: "guide unconfirmed",
+			Activities:    []models.Activity{{ID: "act-1", Title: "Amber Fort", InternalNotes: "pay cash"}},
+		}},
+		Revision: 1,
+	}
+	if err := store.Create(itinerary); err != nil {
+		t.Fatal(err)
+	}
+	return itinerary
+}
+
+func hasInternalNotes(itinerary *models.Itinerary) bool {
+	if itinerary.InternalNotes != "" || itinerary.Hotels[0].InternalNotes != "" {
+		return true
+	}
+	day := itinerary.Days[0]
+	return day.InternalNotes != "" || day.Activities[0].InternalNotes != ""
+}
+
+func TestSeesInternalNotes(t *testing.T) {
+	for role, want := range map[string]bool{
+		models.RoleOwner:    true,
+		models.RoleAdmin:    true,
+		models.RoleEditor:   true,
+		models.RoleApprover: false,
+		models.RoleViewer:   false,
+		"":                  false,
+	} {
+		if got := SeesInternalNotes(role); got != want {
+			t.Errorf("SeesInternalNotes(%q) = %v, want %v", role, got, want)
+		}
+		if err := CheckInternalNotes(role); (err == nil) != want || (err != nil && !errors.Is(err, ErrAccessDenied)) {
+			t.Errorf("CheckInternalNotes(%q) = %v", role, err)
+		}
+	}
+}
+
+func TestHideInternalNotes(t *testing.T) {
+	store := storage.NewMemoryStore()
+	access := NewAccessService(store)
+	addUser(t, store, "owner", "", "")
+	addUser(t, store, "editor", "", "")
+	addUser(t, store, "viewer", "", "")
+	itinerary := notedItinerary(t, store, "trip-1", "owner", "")
+	addGrant(t, store, itinerary.ID, "editor", models.RoleEditor)
+	addGrant(t, store, itinerary.ID, "viewer", models.RoleViewer)
+
+	for userID, want := range map[string]bool{"owner": true, "editor": true, "viewer": false} {
+		shown := access.HideInternalNotes(userID, []*models.Itinerary{itinerary})
+		if got := hasInternalNotes(shown[0]); got != want {
+			t.Errorf("%s sees internal notes = %v, want %v", userID, got, want)
+		}
+	}
+	if !hasInternalNotes(itinerary) {
+		t.Error("hiding internal notes changed the stored itinerary")
+	}
+}
+
+func TestClientCopyMasksTravellers(t *testing.T) {
+	store := storage.NewMemoryStore()
+	itinerary := notedItinerary(t, store, "trip-1", "owner", "")
+	itinerary.Travellers = []models.Traveller{{
+		ID:             "t1",
+		FirstName:      "Asha",
+		LastName:       "Rao",
+		DateOfBirth:    time.Date(1990, 4, 12, 0, 0, 0, 0, time.UTC),
+		PassportNumber: "Z1234567",
+	}}
+
+	for _, internal := range []bool{false, true} {
+		data, err := NewHTMLRenderer().Render(itinerary, RenderOptions{Internal: internal})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, detail := range []string{"Z1234567", "Apr 12, 1990"} {
+			if got := strings.Contains(string(data), detail); got != internal {
+				t.Errorf("internal=%v copy shows %s = %v", internal, detail, got)
+			}
+		}
+		if masked := strings.Contains(string(data), "****4567"); masked == internal {
+			t.Errorf("internal=%v copy shows the masked passport number = %v", internal, masked)
+		}
+	}
+	if itinerary.Travellers[0].PassportNumber != "Z1234567" {
+		t.Error("rendering the client copy changed the itinerary")
+	}
+}
+
+func TestDocumentsHideOperationsCopies(t *testing.T) {
+	store := storage.NewMemoryStore()
+	documents := NewDocumentService(store, storage.NewLocalDocumentStore(t.TempDir()))
+	itinerary := notedItinerary(t, store, "trip-1", "owner", "")
+	renderer := NewMarkdownRenderer()
+
+	client, err := documents.Archive(itinerary, FormatMarkdown, renderer, []byte("client copy"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	operations, err := documents.Archive(itinerary, FormatMarkdown, renderer, []byte("operations copy"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if listed := documents.ListDocuments(itinerary.ID, false); len(listed) != 1 || listed[0].ID != client.ID {
+		t.Errorf("documents listed without internal access = %v, want only the client copy", listed)
+	}
+	if listed := documents.ListDocuments(itinerary.ID, true); len(listed) != 2 {
+		t.Errorf("documents listed with internal access = %d, want 2", len(listed))
+	}
+	if _, _, err := documents.OpenDocument(itinerary.ID, operations.ID, false); err == nil {
+		t.Error("opened an operations copy without internal access")
+	}
+	if _, data, err := documents.OpenDocument(itinerary.ID, operations.ID, true); err != nil || string(data) != "operations copy" {
+		t.Errorf("OpenDocument with internal access = %q, %v", data, err)
+	}
+}
+
+func TestAuthorizeRoles(t *testing.T) {
+	store := storage.NewMemoryStore()
+	access := NewAccessService(store)
+	for _, id := range []string{"owner", "editor", "approver", "viewer", "stranger"} {
+		addUser(t, store, id, "", "")
+	}
+	itinerary := notedItinerary(t, store, "trip-1", "owner", "")
+	addGrant(t, store, itinerary.ID, "editor", models.RoleEditor)
+	addGrant(t, store, itinerary.ID, "approver", models.RoleApprover)
+	addGrant(t, store, itinerary.ID, "viewer", models.RoleViewer)
+
+	permissions := []string{models.PermissionView, models.PermissionEdit, models.PermissionApprove, models.PermissionManage}
+	allowed := map[string][]bool{
+		"owner":    {true, true, true, true},
+		"editor":   {true, true, false, false},
+		"approver": {true, false, true, false},
+		"viewer":   {true, false, false, false},
+	}
+	for userID, want := range allowed {
+		for i, permission := range permissions {
+			_, role, err := access.Authorize(itinerary.ID, userID, permission)
+			if role != userID {
+				t.Errorf("%s has the %q role", userID, role)
+			}
+			if (err == nil) != want[i] {
+				t.Errorf("%s may %s = %v, want %v", userID, permission, err == nil, want[i])
+			}
+			if err != nil && !errors.Is(err, ErrAccessDenied) {
+				t.Errorf("%s denied %s with %v, want ErrAccessDenied", userID, permission, err)
+			}
+		}
+	}
+
+	// Users without a role cannot tell the itinerary exists
+	_, _, err := access.Authorize(itinerary.ID, "stranger", models.PermissionView)
+	if err == nil || errors.Is(err, ErrAccessDenied) {
+		t.Errorf("stranger got %v, want a not found error", err)
+	}
+}
+
+func TestInvitationAppliesOnceAccepted(t *testing.T) {
+	store := storage.NewMemoryStore()
+	access := NewAccessService(store)
+	addUser(t, store, "owner", "", "")
+	itinerary := notedItinerary(t, store, "trip-1", "owner", "")
+
+	grant, err := access.Invite(itinerary.ID, "owner", &models.InviteCollaboratorRequest{Email: " Later@Example.com ", Role: "Editor"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if grant.Status != models.GrantPending || grant.Role != models.RoleEditor {
+		t.Errorf("invitation is %s with role %q, want pending editor", grant.Status, grant.Role)
+	}
+
+	addUser(t, store, "later", "", "")
+	if role := access.Role(itinerary, "later"); role != "" {
+		t.Errorf("signing up with the invited email gave the %q role before accepting", role)
+	}
+	addUser(t, store, "stranger", "", "")
+	if _, err := access.AcceptInvitation("stranger", grant.ID); !errors.Is(err, ErrInvitationNotFound) {
+		t.Errorf("accepting another email's invitation got %v, want ErrInvitationNotFound", err)
+	}
+
+	if _, err := access.AcceptInvitation("later", grant.ID); err != nil {
+		t.Fatal(err)
+	}
+	if role := access.Role(itinerary, "later"); role != models.RoleEditor {
+		t.Errorf("invited user has role %q after accepting, want editor", role)
+	}
+	if pending := access.Invitations("later"); len(pending) != 0 {
+		t.Errorf("%d invitations still pending after accepting", len(pending))
+	}
+}
+
+func TestInvitationMatchesSignupEmailCase(t *testing.T) {
+	store := storage.NewMemoryStore()
+	access := NewAccessService(store)
+	addUser(t, store, "owner", "", "")
+	itinerary := notedItinerary(t, store, "trip-1", "owner", "")
+	auth, err := NewAuthService(store).Signup(&models.SignupRequest{Email: " Asha.Rao@Example.com", Username: "asha", Password: "secret1", FullName: "Asha Rao"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auth.User.Email != "asha.rao@example.com" {
+		t.Errorf("signup stored the email as %q", auth.User.Email)
+	}
+	if _, err := NewAuthService(store).Signup(&models.SignupRequest{Email: "ASHA.RAO@example.com", Username: "asha2", Password: "secret1", FullName: "Asha Rao"}); err == nil {
+		t.Error("signed up twice with the same email in another case")
+	}
+
+	grant, err := access.Invite(itinerary.ID, "owner", &models.InviteCollaboratorRequest{Email: "asha.rao@example.com", Role: models.RoleViewer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pending := access.Invitations(auth.User.ID); len(pending) != 1 || pending[0].ID != grant.ID {
+		t.Errorf("the invitee sees invitations %v", pending)
+	}
+	if err := access.DeclineInvitation(auth.User.ID, grant.ID); err != nil {
+		t.Fatal(err)
+	}
+	if role := access.Role(itinerary, auth.User.ID); role != "" {
+		t.Errorf("declining left the %q role", role)
+	}
+}
+
+func TestRevokeOwnGrantOnly(t *testing.T) {
+	store := storage.NewMemoryStore()
+	access := NewAccessService(store)
+	for _, id := range []string{"owner", "editor", "viewer"} {
+		addUser(t, store, id, "", "")
+	}
+	itinerary := notedItinerary(t, store, "trip-1", "owner", "")
+	addGrant(t, store, itinerary.ID, "editor", models.RoleEditor)
+	addGrant(t, store, itinerary.ID, "viewer", models.RoleViewer)
+	grants := map[string]string{}
+	for _, grant := range store.GetAccessGrantsByItinerary(itinerary.ID) {
+		grants[grant.UserID] = grant.ID
+	}
+
+	if err := access.Revoke(itinerary.ID, "editor", grants["viewer"]); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("editor revoking the viewer got %v, want ErrAccessDenied", err)
+	}
+	if err := access.Revoke(itinerary.ID, "viewer", grants["viewer"]); err != nil {
+		t.Errorf("viewer leaving: %v", err)
+	}
+	if err := access.Revoke(itinerary.ID, "owner", grants["editor"]); err != nil {
+		t.Errorf("owner revoking the editor: %v", err)
+	}
+	if role := access.Role(itinerary, "editor"); role != "" {
+		t.Errorf("revoked editor still has the %q role", role)
+	}
+}
+
+func TestOrganizationRoles(t *testing.T) {
+	store := storage.NewMemoryStore()
+	access := NewAccessService(store)
+	addUser(t, store, "owner", "acme", models.OrgRoleAgent)
+	addUser(t, store, "admin", "acme", models.OrgRoleAdmin)
+	addUser(t, store, "agent", "acme", models.OrgRoleAgent)
+	addUser(t, store, "outsider", "globex", models.OrgRoleAdmin)
+	addUser(t, store, "personal", "", "")
+	itinerary := notedItinerary(t, store, "trip-1", "owner", "acme")
+	addGrant(t, store, itinerary.ID, "outsider", models.RoleEditor)
+
+	for userID, want := range map[string]string{
+		"owner":    models.RoleOwner,
+		"admin":    models.RoleAdmin,
+		"agent":    models.RoleViewer,
+		"outsider": "",
+		"personal": "",
+	} {
+		if role := access.Role(itinerary, userID); role != want {
+			t.Errorf("%s has the %q role, want %q", userID, role, want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"vigovia-task/models"
+	"vigovia-task/storage"
+	"vigovia-task/utils"
+)
+
+// ApprovalService runs the sign-off of itineraries: agents request approval
+// of a revision and approvers answer it
+type ApprovalService struct {
+	store       *storage.MemoryStore
+	itineraries *ItineraryService
+	mu          sync.Mutex // serialises requests and answers
+}
+
+// NewApprovalService creates a new instance of ApprovalService
+func NewApprovalService(store *storage.MemoryStore, itineraries *ItineraryService) *ApprovalService {
+	return &ApprovalService{
+		store:       store,
+		itineraries: itineraries,
+	}
+}
+
+// RequestApproval asks for approval of an itinerary's current revision. A
+// request still waiting for an answer is superseded.
+func (aps *ApprovalService) RequestApproval(itineraryID, userID string, req *models.RequestApprovalRequest) (*models.Approval, error) {
+	aps.mu.Lock()
+	defer aps.mu.Unlock()
+
+	itinerary, err := aps.store.GetByID(itineraryID)
+	if err != nil {
+		return nil, err
+	}
+	if err := aps.itineraries.SetApprovalStatus(itineraryID, itinerary.Revision, models.ApprovalPending); err != nil {
+		return nil, err
+	}
+
+	if err := supersedePendingApprovals(aps.store, itineraryID); err != nil {
+		return nil, err
+	}
+
+	approval := &models.Approval{
+		ID:          generateID("apr"),
+		ItineraryID: itineraryID,
+		Revision:    itinerary.Revision,
+		Status:      models.ApprovalPending,
+		Message:     strings.TrimSpace(req.Message),
+		RequestedBy: userID,
+		CreatedAt:   time.Now(),
+	}
+	if err := aps.store.CreateApproval(approval); err != nil {
+		return nil, err
+	}
+	// An edit saved since the status was set superseded the requests it
+	// found, which may not have included this one
+	if current, err := aps.store.GetByID(itineraryID); err == nil && current.Revision != approval.Revision {
+		if err := supersedePendingApprovals(aps.store, itineraryID); err != nil {
+			return nil, err
+		}
+		return nil, utils.NewValidationError(fmt.Sprintf("the itinerary has changed since revision %d; request approval of revision %d instead", approval.Revision, current.Revision))
+	}
+	return approval, nil
+}
+
+// ListApprovals returns an itinerary's approval requests, oldest first
+func (aps *ApprovalService) ListApprovals(itineraryID string) []*models.Approval {
+	return aps.store.GetApprovalsByItinerary(itineraryID)
+}
+
+// Respond records userID's answer to a pending approval request. The answer
+// only counts for the revision that was sent, so it is refused once the
+// itinerary has changed.
+func (aps *ApprovalService) Respond(itineraryID, approvalID, userID string, req *models.RespondApprovalRequest) (*models.Approval, error) {
+	req.Decision = strings.ToLower(strings.TrimSpace(req.Decision))
+	req.Comment = strings.TrimSpace(req.Comment)
+	if err := utils.ValidateApprovalResponse(req); err != nil {
+		return nil, err
+	}
+
+	aps.mu.Lock()
+	defer aps.mu.Unlock()
+
+	var approval *models.Approval
+	for _, existing := range aps.store.GetApprovalsByItinerary(itineraryID) {
+		if existing.ID == approvalID {
+			approval = existing
+		}
+	}
+	if approval == nil {
+		return nil, utils.NewValidationError(fmt.Sprintf("approval %s not found on itinerary", approvalID))
+	}
+	if approval.Status != models.ApprovalPending {
+		return nil, utils.NewValidationError(fmt.Sprintf("approval %s is already %s", approvalID, approval.Status))
+	}
+	if err := aps.itineraries.SetApprovalStatus(itineraryID, approval.Revision, req.Decision); err != nil {
+		return nil, err
+	}
+
+	answered := *approval
+	answered.Status = req.Decision
+	answered.RespondedBy = userID
+	answered.ResponseComment = req.Comment
+	answered.RespondedAt = time.Now()
+	if err := aps.store.UpdateApproval(&answered); err != nil {
+		return nil, err
+	}
+	return &answered, nil
+}
+
+// supersedePendingApprovals marks an itinerary's requests still waiting for
+// an answer as superseded
+func supersedePendingApprovals(store *storage.MemoryStore, itineraryID string) error {
+	for _, existing := range store.GetApprovalsByItinerary(itineraryID) {
+		if existing.Status != models.ApprovalPending {
+			continue
+		}
+		superseded := *existing
+		superseded.Status = models.ApprovalSuperseded
+		if err := store.UpdateApproval(&superseded); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"testing"
+
+	"vigovia-task/models"
+	"vigovia-task/storage"
+)
+
+func TestApprovalStatusKeepsRevision(t *testing.T) {
+	store := storage.NewMemoryStore()
+	itineraries := NewItineraryService(store, NewTravelCheckService(nil))
+	approvals := NewApprovalService(store, itineraries)
+	changed := 0
+	itineraries.OnChange(func(string) { changed++ })
+	stored := notedItinerary(t, store, "trip-1", "owner", "")
+
+	approval, err := approvals.RequestApproval(stored.ID, "owner", &models.RequestApprovalRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending, _ := store.GetByID(stored.ID)
+	if pending.ApprovalStatus != models.ApprovalPending || pending.Revision != 1 || !pending.UpdatedAt.After(stored.UpdatedAt) {
+		t.Errorf("after the request: status %q, revision %d, updated at %v", pending.ApprovalStatus, pending.Revision, pending.UpdatedAt)
+	}
+	if changed != 1 {
+		t.Errorf("requesting approval notified %d changes, want 1", changed)
+	}
+
+	if _, err := approvals.Respond(stored.ID, approval.ID, "approver", &models.RespondApprovalRequest{Decision: models.ApprovalApproved}); err != nil {
+		t.Fatalf("approving the requested revision: %v", err)
+	}
+	approved, _ := store.GetByID(stored.ID)
+	if approved.ApprovalStatus != models.ApprovalApproved || approved.Revision != 1 || !approved.UpdatedAt.After(pending.UpdatedAt) {
+		t.Errorf("after approval: status %q, revision %d", approved.ApprovalStatus, approved.Revision)
+	}
+	if changed != 2 {
+		t.Errorf("answering notified %d changes in all, want 2", changed)
+	}
+}
+
+func TestApprovalOfChangedRevisionRefused(t *testing.T) {
+	store := storage.NewMemoryStore()
+	itineraries := NewItineraryService(store, NewTravelCheckService(nil))
+	approvals := NewApprovalService(store, itineraries)
+	stored := notedItinerary(t, store, "trip-1", "owner", "")
+
+	approval, err := approvals.RequestApproval(stored.ID, "owner", &models.RequestApprovalRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := itineraries.UpdateItinerary(stored.ID, &models.UpdateItineraryRequest{Title: "Renamed"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := approvals.Respond(stored.ID, approval.ID, "approver", &models.RespondApprovalRequest{Decision: models.ApprovalApproved}); err == nil {
+		t.Error("approved a revision the itinerary has moved on from")
+	}
+}
+
+func TestEditAfterApprovalClearsStatus(t *testing.T) {
+	store := storage.NewMemoryStore()
+	itineraries := NewItineraryService(store, NewTravelCheckService(nil))
+	approvals := NewApprovalService(store, itineraries)
+	stored := notedItinerary(t, store, "trip-1", "owner", "")
+
+	approval, err := approvals.RequestApproval(stored.ID, "owner", &models.RequestApprovalRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := approvals.Respond(stored.ID, approval.ID, "approver", &models.RespondApprovalRequest{Decision: models.ApprovalApproved}); err != nil {
+		t.Fatal(err)
+	}
+	edited, err := itineraries.UpdateItinerary(stored.ID, &models.UpdateItineraryRequest{Title: "Renamed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edited.Revision != 2 || edited.ApprovalStatus != "" {
+		t.Errorf("after editing an approved itinerary: revision %d, status %q; want 2 and no status", edited.Revision, edited.ApprovalStatus)
+	}
+	if history := approvals.ListApprovals(stored.ID); history[0].Status != models.ApprovalApproved {
+		t.Errorf("the approval of revision 1 became %q", history[0].Status)
+	}
+}
+
+func TestEditWhilePendingSupersedesRequest(t *testing.T) {
+	store := storage.NewMemoryStore()
+	itineraries := NewItineraryService(store, NewTravelCheckService(nil))
+	approvals := NewApprovalService(store, itineraries)
+	stored := notedItinerary(t, store, "trip-1", "owner", "")
+
+	if _, err := approvals.RequestApproval(stored.ID, "owner", &models.RequestApprovalRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	edited, err := itineraries.UpdateItinerary(stored.ID, &models.UpdateItineraryRequest{Title: "Renamed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edited.ApprovalStatus != "" {
+		t.Errorf("an edit while pending left the status %q", edited.ApprovalStatus)
+	}
+	if history := approvals.ListApprovals(stored.ID); history[0].Status != models.ApprovalSuperseded {
+		t.Errorf("the open request is %q after the edit, want superseded", history[0].Status)
+	}
+
+	approval, err := approvals.RequestApproval(stored.ID, "owner", &models.RequestApprovalRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if approval.Revision != 2 {
+		t.Errorf("the new request is for revision %d, want 2", approval.Revision)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"strings"
 	"time"
 	"vigovia-task/models"
 	"vigovia-task/storage"
@@ -25,6 +26,10 @@ func NewAuthService(store *storage.MemoryStore) *AuthService {
 
 // Signup creates a new user account
 func (as *AuthService) Signup(req *models.SignupRequest) (*models.AuthResponse, error) {
+	// Emails are stored in lowercase, so invitations and logins match them
+	// whatever case they are typed in
+	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+
 	// Check if user already exists
 	_, err := as.store.GetUserByEmail(req.Email)
 	if err == nil {
@@ -0,0 +1,282 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"strings"
+	"sync"
+	"time"
+
+	"vigovia-task/models"
+	"vigovia-task/storage"
+	"vigovia-task/utils"
+)
+
+// Default palette reproducing the original Vigovia PDF look
+const (
+	defaultPrimaryColor = "#2980B9"
+	defaultAccentColor  = "#3498DB"
+	defaultHeadingColor = "#222222"
+	defaultDividerColor = "#D2D2D2"
+	defaultFooterText   = "Generated by Vigovia Itinerary Builder"
+)
+
+// BrandingService manages agency branding profiles
+type BrandingService struct {
+	store *storage.MemoryStore
+	mu    sync.Mutex // serialises edits, so a user keeps one default profile
+}
+
+// NewBrandingService creates a new instance of BrandingService
+func NewBrandingService(store *storage.MemoryStore) *BrandingService {
+	return &BrandingService{
+		store: store,
+	}
+}
+
+// DefaultBrandingProfile returns the built-in theme used when no profile applies
+func DefaultBrandingProfile() *models.BrandingProfile {
+	return &models.BrandingProfile{
+		ID:   "default",
+		Name: "Vigovia Default",
+		Colors: models.BrandingColors{
+			Primary: defaultPrimaryColor,
+			Accent:  defaultAccentColor,
+			Heading: defaultHeadingColor,
+			Divider: defaultDividerColor,
+		},
+		FooterText: defaultFooterText,
+	}
+}
+
+// CreateProfile creates a branding profile owned by userID
+func (bs *BrandingService) CreateProfile(userID string, req *models.BrandingProfileRequest) (*models.BrandingProfile, error) {
+	if err := utils.ValidateBrandingProfile(req); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	profile := &models.BrandingProfile{
+		ID:        generateID("brand"),
+		UserID:    userID,
+		CreatedAt: now,
+	}
+	applyBrandingRequest(profile, req)
+	profile.UpdatedAt = now
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	if err := bs.store.CreateBrandingProfile(profile); err != nil {
+		return nil, err
+	}
+	if profile.IsDefault {
+		if err := bs.clearOtherDefaults(profile); err != nil {
+			return nil, err
+		}
+	}
+
+	return profile, nil
+}
+
+// GetProfile retrieves a branding profile owned by userID
+func (bs *BrandingService) GetProfile(userID, id string) (*models.BrandingProfile, error) {
+	profile, err := bs.store.GetBrandingProfile(id)
+	if err != nil {
+		return nil, err
+	}
+	if profile.UserID != userID {
+		return nil, fmt.Errorf("branding profile with id %s not found", id)
+	}
+	return profile, nil
+}
+
+// ListProfiles retrieves all branding profiles owned by userID
+func (bs *BrandingService) ListProfiles(userID string) []*models.BrandingProfile {
+	return bs.store.GetBrandingProfilesByUser(userID)
+}
+
+// UpdateProfile replaces the settings of a branding profile owned by userID
+func (bs *BrandingService) UpdateProfile(userID, id string, req *models.BrandingProfileRequest) (*models.BrandingProfile, error) {
+	if err := utils.ValidateBrandingProfile(req); err != nil {
+		return nil, err
+	}
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	profile, err := bs.GetProfile(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// The stored profile may be read by a render at the same time, so a copy
+	// is edited and replaces it
+	updated := *profile
+	applyBrandingRequest(&updated, req)
+	updated.UpdatedAt = time.Now()
+
+	if err := bs.store.UpdateBrandingProfile(id, &updated); err != nil {
+		return nil, err
+	}
+	if updated.IsDefault {
+		if err := bs.clearOtherDefaults(&updated); err != nil {
+			return nil, err
+		}
+	}
+
+	return &updated, nil
+}
+
+// DeleteProfile deletes a branding profile owned by userID
+func (bs *BrandingService) DeleteProfile(userID, id string) error {
+	if _, err := bs.GetProfile(userID, id); err != nil {
+		return err
+	}
+	return bs.store.DeleteBrandingProfile(id)
+}
+
+// ResolveTheme picks the theme for a user's document: an explicitly requested
+// profile, otherwise the user's default profile, otherwise their
+// organization's theme, otherwise the built-in theme. The organization's
+// theme can also be requested by ID.
+func (bs *BrandingService) ResolveTheme(userID, profileID string) (*models.BrandingProfile, error) {
+	if profileID != "" {
+		if profile := bs.organizationProfile(userID); profile != nil && profile.ID == profileID {
+			return withDefaults(profile), nil
+		}
+		profile, err := bs.GetProfile(userID, profileID)
+		if err != nil {
+			return nil, err
+		}
+		return withDefaults(profile), nil
+	}
+
+	for _, profile := range bs.store.GetBrandingProfilesByUser(userID) {
+		if profile.IsDefault {
+			return withDefaults(profile), nil
+		}
+	}
+
+	if profile := bs.organizationProfile(userID); profile != nil {
+		return withDefaults(profile), nil
+	}
+	return DefaultBrandingProfile(), nil
+}
+
+// organizationProfile returns the theme set in the user's organization
+// settings, or nil when there is none
+func (bs *BrandingService) organizationProfile(userID string) *models.BrandingProfile {
+	user, err := bs.store.GetUserByID(userID)
+	if err != nil || user.OrganizationID == "" {
+		return nil
+	}
+	organization, err := bs.store.GetOrganization(user.OrganizationID)
+	if err != nil || organization.Settings.BrandingProfileID == "" {
+		return nil
+	}
+	profile, err := bs.store.GetBrandingProfile(organization.Settings.BrandingProfileID)
+	if err != nil {
+		return nil
+	}
+	return profile
+}
+
+// clearOtherDefaults stores copies of the user's other default profiles
+// that are no longer the default
+func (bs *BrandingService) clearOtherDefaults(current *models.BrandingProfile) error {
+	for _, profile := range bs.store.GetBrandingProfilesByUser(current.UserID) {
+		if profile.ID == current.ID || !profile.IsDefault {
+			continue
+		}
+		cleared := *profile
+		cleared.IsDefault = false
+		cleared.UpdatedAt = time.Now()
+		if err := bs.store.UpdateBrandingProfile(cleared.ID, &cleared); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyBrandingRequest(profile *models.BrandingProfile, req *models.BrandingProfileRequest) {
+	profile.Name = strings.TrimSpace(req.Name)
+	profile.IsDefault = req.IsDefault
+	profile.Logo = req.Logo
+	profile.LogoFormat = ""
+	if len(req.Logo) > 0 {
+		if _, format, err := image.DecodeConfig(bytes.NewReader(req.Logo)); err == nil {
+			profile.LogoFormat = format
+		}
+	}
+	profile.Colors = req.Colors
+	profile.Fonts = req.Fonts
+	profile.Fonts.Family = strings.TrimSpace(req.Fonts.Family)
+	profile.FooterText = strings.TrimSpace(req.FooterText)
+	profile.Contact = req.Contact
+	profile.TermsAndConditions = strings.TrimSpace(req.TermsAndConditions)
+}
+
+// withDefaults returns a copy of the profile with unset values taken from the
+// built-in theme
+func withDefaults(profile *models.BrandingProfile) *models.BrandingProfile {
+	theme := *profile
+	defaults := DefaultBrandingProfile()
+	if theme.Colors.Primary == "" {
+		theme.Colors.Primary = defaults.Colors.Primary
+	}
+	if theme.Colors.Accent == "" {
+		theme.Colors.Accent = defaults.Colors.Accent
+	}
+	if theme.Colors.Heading == "" {
+		theme.Colors.Heading = defaults.Colors.Heading
+	}
+	if theme.Colors.Divider == "" {
+		theme.Colors.Divider = defaults.Colors.Divider
+	}
+	if theme.FooterText == "" {
+		theme.FooterText = defaults.FooterText
+	}
+	return &theme
+}
+
+// PreviewItinerary returns a small sample itinerary used to preview themes
+func PreviewItinerary() *models.Itinerary {
+	start := time.Date(2024, time.November, 15, 0, 0, 0, 0, time.UTC)
+	return &models.Itinerary{
+		ID:          "preview",
+		Title:       "Jaipur Royal Heritage Tour",
+		Description: "A three-day journey through the forts, palaces and bazaars of the Pink City.",
+		StartDate:   start,
+		EndDate:     start.AddDate(0, 0, 2),
+		Location:    "Jaipur, India",
+		Hotels: []models.Hotel{
+			{Name: "Rambagh Palace", City: "Jaipur", CheckIn: start.Add(14 * time.Hour), CheckOut: start.AddDate(0, 0, 2).Add(11 * time.Hour), Nights: 2},
+		},
+		Flights: []models.Flight{
+			{Airline: "IndiGo", FlightNumber: "6E 2172", DepartureCity: "New Delhi", DepartureAirport: "DEL", DepartureTime: start.Add(8 * time.Hour), ArrivalCity: "Jaipur", ArrivalAirport: "JAI", ArrivalTime: start.Add(9 * time.Hour)},
+		},
+		Transfers: []models.Transfer{
+			{Mode: "private car", Pickup: "Jaipur Airport", Dropoff: "Rambagh Palace", PickupTime: "09:30"},
+		},
+		Days: []models.DayPlan{
+			{DayNumber: 1, Date: start, Title: "Arrival and City Palace", Activities: []models.Activity{
+				{Period: models.ActivityPeriodAfternoon, Time: "15:00", Title: "City Palace", Description: "Guided tour of the royal residence.", Location: "City Palace, Jaipur", Duration: "2 hours"},
+			}},
+			{DayNumber: 2, Date: start.AddDate(0, 0, 1), Title: "Amber Fort", Activities: []models.Activity{
+				{Period: models.ActivityPeriodMorning, Time: "08:00", Title: "Amber Fort guided tour", Description: "Explore the hilltop fort and Sheesh Mahal.", Location: "Amer, Jaipur", Duration: "3 hours"},
+				{Period: models.ActivityPeriodEvening, Time: "19:00", Title: "Chokhi Dhani dinner", Description: "Rajasthani cultural evening.", Location: "Chokhi Dhani", Duration: "3 hours"},
+			}},
+			{DayNumber: 3, Date: start.AddDate(0, 0, 2), Title: "Departure", Activities: []models.Activity{
+				{Period: models.ActivityPeriodMorning, Time: "10:00", Title: "Hawa Mahal photo stop", Description: "Last look at the Palace of Winds.", Location: "Hawa Mahal", Duration: "1 hour"},
+			}},
+		},
+		PaymentPlan: []models.PaymentInstallment{
+			{InstallmentNumber: 1, Amount: 45000, Currency: "INR", DueDate: start.AddDate(0, 0, -30), Status: "Paid"},
+			{InstallmentNumber: 2, Amount: 30000, Currency: "INR", DueDate: start.AddDate(0, 0, -7)},
+		},
+		Inclusions: []string{"Accommodation with breakfast", "Airport transfers"},
+		Exclusions: []string{"Monument entry fees"},
+	}
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"sync"
+	"testing"
+
+	"vigovia-task/models"
+	"vigovia-task/storage"
+)
+
+func TestUpdateProfileReplacesStoredProfile(t *testing.T) {
+	store := storage.NewMemoryStore()
+	branding := NewBrandingService(store)
+	first, err := branding.CreateProfile("agent", &models.BrandingProfileRequest{Name: "Classic", IsDefault: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := branding.CreateProfile("agent", &models.BrandingProfileRequest{Name: "Modern"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, _ := store.GetBrandingProfile(second.ID)
+
+	updated, err := branding.UpdateProfile("agent", second.ID, &models.BrandingProfileRequest{Name: "Modern Blue", IsDefault: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Name != "Modern" || stored.IsDefault {
+		t.Errorf("the update changed the profile read before it: %q, default %v", stored.Name, stored.IsDefault)
+	}
+	if !first.IsDefault {
+		t.Error("making another profile the default changed the profile read before it")
+	}
+	if current, _ := store.GetBrandingProfile(second.ID); current.Name != "Modern Blue" || !current.IsDefault || current != updated {
+		t.Errorf("stored profile %q, default %v", current.Name, current.IsDefault)
+	}
+	if current, _ := store.GetBrandingProfile(first.ID); current.IsDefault {
+		t.Error("the former default profile is still the default")
+	}
+
+	if _, err := branding.UpdateProfile("agent", second.ID, &models.BrandingProfileRequest{Name: " "}); err == nil {
+		t.Fatal("an update without a name was accepted")
+	}
+	if current, _ := store.GetBrandingProfile(second.ID); current.Name != "Modern Blue" {
+		t.Errorf("a rejected update left the name %q", current.Name)
+	}
+}
+
+func TestConcurrentDefaultProfiles(t *testing.T) {
+	store := storage.NewMemoryStore()
+	branding := NewBrandingService(store)
+	ids := make([]string, 4)
+	for i := range ids {
+		profile, err := branding.CreateProfile("agent", &models.BrandingProfileRequest{Name: "Theme"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = profile.ID
+	}
+
+	var wg sync.WaitGroup
+	for _, id := range ids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := branding.UpdateProfile("agent", id, &models.BrandingProfileRequest{Name: "Theme", IsDefault: true}); err != nil {
+				t.Error(err)
+			}
+			branding.ResolveTheme("agent", "")
+		}()
+	}
+	wg.Wait()
+
+	defaults := 0
+	for _, profile := range branding.ListProfiles("agent") {
+		if profile.IsDefault {
+			defaults++
+		}
+	}
+	if defaults != 1 {
+		t.Errorf("%d default profiles, want 1", defaults)
+	}
+}
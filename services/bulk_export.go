@@ -0,0 +1,117 @@
+package services
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"vigovia-task/models"
+)
+
+// MaxBulkExportItineraries caps the number of itineraries in one archive
+const MaxBulkExportItineraries = 200
+
+// FormatZip is the format of batch export jobs, which produce a ZIP archive
+// of PDFs
+const FormatZip = "zip"
+
+// BulkExportFileName names the ZIP archive of a bulk export made at t
+func BulkExportFileName(t time.Time) string {
+	return fmt.Sprintf("itineraries_%s.zip", t.Format("2006-01-02"))
+}
+
+// BulkExportItem is one itinerary of a bulk export with its render options
+type BulkExportItem struct {
+	Itinerary *models.Itinerary
+	Options   RenderOptions
+}
+
+var bulkManifestHeader = []string{"file_name", "itinerary_id", "title", "location", "start_date", "end_date", "size_bytes", "sha256", "status", "error"}
+
+// WriteZip streams a ZIP archive with one PDF per item, named like the saved
+// exports, followed by manifest.csv. Only one document is held in memory at a
+// time. An itinerary that fails to render is listed in the manifest with its
+// error and the archive continues.
+func (ps *PDFService) WriteZip(w io.Writer, items []BulkExportItem, cache *RenderCache) error {
+	archive := zip.NewWriter(w)
+	manifest := [][]string{bulkManifestHeader}
+	names := make(map[string]bool)
+
+	for _, item := range items {
+		itinerary := item.Itinerary
+		name := uniqueArchiveName(names, ps.buildFileName(itinerary))
+		row := []string{name, itinerary.ID, csvSafe(itinerary.Title), csvSafe(itinerary.Location), formatISODate(itinerary.StartDate), formatISODate(itinerary.EndDate)}
+
+		var data []byte
+		var err error
+		if cache != nil {
+			var rendered *RenderedDocument
+			if rendered, err = cache.Render(itinerary, FormatPDF, ps, item.Options); err == nil {
+				data = rendered.Data
+			}
+		} else {
+			data, err = ps.GeneratePDF(itinerary, item.Options)
+		}
+		if err != nil {
+			manifest = append(manifest, append(row, "", "", "failed", csvSafe(err.Error())))
+			continue
+		}
+
+		entry, err := archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: itinerary.UpdatedAt})
+		if err != nil {
+			return err
+		}
+		if _, err := entry.Write(data); err != nil {
+			return err
+		}
+
+		sum := sha256.Sum256(data)
+		manifest = append(manifest, append(row, strconv.Itoa(len(data)), hex.EncodeToString(sum[:]), "ok", ""))
+	}
+
+	entry, err := archive.CreateHeader(&zip.FileHeader{Name: "manifest.csv", Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	if err := csv.NewWriter(entry).WriteAll(manifest); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+// uniqueArchiveName appends -2, -3, ... to a name already used in the
+// archive until it finds one that is not, and records the name it returns
+func uniqueArchiveName(used map[string]bool, name string) string {
+	base, extension := name, ""
+	if dot := strings.LastIndex(name, "."); dot > 0 {
+		base, extension = name[:dot], name[dot:]
+	}
+	candidate := name
+	for n := 2; used[candidate]; n++ {
+		candidate = fmt.Sprintf("%s-%d%s", base, n, extension)
+	}
+	used[candidate] = true
+	return candidate
+}
+
+func formatISODate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+// csvSafe stops spreadsheet applications from evaluating user text as a formula
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
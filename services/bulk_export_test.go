@@ -0,0 +1,27 @@
+package services
+
+import "testing"
+
+func TestUniqueArchiveName(t *testing.T) {
+	used := make(map[string]bool)
+	names := []string{"x-2.pdf", "x.pdf", "x.pdf", "x.pdf", "notes", "notes"}
+	want := []string{"x-2.pdf", "x.pdf", "x-3.pdf", "x-4.pdf", "notes", "notes-2"}
+	for i, name := range names {
+		if got := uniqueArchiveName(used, name); got != want[i] {
+			t.Errorf("name %d %q = %q, want %q", i, name, got, want[i])
+		}
+	}
+}
+
+func TestCSVSafe(t *testing.T) {
+	for value, want := range map[string]string{
+		"=HYPERLINK(\"x\")": "'=HYPERLINK(\"x\")",
+		"-2":                "'-2",
+		"Goa":               "Goa",
+		"":                  "",
+	} {
+		if got := csvSafe(value); got != want {
+			t.Errorf("csvSafe(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"vigovia-task/models"
+	"vigovia-task/utils"
+)
+
+// applyCatalogue fills in activities that reference a catalogue entry from
+// that entry. Entries must be in the catalogue of the itinerary's
+// organization, or of its owner outside any organization.
+func (is *ItineraryService) applyCatalogue(organizationID, ownerID string, days []models.DayPlan) error {
+	for i := range days {
+		for j := range days[i].Activities {
+			activity := &days[i].Activities[j]
+			activity.CatalogueID = strings.TrimSpace(activity.CatalogueID)
+			if activity.CatalogueID == "" {
+				activity.CatalogueLink = false
+				continue
+			}
+			entry, err := is.store.GetCatalogueEntry(activity.CatalogueID)
+			if err != nil || !entry.InCatalogueOf(organizationID, strings.TrimSpace(ownerID)) {
+				return utils.NewValidationError(fmt.Sprintf("catalogue entry %s not found", activity.CatalogueID))
+			}
+			fillFromCatalogue(activity, entry)
+		}
+	}
+	return nil
+}
+
+// SyncCatalogueEntry brings the activities referencing a catalogue entry up
+// to date after it changes. Linked activities take on its new details; when
+// the entry is removed every activity keeps its details and loses the
+// reference.
+func (is *ItineraryService) SyncCatalogueEntry(entry *models.CatalogueEntry, removed bool) error {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	for _, itinerary := range is.store.GetByOrganization(entry.OrganizationID) {
+		days := copyDays(itinerary.Days)
+		changed := false
+		for i := range days {
+			for j := range days[i].Activities {
+				activity := &days[i].Activities[j]
+				if activity.CatalogueID != entry.ID {
+					continue
+				}
+				if removed {
+					activity.CatalogueID = ""
+					activity.CatalogueLink = false
+					changed = true
+				} else if activity.CatalogueLink {
+					fillFromCatalogue(activity, entry)
+					changed = true
+				}
+			}
+		}
+		if !changed {
+			continue
+		}
+
+		updated := *itinerary
+		updated.Days = days
+		if err := is.save(&updated, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fillFromCatalogue copies a catalogue entry's details into an activity. A
+// linked activity takes every detail the entry has; otherwise only the
+// activity's empty details are filled in.
+func fillFromCatalogue(activity *models.Activity, entry *models.CatalogueEntry) {
+	fill := func(field *string, value string) {
+		if value != "" && (activity.CatalogueLink || strings.TrimSpace(*field) == "") {
+			*field = value
+		}
+	}
+	fill(&activity.Title, entry.Title)
+	fill(&activity.Description, entry.Description)
+	fill(&activity.Location, entry.Location)
+	fill(&activity.Duration, entry.Duration)
+	fill(&activity.Period, entry.Period)
+}
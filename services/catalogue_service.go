@@ -0,0 +1,199 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"vigovia-task/models"
+	"vigovia-task/storage"
+	"vigovia-task/utils"
+)
+
+// ErrCatalogueEntryReadOnly is returned when a user tries to change a
+// catalogue entry they neither created nor administer
+var ErrCatalogueEntryReadOnly = errors.New("only its creator or an organization admin can change a catalogue entry")
+
+// CatalogueService keeps each organization's catalogue of activities and
+// points of interest that day plans can reuse
+type CatalogueService struct {
+	store       *storage.MemoryStore
+	access      *AccessService
+	itineraries *ItineraryService
+}
+
+// NewCatalogueService creates a new instance of CatalogueService
+func NewCatalogueService(store *storage.MemoryStore, access *AccessService, itineraries *ItineraryService) *CatalogueService {
+	return &CatalogueService{
+		store:       store,
+		access:      access,
+		itineraries: itineraries,
+	}
+}
+
+// CreateEntry adds an entry to the catalogue userID works with: their
+// organization's, or their own outside any organization
+func (cs *CatalogueService) CreateEntry(userID string, req *models.CatalogueEntryRequest) (*models.CatalogueEntry, error) {
+	normalizeCatalogueRequest(req)
+	if err := utils.ValidateCatalogueEntry(req); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	entry := &models.CatalogueEntry{
+		ID:             generateID("cat"),
+		OrganizationID: cs.access.Tenant(userID),
+		CreatedBy:      userID,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	applyCatalogueRequest(entry, req)
+	if err := cs.store.CreateCatalogueEntry(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// GetEntry retrieves an entry of the catalogue userID works with
+func (cs *CatalogueService) GetEntry(userID, id string) (*models.CatalogueEntry, error) {
+	entry, err := cs.store.GetCatalogueEntry(id)
+	if err != nil || !entry.InCatalogueOf(cs.access.Tenant(userID), userID) {
+		return nil, fmt.Errorf("catalogue entry with id %s not found", id)
+	}
+	return entry, nil
+}
+
+// Search returns the entries of userID's catalogue matching the query, by
+// title. Text fields match when they contain the value, ignoring case; a tag
+// or period must match exactly.
+func (cs *CatalogueService) Search(userID string, query *models.CatalogueQuery) []*models.CatalogueEntry {
+	text := strings.ToLower(strings.TrimSpace(query.Query))
+	tag := strings.ToLower(strings.TrimSpace(query.Tag))
+	location := strings.ToLower(strings.TrimSpace(query.Location))
+	period := strings.ToLower(strings.TrimSpace(query.Period))
+
+	tenant := cs.access.Tenant(userID)
+	matches := []*models.CatalogueEntry{}
+	for _, entry := range cs.store.GetCatalogueByOrganization(tenant) {
+		if !entry.InCatalogueOf(tenant, userID) {
+			continue
+		}
+		if tag != "" && !containsString(entry.Tags, tag) {
+			continue
+		}
+		if period != "" && entry.Period != period {
+			continue
+		}
+		if location != "" && !strings.Contains(strings.ToLower(entry.Location), location) {
+			continue
+		}
+		if text != "" && !catalogueEntryMatches(entry, text) {
+			continue
+		}
+		matches = append(matches, entry)
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if !strings.EqualFold(matches[i].Title, matches[j].Title) {
+			return strings.ToLower(matches[i].Title) < strings.ToLower(matches[j].Title)
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	return matches
+}
+
+// UpdateEntry replaces an entry's details. Activities linked to the entry
+// pick up the new details.
+func (cs *CatalogueService) UpdateEntry(userID, id string, req *models.CatalogueEntryRequest) (*models.CatalogueEntry, error) {
+	entry, err := cs.editableEntry(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	normalizeCatalogueRequest(req)
+	if err := utils.ValidateCatalogueEntry(req); err != nil {
+		return nil, err
+	}
+
+	updated := *entry
+	applyCatalogueRequest(&updated, req)
+	updated.UpdatedAt = time.Now()
+	if err := cs.store.UpdateCatalogueEntry(&updated); err != nil {
+		return nil, err
+	}
+	if err := cs.itineraries.SyncCatalogueEntry(&updated, false); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteEntry removes an entry from the catalogue. Activities that
+// referenced it keep their details but lose the reference.
+func (cs *CatalogueService) DeleteEntry(userID, id string) error {
+	entry, err := cs.editableEntry(userID, id)
+	if err != nil {
+		return err
+	}
+	if err := cs.store.DeleteCatalogueEntry(id); err != nil {
+		return err
+	}
+	return cs.itineraries.SyncCatalogueEntry(entry, true)
+}
+
+// editableEntry retrieves an entry userID may change: one they created, or
+// any entry of an organization they administer
+func (cs *CatalogueService) editableEntry(userID, id string) (*models.CatalogueEntry, error) {
+	entry, err := cs.GetEntry(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if entry.CreatedBy == userID {
+		return entry, nil
+	}
+	user, err := cs.store.GetUserByID(userID)
+	if err != nil || user.OrganizationRole != models.OrgRoleAdmin {
+		return nil, ErrCatalogueEntryReadOnly
+	}
+	return entry, nil
+}
+
+// normalizeCatalogueRequest trims the request and lower-cases its period and
+// tags and upper-cases its currency
+func normalizeCatalogueRequest(req *models.CatalogueEntryRequest) {
+	req.Title = strings.TrimSpace(req.Title)
+	req.Description = strings.TrimSpace(req.Description)
+	req.Location = strings.TrimSpace(req.Location)
+	req.Duration = strings.TrimSpace(req.Duration)
+	req.Period = strings.ToLower(strings.TrimSpace(req.Period))
+	req.Currency = strings.ToUpper(strings.TrimSpace(req.Currency))
+	for i, tag := range req.Tags {
+		req.Tags[i] = strings.ToLower(strings.TrimSpace(tag))
+	}
+}
+
+func applyCatalogueRequest(entry *models.CatalogueEntry, req *models.CatalogueEntryRequest) {
+	entry.Title = req.Title
+	entry.Description = req.Description
+	entry.Location = req.Location
+	entry.Duration = req.Duration
+	entry.Period = req.Period
+	entry.Price = req.Price
+	entry.Currency = req.Currency
+	entry.Tags = append([]string(nil), req.Tags...)
+	entry.Coordinates = nil
+	if req.Coordinates != nil {
+		point := *req.Coordinates
+		entry.Coordinates = &point
+	}
+}
+
+func catalogueEntryMatches(entry *models.CatalogueEntry, query string) bool {
+	fields := append([]string{entry.Title, entry.Description, entry.Location}, entry.Tags...)
+	for _, field := range fields {
+		if strings.Contains(strings.ToLower(field), query) {
+			return true
+		}
+	}
+	return false
+}
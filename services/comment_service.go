@@ -0,0 +1,171 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"vigovia-task/models"
+	"vigovia-task/storage"
+	"vigovia-task/utils"
+)
+
+// CommentService keeps the comment threads on itineraries
+type CommentService struct {
+	store  *storage.MemoryStore
+	access *AccessService
+}
+
+// NewCommentService creates a new instance of CommentService
+func NewCommentService(store *storage.MemoryStore, access *AccessService) *CommentService {
+	return &CommentService{
+		store:  store,
+		access: access,
+	}
+}
+
+// ListComments returns an itinerary's comment threads, oldest first, with
+// replies nested under the comments they answer. A day or activity ID keeps
+// only the threads on it.
+func (cs *CommentService) ListComments(itineraryID, dayID, activityID string) []*models.Comment {
+	comments := cs.store.GetCommentsByItinerary(itineraryID)
+	copies := make(map[string]*models.Comment, len(comments))
+	for _, comment := range comments {
+		copied := *comment
+		copied.Replies = nil
+		copies[comment.ID] = &copied
+	}
+
+	threads := make([]*models.Comment, 0)
+	for _, comment := range comments {
+		copied := copies[comment.ID]
+		if parent, ok := copies[comment.ParentID]; ok {
+			parent.Replies = append(parent.Replies, copied)
+			continue
+		}
+		if dayID != "" && copied.DayID != dayID {
+			continue
+		}
+		if activityID != "" && copied.ActivityID != activityID {
+			continue
+		}
+		threads = append(threads, copied)
+	}
+	return threads
+}
+
+// AddComment posts a comment by userID on an itinerary, one of its days or
+// activities, or in reply to another comment
+func (cs *CommentService) AddComment(itineraryID, userID string, req *models.CreateCommentRequest) (*models.Comment, error) {
+	req.Body = strings.TrimSpace(req.Body)
+	if err := utils.ValidateComment(req.Body); err != nil {
+		return nil, err
+	}
+
+	itinerary, err := cs.store.GetByID(itineraryID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	comment := &models.Comment{
+		ID:          generateID("cmt"),
+		ItineraryID: itineraryID,
+		AuthorID:    userID,
+		Body:        req.Body,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if user, err := cs.store.GetUserByID(userID); err == nil {
+		comment.AuthorName = user.FullName
+	}
+
+	switch {
+	case req.ParentID != "":
+		parent, err := cs.comment(itineraryID, req.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		comment.ParentID = parent.ID
+		comment.DayID = parent.DayID
+		comment.ActivityID = parent.ActivityID
+	case req.DayID != "" && req.ActivityID != "":
+		return nil, utils.NewValidationError("a comment can be on a day or an activity, not both")
+	case req.DayID != "":
+		idx, err := dayIndexByRef(itinerary.Days, req.DayID)
+		if err != nil {
+			return nil, err
+		}
+		comment.DayID = itinerary.Days[idx].ID
+	case req.ActivityID != "":
+		if _, _, err := activityIndex(itinerary.Days, req.ActivityID); err != nil {
+			return nil, err
+		}
+		comment.ActivityID = req.ActivityID
+	}
+
+	if err := cs.store.CreateComment(comment); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// UpdateComment changes the text of a comment. Only its author can.
+func (cs *CommentService) UpdateComment(itineraryID, userID, commentID string, req *models.UpdateCommentRequest) (*models.Comment, error) {
+	req.Body = strings.TrimSpace(req.Body)
+	if err := utils.ValidateComment(req.Body); err != nil {
+		return nil, err
+	}
+
+	comment, err := cs.comment(itineraryID, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.AuthorID != userID {
+		return nil, fmt.Errorf("%w: only the author can edit a comment", ErrAccessDenied)
+	}
+
+	updated := *comment
+	updated.Body = req.Body
+	updated.UpdatedAt = time.Now()
+	if err := cs.store.UpdateComment(&updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteComment removes a comment along with its replies. Authors can delete
+// their own comments, and users who may manage the itinerary any comment.
+func (cs *CommentService) DeleteComment(itineraryID, userID, commentID string) error {
+	comment, err := cs.comment(itineraryID, commentID)
+	if err != nil {
+		return err
+	}
+	if comment.AuthorID != userID {
+		if _, _, err := cs.access.Authorize(itineraryID, userID, models.PermissionManage); err != nil {
+			return err
+		}
+	}
+
+	children := make(map[string][]string)
+	for _, c := range cs.store.GetCommentsByItinerary(itineraryID) {
+		children[c.ParentID] = append(children[c.ParentID], c.ID)
+	}
+	pending := []string{comment.ID}
+	for len(pending) > 0 {
+		id := pending[0]
+		pending = append(pending[1:], children[id]...)
+		if err := cs.store.DeleteComment(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cs *CommentService) comment(itineraryID, commentID string) (*models.Comment, error) {
+	comment, err := cs.store.GetComment(commentID)
+	if err != nil || comment.ItineraryID != itineraryID {
+		return nil, utils.NewValidationError(fmt.Sprintf("comment %s not found on itinerary", commentID))
+	}
+	return comment, nil
+}
@@ -0,0 +1,318 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+
+	"vigovia-task/models"
+	"vigovia-task/utils"
+)
+
+// InsertDay adds a day plan at a position. The new day takes the date of the
+// day it displaces, and that day and every later one move a day later.
+func (is *ItineraryService) InsertDay(itineraryID string, req *models.InsertDayRequest) (*models.Itinerary, error) {
+	return is.editDays(itineraryID, func(days []models.DayPlan) ([]models.DayPlan, error) {
+		position := req.Position
+		if position < 0 {
+			return nil, utils.NewValidationError("position must not be negative")
+		}
+		if position == 0 || position > len(days) {
+			position = len(days) + 1
+		}
+
+		day := models.DayPlan{Title: req.Title, Activities: newActivities(req.Activities), InternalNotes: req.InternalNotes}
+		if position <= len(days) {
+			day.Date = days[position-1].Date
+		} else if len(days) > 0 {
+			day.Date = days[len(days)-1].Date.AddDate(0, 0, 1)
+		}
+		for i := position - 1; i < len(days); i++ {
+			days[i].Date = days[i].Date.AddDate(0, 0, 1)
+		}
+
+		days = append(days, models.DayPlan{})
+		copy(days[position:], days[position-1:])
+		days[position-1] = day
+		return days, nil
+	})
+}
+
+// GetDay finds a day plan by its ID or day number
+func (is *ItineraryService) GetDay(itineraryID, dayRef string) (*models.DayPlan, error) {
+	itinerary, err := is.store.GetByID(itineraryID)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := dayIndexByRef(itinerary.Days, dayRef)
+	if err != nil {
+		return nil, err
+	}
+	day := itinerary.Days[idx]
+	return &day, nil
+}
+
+// UpdateDay replaces a day plan's title and activities, and its date when
+// one is given. The date must fall after the previous day's and before the
+// next day's. Activities without an ID are added as new ones.
+func (is *ItineraryService) UpdateDay(itineraryID, dayRef string, day *models.DayPlan) (*models.Itinerary, error) {
+	return is.editDays(itineraryID, func(days []models.DayPlan) ([]models.DayPlan, error) {
+		idx, err := dayIndexByRef(days, dayRef)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkComponentIDs("activity", activityIDs([]models.DayPlan{*day}), activityIDs(days)); err != nil {
+			return nil, err
+		}
+		days[idx].Title = day.Title
+		days[idx].Activities = append([]models.Activity(nil), day.Activities...)
+		days[idx].InternalNotes = day.InternalNotes
+		if !day.Date.IsZero() {
+			if idx > 0 && daysBetween(days[idx-1].Date, day.Date) < 1 {
+				return nil, utils.NewValidationError(fmt.Sprintf("day %d must be dated after day %d (%s)", idx+1, idx, days[idx-1].Date.Format("2006-01-02")))
+			}
+			if idx < len(days)-1 && daysBetween(day.Date, days[idx+1].Date) < 1 {
+				return nil, utils.NewValidationError(fmt.Sprintf("day %d must be dated before day %d (%s)", idx+1, idx+2, days[idx+1].Date.Format("2006-01-02")))
+			}
+			days[idx].Date = day.Date
+		}
+		return days, nil
+	})
+}
+
+// DeleteDay removes a day plan, found by its ID or day number, and moves
+// every later day a day earlier
+func (is *ItineraryService) DeleteDay(itineraryID, dayRef string) (*models.Itinerary, error) {
+	return is.editDays(itineraryID, func(days []models.DayPlan) ([]models.DayPlan, error) {
+		idx, err := dayIndexByRef(days, dayRef)
+		if err != nil {
+			return nil, err
+		}
+		for i := idx + 1; i < len(days); i++ {
+			days[i].Date = days[i].Date.AddDate(0, 0, -1)
+		}
+		return append(days[:idx], days[idx+1:]...), nil
+	})
+}
+
+// ReorderDays puts the day plans in the given order of their current day
+// numbers. The dates stay in place, so each plan takes the date of its new
+// position.
+func (is *ItineraryService) ReorderDays(itineraryID string, order []int) (*models.Itinerary, error) {
+	return is.editDays(itineraryID, func(days []models.DayPlan) ([]models.DayPlan, error) {
+		if len(order) != len(days) {
+			return nil, utils.NewValidationError(fmt.Sprintf("order must list all %d days", len(days)))
+		}
+		reordered := make([]models.DayPlan, 0, len(days))
+		used := make(map[int]bool, len(order))
+		for _, dayNumber := range order {
+			idx, err := dayIndex(days, dayNumber)
+			if err != nil {
+				return nil, err
+			}
+			if used[idx] {
+				return nil, utils.NewValidationError(fmt.Sprintf("day %d is listed more than once", dayNumber))
+			}
+			used[idx] = true
+			reordered = append(reordered, days[idx])
+		}
+		for i := range reordered {
+			reordered[i].Date = days[i].Date
+		}
+		return reordered, nil
+	})
+}
+
+// SwapDays trades the plans of two days, each keeping its date
+func (is *ItineraryService) SwapDays(itineraryID string, first, second int) (*models.Itinerary, error) {
+	return is.editDays(itineraryID, func(days []models.DayPlan) ([]models.DayPlan, error) {
+		i, err := dayIndex(days, first)
+		if err != nil {
+			return nil, err
+		}
+		j, err := dayIndex(days, second)
+		if err != nil {
+			return nil, err
+		}
+		days[i].Date, days[j].Date = days[j].Date, days[i].Date
+		days[i], days[j] = days[j], days[i]
+		return days, nil
+	})
+}
+
+// GetActivity finds an activity by its ID
+func (is *ItineraryService) GetActivity(itineraryID, activityID string) (*models.Activity, error) {
+	itinerary, err := is.store.GetByID(itineraryID)
+	if err != nil {
+		return nil, err
+	}
+	dayIdx, activityIdx, err := activityIndex(itinerary.Days, activityID)
+	if err != nil {
+		return nil, err
+	}
+	activity := itinerary.Days[dayIdx].Activities[activityIdx]
+	return &activity, nil
+}
+
+// UpdateActivity replaces an activity, keeping its ID
+func (is *ItineraryService) UpdateActivity(itineraryID, activityID string, activity *models.Activity) (*models.Itinerary, error) {
+	return is.editDays(itineraryID, func(days []models.DayPlan) ([]models.DayPlan, error) {
+		dayIdx, activityIdx, err := activityIndex(days, activityID)
+		if err != nil {
+			return nil, err
+		}
+		updated := *activity
+		updated.ID = activityID
+		days[dayIdx].Activities[activityIdx] = updated
+		return days, nil
+	})
+}
+
+// DeleteActivity removes an activity from its day
+func (is *ItineraryService) DeleteActivity(itineraryID, activityID string) (*models.Itinerary, error) {
+	return is.editDays(itineraryID, func(days []models.DayPlan) ([]models.DayPlan, error) {
+		dayIdx, activityIdx, err := activityIndex(days, activityID)
+		if err != nil {
+			return nil, err
+		}
+		activities := days[dayIdx].Activities
+		days[dayIdx].Activities = append(activities[:activityIdx], activities[activityIdx+1:]...)
+		return days, nil
+	})
+}
+
+// MoveActivity moves an activity to another day, or to another position on
+// the same day
+func (is *ItineraryService) MoveActivity(itineraryID, activityID string, req *models.MoveActivityRequest) (*models.Itinerary, error) {
+	return is.editDays(itineraryID, func(days []models.DayPlan) ([]models.DayPlan, error) {
+		if req.Position < 0 {
+			return nil, utils.NewValidationError("position must not be negative")
+		}
+		dayIdx, activityIdx, err := activityIndex(days, activityID)
+		if err != nil {
+			return nil, err
+		}
+		targetIdx, err := dayIndex(days, req.DayNumber)
+		if err != nil {
+			return nil, err
+		}
+
+		source := days[dayIdx].Activities
+		activity := source[activityIdx]
+		days[dayIdx].Activities = append(source[:activityIdx], source[activityIdx+1:]...)
+
+		target := days[targetIdx].Activities
+		position := req.Position
+		if position == 0 || position > len(target) {
+			position = len(target) + 1
+		}
+		target = append(target, models.Activity{})
+		copy(target[position:], target[position-1:])
+		target[position-1] = activity
+		days[targetIdx].Activities = target
+		return days, nil
+	})
+}
+
+// editDays applies edit to a copy of an itinerary's day plans, numbers the
+// result from 1 and stores it only if every day still validates, so a
+// rejected edit leaves the itinerary as it was. Day 1 may not be dated before
+// the trip starts, and the trip ends on the date of the last day.
+func (is *ItineraryService) editDays(itineraryID string, edit func(days []models.DayPlan) ([]models.DayPlan, error)) (*models.Itinerary, error) {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	itinerary, err := is.store.GetByID(itineraryID)
+	if err != nil {
+		return nil, err
+	}
+
+	days, err := edit(copyDays(itinerary.Days))
+	if err != nil {
+		return nil, err
+	}
+	if len(days) == 0 {
+		return nil, utils.NewValidationError("at least one day plan is required")
+	}
+	if daysBetween(itinerary.StartDate, days[0].Date) < 0 {
+		return nil, utils.NewValidationError(fmt.Sprintf("day 1 cannot be dated before the trip starts on %s", itinerary.StartDate.Format("2006-01-02")))
+	}
+	days = normalizeDays(days)
+	if err := is.applyCatalogue(itinerary.OrganizationID, itinerary.UserID, days); err != nil {
+		return nil, err
+	}
+	for i := range days {
+		days[i].DayNumber = i + 1
+		if err := utils.ValidateDayPlan(&days[i]); err != nil {
+			return nil, err
+		}
+	}
+	if err := utils.ValidateActivityIDs(days); err != nil {
+		return nil, err
+	}
+
+	// The stored itinerary is replaced rather than changed in place, so
+	// readers holding it never see a half-applied edit
+	updated := *itinerary
+	updated.Days = days
+	updated.EndDate = calendarDate(days[len(days)-1].Date)
+	is.refreshDerived(&updated)
+	if err := is.save(&updated, true); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// copyDays copies day plans along with their activity slices
+func copyDays(days []models.DayPlan) []models.DayPlan {
+	copied := make([]models.DayPlan, len(days))
+	for i, day := range days {
+		day.Activities = append([]models.Activity(nil), day.Activities...)
+		copied[i] = day
+	}
+	return copied
+}
+
+func dayIndex(days []models.DayPlan, dayNumber int) (int, error) {
+	for i, day := range days {
+		if day.DayNumber == dayNumber {
+			return i, nil
+		}
+	}
+	return 0, utils.NewValidationError(fmt.Sprintf("day %d not found in itinerary", dayNumber))
+}
+
+// dayIndexByRef finds a day by its ID, or else by a day number
+func dayIndexByRef(days []models.DayPlan, ref string) (int, error) {
+	for i, day := range days {
+		if day.ID == ref {
+			return i, nil
+		}
+	}
+	dayNumber, err := strconv.Atoi(ref)
+	if err != nil {
+		return 0, utils.NewValidationError(fmt.Sprintf("day %s not found in itinerary", ref))
+	}
+	return dayIndex(days, dayNumber)
+}
+
+// newActivities copies activities for adding to a day, dropping any IDs so
+// each gets a new one
+func newActivities(activities []models.Activity) []models.Activity {
+	added := make([]models.Activity, len(activities))
+	for i, activity := range activities {
+		activity.ID = ""
+		added[i] = activity
+	}
+	return added
+}
+
+func activityIndex(days []models.DayPlan, activityID string) (int, int, error) {
+	for i, day := range days {
+		for j, activity := range day.Activities {
+			if activity.ID == activityID {
+				return i, j, nil
+			}
+		}
+	}
+	return 0, 0, utils.NewValidationError(fmt.Sprintf("activity %s not found in itinerary", activityID))
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"vigovia-task/models"
+	"vigovia-task/storage"
+	"vigovia-task/utils"
+)
+
+// datedItinerary stores an itinerary with one valid day plan per date,
+// starting on start
+func datedItinerary(t *testing.T, store *storage.MemoryStore, start time.Time, days int) *models.Itinerary {
+	t.Helper()
+	itinerary := &models.Itinerary{
+		ID:        "trip-1",
+		UserID:    "owner",
+		Title:     "Kerala Backwaters",
+		StartDate: start,
+		EndDate:   start.AddDate(0, 0, days-1),
+		Revision:  1,
+	}
+	for i := 0; i < days; i++ {
+		itinerary.Days = append(itinerary.Days, models.DayPlan{
+			ID:         generateID("day"),
+			DayNumber:  i + 1,
+			Date:       start.AddDate(0, 0, i),
+			Title:      "Houseboat",
+			Activities: []models.Activity{{ID: generateID("act"), Title: "Cruise", Period: "morning", Time: "09:00", Description: "Lake cruise", Location: "Alleppey"}},
+		})
+	}
+	if err := store.Create(itinerary); err != nil {
+		t.Fatal(err)
+	}
+	return itinerary
+}
+
+func TestDayEditsMoveEndDate(t *testing.T) {
+	store := storage.NewMemoryStore()
+	itineraries := NewItineraryService(store, NewTravelCheckService(nil))
+	start := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+	datedItinerary(t, store, start, 3)
+
+	updated, err := itineraries.DeleteDay("trip-1", "3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := start.AddDate(0, 0, 1); !updated.EndDate.Equal(want) {
+		t.Errorf("end date after deleting the last day = %v, want %v", updated.EndDate, want)
+	}
+
+	updated, err = itineraries.DeleteDay("trip-1", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !updated.EndDate.Equal(start) || !updated.Days[0].Date.Equal(start) {
+		t.Errorf("after deleting the first of two days the trip ends %v with day 1 on %v, want both %v", updated.EndDate, updated.Days[0].Date, start)
+	}
+
+	updated, err = itineraries.InsertDay("trip-1", &models.InsertDayRequest{
+		Title:      "Munnar",
+		Activities: []models.Activity{{Title: "Tea estate", Period: "afternoon", Time: "14:00", Description: "Plantation walk", Location: "Munnar"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := start.AddDate(0, 0, 1); !updated.EndDate.Equal(want) {
+		t.Errorf("end date after appending a day = %v, want %v", updated.EndDate, want)
+	}
+}
+
+func TestUpdateDayKeepsDayOrder(t *testing.T) {
+	store := storage.NewMemoryStore()
+	itineraries := NewItineraryService(store, NewTravelCheckService(nil))
+	start := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+	datedItinerary(t, store, start, 3)
+	day := func(date time.Time) *models.DayPlan {
+		return &models.DayPlan{
+			Title:      "Houseboat",
+			Date:       date,
+			Activities: []models.Activity{{Title: "Cruise", Period: "morning", Time: "09:00", Description: "Lake cruise", Location: "Alleppey"}},
+		}
+	}
+
+	for _, tc := range []struct {
+		name   string
+		dayRef string
+		date   time.Time
+	}{
+		{"day 1 before the start", "1", start.AddDate(0, 0, -1)},
+		{"day 2 on day 1's date", "2", start},
+		{"day 2 after day 3", "2", start.AddDate(0, 0, 5)},
+		{"day 3 before day 2", "3", start},
+	} {
+		_, err := itineraries.UpdateDay("trip-1", tc.dayRef, day(tc.date))
+		var validationErr *utils.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("moving %s got %v, want a validation error", tc.name, err)
+		}
+	}
+	if stored, _ := store.GetByID("trip-1"); stored.Revision != 1 {
+		t.Errorf("rejected date changes saved revision %d", stored.Revision)
+	}
+
+	updated, err := itineraries.UpdateDay("trip-1", "3", day(start.AddDate(0, 0, 4)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := start.AddDate(0, 0, 4); !updated.EndDate.Equal(want) {
+		t.Errorf("moving the last day later ends the trip on %v, want %v", updated.EndDate, want)
+	}
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"vigovia-task/models"
+	"vigovia-task/storage"
+)
+
+// DocumentService archives exported documents and lists them per itinerary
+type DocumentService struct {
+	store *storage.MemoryStore
+	blobs storage.DocumentStore
+}
+
+// NewDocumentService creates a new instance of DocumentService
+func NewDocumentService(store *storage.MemoryStore, blobs storage.DocumentStore) *DocumentService {
+	return &DocumentService{
+		store: store,
+		blobs: blobs,
+	}
+}
+
+// Archive stores an exported document under a key derived from its SHA-256
+// checksum. Identical exports share one stored object and one record.
+// Internal marks an operations copy, which carries internal notes.
+func (ds *DocumentService) Archive(itinerary *models.Itinerary, format string, renderer Renderer, data []byte, internal bool) (*models.Document, error) {
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+
+	for _, existing := range ds.store.GetDocumentsByItinerary(itinerary.ID) {
+		if existing.Checksum == checksum {
+			return existing, nil
+		}
+	}
+
+	key := fmt.Sprintf("documents/%s/%s%s", checksum[:2], checksum, renderer.FileExtension())
+	exists, err := ds.blobs.Exists(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to archive document: %w", err)
+	}
+	if !exists {
+		if err := ds.blobs.Put(key, data, renderer.ContentType()); err != nil {
+			return nil, fmt.Errorf("failed to archive document: %w", err)
+		}
+	}
+
+	document := &models.Document{
+		ID:          generateID("doc"),
+		ItineraryID: itinerary.ID,
+		UserID:      itinerary.UserID,
+		Format:      format,
+		Internal:    internal,
+		FileName:    ExportFileName(itinerary, renderer),
+		ContentType: renderer.ContentType(),
+		Size:        int64(len(data)),
+		Checksum:    checksum,
+		StorageKey:  key,
+		CreatedAt:   time.Now(),
+	}
+	if err := ds.store.CreateDocument(document); err != nil {
+		return nil, err
+	}
+
+	return document, nil
+}
+
+// ArchiveBulk stores the ZIP archive of a batch export job for userID. It
+// belongs to no single itinerary, so it is only reachable through its job.
+func (ds *DocumentService) ArchiveBulk(userID string, data []byte, internal bool) (*models.Document, error) {
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+
+	key := fmt.Sprintf("documents/%s/%s.zip", checksum[:2], checksum)
+	if err := ds.blobs.Put(key, data, "application/zip"); err != nil {
+		return nil, fmt.Errorf("failed to archive document: %w", err)
+	}
+
+	now := time.Now()
+	document := &models.Document{
+		ID:          generateID("doc"),
+		UserID:      userID,
+		Format:      FormatZip,
+		Internal:    internal,
+		FileName:    BulkExportFileName(now),
+		ContentType: "application/zip",
+		Size:        int64(len(data)),
+		Checksum:    checksum,
+		StorageKey:  key,
+		CreatedAt:   now,
+	}
+	if err := ds.store.CreateDocument(document); err != nil {
+		return nil, err
+	}
+
+	return document, nil
+}
+
+// ListDocuments retrieves the archived documents of an itinerary. Operations
+// copies are left out unless internal is set.
+func (ds *DocumentService) ListDocuments(itineraryID string, internal bool) []*models.Document {
+	documents := make([]*models.Document, 0)
+	for _, document := range ds.store.GetDocumentsByItinerary(itineraryID) {
+		if document.Internal && !internal {
+			continue
+		}
+		documents = append(documents, document)
+	}
+	return documents
+}
+
+// OpenDocument returns an archived document of an itinerary and its content.
+// Operations copies are found only when internal is set.
+func (ds *DocumentService) OpenDocument(itineraryID, documentID string, internal bool) (*models.Document, []byte, error) {
+	document, err := ds.store.GetDocument(documentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if document.ItineraryID != itineraryID || (document.Internal && !internal) {
+		return nil, nil, fmt.Errorf("document with id %s not found", documentID)
+	}
+
+	data, err := ds.blobs.Get(document.StorageKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read document %s: %w", documentID, err)
+	}
+
+	return document, data, nil
+}
@@ -0,0 +1,392 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"vigovia-task/models"
+	"vigovia-task/storage"
+)
+
+// Errors returned by ExportService.Enqueue when a concurrency limit is reached
+var (
+	ErrExportQueueFull = errors.New("export queue is full, try again later")
+	ErrTooManyExports  = errors.New("too many exports in progress for this user")
+)
+
+// ExportServiceConfig sets the limits of the export worker pool
+type ExportServiceConfig struct {
+	// Workers is the number of documents rendered concurrently
+	Workers int
+	// QueueSize is the number of jobs that may wait for a worker
+	QueueSize int
+	// MaxActivePerUser caps the queued and running jobs of a single user
+	MaxActivePerUser int
+	// MaxAttempts is the number of times a failing job is tried
+	MaxAttempts int
+	// RetryDelay is multiplied by the attempt number between retries
+	RetryDelay time.Duration
+}
+
+// DefaultExportServiceConfig returns the limits used by the API server
+func DefaultExportServiceConfig() ExportServiceConfig {
+	return ExportServiceConfig{
+		Workers:          2,
+		QueueSize:        100,
+		MaxActivePerUser: 5,
+		MaxAttempts:      3,
+		RetryDelay:       time.Second,
+	}
+}
+
+// exportTask carries what a worker needs to run a job. A batch job has an
+// item per itinerary and no renderer.
+type exportTask struct {
+	jobID    string
+	renderer Renderer
+	opts     RenderOptions
+	items    []BulkExportItem
+}
+
+// ExportService renders documents in a background worker pool and archives
+// the results in document storage
+type ExportService struct {
+	store     *storage.MemoryStore
+	renderers *RendererRegistry
+	pdf       *PDFService
+	branding  *BrandingService
+	documents *DocumentService
+	access    *AccessService
+	config    ExportServiceConfig
+	queue     chan exportTask
+	mu        sync.Mutex // serialises the per-user limit check with job creation
+}
+
+// NewExportService creates an ExportService and starts its workers
+func NewExportService(store *storage.MemoryStore, renderers *RendererRegistry, pdf *PDFService, branding *BrandingService, documents *DocumentService, access *AccessService, config ExportServiceConfig) *ExportService {
+	defaults := DefaultExportServiceConfig()
+	if config.Workers <= 0 {
+		config.Workers = defaults.Workers
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaults.QueueSize
+	}
+	if config.MaxActivePerUser <= 0 {
+		config.MaxActivePerUser = defaults.MaxActivePerUser
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaults.MaxAttempts
+	}
+
+	es := &ExportService{
+		store:     store,
+		renderers: renderers,
+		pdf:       pdf,
+		branding:  branding,
+		documents: documents,
+		access:    access,
+		config:    config,
+		queue:     make(chan exportTask, config.QueueSize),
+	}
+	for i := 0; i < config.Workers; i++ {
+		go es.worker()
+	}
+
+	return es
+}
+
+// Enqueue validates an export request for userID and queues it
+func (es *ExportService) Enqueue(userID, itineraryID string, req *models.ExportJobRequest) (*models.ExportJob, error) {
+	itinerary, err := es.store.GetByID(itineraryID)
+	if err != nil {
+		return nil, err
+	}
+
+	format := req.Format
+	if format == "" {
+		format = FormatPDF
+	}
+	renderer, err := es.renderers.Get(format)
+	if err != nil {
+		return nil, err
+	}
+
+	theme, err := es.branding.ResolveTheme(itinerary.UserID, req.Theme)
+	if err != nil {
+		return nil, err
+	}
+
+	margin := ""
+	if req.Margin != 0 {
+		margin = strconv.FormatFloat(req.Margin, 'f', -1, 64)
+	}
+	layout, err := NewPageLayout(req.PageSize, req.Orientation, margin, req.Layout)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &models.ExportJob{
+		ID:          generateID("job"),
+		ItineraryID: itinerary.ID,
+		UserID:      userID,
+		Format:      normalizeFormat(format),
+		Internal:    req.Internal,
+		Status:      models.ExportJobQueued,
+		MaxAttempts: es.config.MaxAttempts,
+		CreatedAt:   time.Now(),
+	}
+	return es.submit(job, exportTask{renderer: renderer, opts: RenderOptions{Theme: theme, Layout: layout, Internal: req.Internal}})
+}
+
+// EnqueueBulk queues a batch job rendering a ZIP archive of PDFs of the
+// itineraries, like the synchronous bulk export. The caller has checked that
+// userID may export each of them.
+func (es *ExportService) EnqueueBulk(userID string, itineraries []*models.Itinerary, req *models.BulkExportRequest) (*models.ExportJob, error) {
+	if len(itineraries) > MaxBulkExportItineraries {
+		return nil, fmt.Errorf("at most %d itineraries can be exported at once", MaxBulkExportItineraries)
+	}
+
+	margin := ""
+	if req.Margin != 0 {
+		margin = strconv.FormatFloat(req.Margin, 'f', -1, 64)
+	}
+	layout, err := NewPageLayout(req.PageSize, req.Orientation, margin, req.Layout)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]BulkExportItem, 0, len(itineraries))
+	ids := make([]string, 0, len(itineraries))
+	for _, itinerary := range itineraries {
+		theme, err := es.branding.ResolveTheme(itinerary.UserID, req.Theme)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, BulkExportItem{Itinerary: itinerary, Options: RenderOptions{Theme: theme, Layout: layout, Internal: req.Internal}})
+		ids = append(ids, itinerary.ID)
+	}
+
+	job := &models.ExportJob{
+		ID:           generateID("job"),
+		ItineraryIDs: ids,
+		UserID:       userID,
+		Format:       FormatZip,
+		Internal:     req.Internal,
+		Status:       models.ExportJobQueued,
+		MaxAttempts:  es.config.MaxAttempts,
+		CreatedAt:    time.Now(),
+	}
+	return es.submit(job, exportTask{items: items})
+}
+
+// submit stores a job and hands its task to the workers
+func (es *ExportService) submit(job *models.ExportJob, task exportTask) (*models.ExportJob, error) {
+	if err := es.admit(job); err != nil {
+		return nil, err
+	}
+
+	task.jobID = job.ID
+	select {
+	case es.queue <- task:
+	default:
+		es.finish(job, func(j *models.ExportJob) {
+			j.Status = models.ExportJobFailed
+			j.Error = ErrExportQueueFull.Error()
+		})
+		return nil, ErrExportQueueFull
+	}
+
+	return job, nil
+}
+
+// admit creates a job unless its user is at the limit of active jobs. The
+// check and the creation happen under one lock so that concurrent requests
+// cannot both take the last slot.
+func (es *ExportService) admit(job *models.ExportJob) error {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	if es.store.CountActiveExportJobs(job.UserID) >= es.config.MaxActivePerUser {
+		return ErrTooManyExports
+	}
+	return es.store.CreateExportJob(job)
+}
+
+// GetJob retrieves an export job owned by userID. Access to the exported
+// itineraries is checked again, so a user who has lost access to one of them,
+// or edit access for an operations copy, can no longer read the job.
+func (es *ExportService) GetJob(userID, jobID string) (*models.ExportJob, error) {
+	job, err := es.store.GetExportJob(jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.UserID != userID {
+		return nil, fmt.Errorf("export job with id %s not found", jobID)
+	}
+
+	for _, itineraryID := range job.Itineraries() {
+		_, role, err := es.access.Authorize(itineraryID, userID, models.PermissionView)
+		if err == nil && job.Internal {
+			err = CheckInternalNotes(role)
+		}
+		if errors.Is(err, ErrAccessDenied) {
+			return nil, err
+		}
+		if err != nil {
+			return nil, fmt.Errorf("export job with id %s not found", jobID)
+		}
+	}
+	return job, nil
+}
+
+// OpenResult returns the document produced by a finished job of userID
+func (es *ExportService) OpenResult(userID, jobID string) (*models.Document, []byte, error) {
+	job, err := es.GetJob(userID, jobID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if job.Status != models.ExportJobSucceeded {
+		return nil, nil, fmt.Errorf("export job %s is %s", jobID, job.Status)
+	}
+	return es.documents.OpenDocument(job.ItineraryID, job.DocumentID, job.Internal)
+}
+
+func (es *ExportService) worker() {
+	for task := range es.queue {
+		es.run(task)
+	}
+}
+
+// run executes a job, retrying failed attempts with a linear backoff
+func (es *ExportService) run(task exportTask) {
+	job, err := es.store.GetExportJob(task.jobID)
+	if err != nil {
+		return
+	}
+
+	started := time.Now()
+	job = es.update(job, func(j *models.ExportJob) {
+		j.Status = models.ExportJobRunning
+		j.StartedAt = &started
+	})
+
+	for {
+		job = es.update(job, func(j *models.ExportJob) {
+			j.Attempts++
+			j.Progress = 0
+			j.Error = ""
+		})
+
+		document, err := es.attempt(job, task)
+		if err == nil {
+			es.finish(job, func(j *models.ExportJob) {
+				j.Status = models.ExportJobSucceeded
+				j.Progress = 100
+				j.DocumentID = document.ID
+				j.DownloadURL = fmt.Sprintf("/api/exports/%s/download", j.ID)
+			})
+			return
+		}
+
+		log.Printf("export job %s attempt %d failed: %v", job.ID, job.Attempts, err)
+		if job.Attempts >= job.MaxAttempts {
+			es.finish(job, func(j *models.ExportJob) {
+				j.Status = models.ExportJobFailed
+				j.Error = err.Error()
+			})
+			return
+		}
+
+		job = es.update(job, func(j *models.ExportJob) { j.Error = err.Error() })
+		time.Sleep(es.config.RetryDelay * time.Duration(job.Attempts))
+	}
+}
+
+// attempt renders and archives the document once. Rendering reports 0-90%,
+// archiving the rest.
+func (es *ExportService) attempt(job *models.ExportJob, task exportTask) (document *models.Document, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("render panicked: %v", recovered)
+		}
+	}()
+
+	if task.items != nil {
+		return es.attemptBulk(job, task)
+	}
+
+	itinerary, err := es.store.GetByID(job.ItineraryID)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := task.opts
+	opts.Progress = func(percent int) {
+		job = es.update(job, func(j *models.ExportJob) { j.Progress = percent * 90 / 100 })
+	}
+
+	data, err := task.renderer.Render(itinerary, opts)
+	if err != nil {
+		return nil, err
+	}
+	job = es.update(job, func(j *models.ExportJob) { j.Progress = 90 })
+
+	return es.documents.Archive(itinerary, job.Format, task.renderer, data, job.Internal)
+}
+
+// attemptBulk renders the ZIP archive of a batch job from the current
+// version of each itinerary, leaving out those deleted since it was queued.
+// Each itinerary takes an equal share of the first 90% of progress.
+func (es *ExportService) attemptBulk(job *models.ExportJob, task exportTask) (*models.Document, error) {
+	items := make([]BulkExportItem, 0, len(task.items))
+	for _, item := range task.items {
+		itinerary, err := es.store.GetByID(item.Itinerary.ID)
+		if err != nil {
+			continue
+		}
+		item.Itinerary = itinerary
+		items = append(items, item)
+	}
+	if len(items) == 0 {
+		return nil, errors.New("every itinerary of the export has been deleted")
+	}
+
+	for i := range items {
+		done := i
+		items[i].Options.Progress = func(percent int) {
+			job = es.update(job, func(j *models.ExportJob) { j.Progress = (done*100 + percent) * 90 / (100 * len(items)) })
+		}
+	}
+
+	var archive bytes.Buffer
+	if err := es.pdf.WriteZip(&archive, items, nil); err != nil {
+		return nil, err
+	}
+	job = es.update(job, func(j *models.ExportJob) { j.Progress = 90 })
+
+	return es.documents.ArchiveBulk(job.UserID, archive.Bytes(), job.Internal)
+}
+
+// update stores a modified copy of job and returns it
+func (es *ExportService) update(job *models.ExportJob, modify func(*models.ExportJob)) *models.ExportJob {
+	next := *job
+	modify(&next)
+	if err := es.store.UpdateExportJob(&next); err != nil {
+		log.Printf("update export job %s: %v", job.ID, err)
+	}
+	return &next
+}
+
+// finish records the final state of a job
+func (es *ExportService) finish(job *models.ExportJob, modify func(*models.ExportJob)) {
+	completed := time.Now()
+	es.update(job, func(j *models.ExportJob) {
+		modify(j)
+		j.CompletedAt = &completed
+	})
+}
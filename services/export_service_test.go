@@ -0,0 +1,177 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"vigovia-task/models"
+	"vigovia-task/storage"
+)
+
+// blockingRenderer renders once release is closed
+type blockingRenderer struct {
+	release chan struct{}
+}
+
+func (r *blockingRenderer) Render(itinerary *models.Itinerary, opts RenderOptions) ([]byte, error) {
+	<-r.release
+	return []byte("rendered " + itinerary.ID), nil
+}
+
+func (r *blockingRenderer) ContentType() string   { return "application/pdf" }
+func (r *blockingRenderer) FileExtension() string { return ".pdf" }
+
+// newTestExportService returns an ExportService over store whose PDF
+// renderer is renderer
+func newTestExportService(t *testing.T, store *storage.MemoryStore, renderer Renderer, config ExportServiceConfig) *ExportService {
+	t.Helper()
+	pdf, err := NewPDFService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	renderers := &RendererRegistry{renderers: map[string]Renderer{FormatPDF: renderer}}
+	documents := NewDocumentService(store, storage.NewLocalDocumentStore(t.TempDir()))
+	return NewExportService(store, renderers, pdf, NewBrandingService(store), documents, NewAccessService(store), config)
+}
+
+// waitForJob polls a job until it succeeds or fails
+func waitForJob(t *testing.T, store *storage.MemoryStore, jobID string) *models.ExportJob {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := store.GetExportJob(jobID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Status == models.ExportJobSucceeded || job.Status == models.ExportJobFailed {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("export job %s did not finish", jobID)
+	return nil
+}
+
+func TestExportLimitHoldsUnderConcurrentRequests(t *testing.T) {
+	store := storage.NewMemoryStore()
+	renderer := &blockingRenderer{release: make(chan struct{})}
+	exports := newTestExportService(t, store, renderer, ExportServiceConfig{Workers: 1, QueueSize: 50, MaxActivePerUser: 3})
+	addUser(t, store, "owner", "", "")
+	itinerary := notedItinerary(t, store, "trip-1", "owner", "")
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var accepted []string
+	limited := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			job, err := exports.Enqueue("owner", itinerary.ID, &models.ExportJobRequest{})
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				accepted = append(accepted, job.ID)
+			case errors.Is(err, ErrTooManyExports):
+				limited++
+			default:
+				t.Errorf("Enqueue: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(accepted) != 3 || limited != 17 {
+		t.Errorf("accepted %d and limited %d jobs, want 3 and 17", len(accepted), limited)
+	}
+
+	close(renderer.release)
+	for _, jobID := range accepted {
+		waitForJob(t, store, jobID)
+	}
+}
+
+func TestExportJobRechecksAccess(t *testing.T) {
+	store := storage.NewMemoryStore()
+	renderer := &blockingRenderer{release: make(chan struct{})}
+	close(renderer.release)
+	exports := newTestExportService(t, store, renderer, ExportServiceConfig{})
+	addUser(t, store, "owner", "", "")
+	addUser(t, store, "editor", "", "")
+	itinerary := notedItinerary(t, store, "trip-1", "owner", "")
+	addGrant(t, store, itinerary.ID, "editor", models.RoleEditor)
+
+	job, err := exports.Enqueue("editor", itinerary.ID, &models.ExportJobRequest{Internal: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if finished := waitForJob(t, store, job.ID); finished.Status != models.ExportJobSucceeded {
+		t.Fatalf("job %s: %s", finished.Status, finished.Error)
+	}
+	if _, _, err := exports.OpenResult("editor", job.ID); err != nil {
+		t.Fatalf("editor downloading their job: %v", err)
+	}
+	if _, err := exports.GetJob("owner", job.ID); err == nil {
+		t.Error("another user read the editor's job")
+	}
+
+	grant := store.GetAccessGrantsByItinerary(itinerary.ID)[0]
+	viewer := *grant
+	viewer.Role = models.RoleViewer
+	if err := store.UpdateAccessGrant(&viewer); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := exports.OpenResult("editor", job.ID); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("downloading an operations copy as a viewer: %v, want ErrAccessDenied", err)
+	}
+
+	if err := store.DeleteAccessGrant(grant.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := exports.GetJob("editor", job.ID); err == nil {
+		t.Error("a revoked collaborator still reads the job")
+	}
+}
+
+func TestBulkExportJob(t *testing.T) {
+	store := storage.NewMemoryStore()
+	exports := newTestExportService(t, store, NewMarkdownRenderer(), ExportServiceConfig{})
+	addUser(t, store, "owner", "", "")
+	first := notedItinerary(t, store, "trip-1", "owner", "")
+	second := notedItinerary(t, store, "trip-2", "owner", "")
+
+	job, err := exports.EnqueueBulk("owner", []*models.Itinerary{first, second}, &models.BulkExportRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Format != FormatZip || len(job.ItineraryIDs) != 2 {
+		t.Errorf("batch job format %q for %v", job.Format, job.ItineraryIDs)
+	}
+	if finished := waitForJob(t, store, job.ID); finished.Status != models.ExportJobSucceeded || finished.Progress != 100 {
+		t.Fatalf("job %s at %d%%: %s", finished.Status, finished.Progress, finished.Error)
+	}
+
+	document, data, err := exports.OpenResult("owner", job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if document.ContentType != "application/zip" {
+		t.Errorf("content type %q", document.ContentType)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := map[string]bool{}
+	for _, file := range archive.File {
+		names[file.Name] = true
+	}
+	if len(archive.File) != 3 || len(names) != 3 || !names["manifest.csv"] {
+		t.Errorf("archive holds %v, want two PDFs and manifest.csv", names)
+	}
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"vigovia-task/models"
+)
+
+// Minimum connection times between the legs of a journey
+const (
+	defaultMinimumConnection        = 60 * time.Minute
+	terminalChangeMinimumConnection = 90 * time.Minute
+	airportChangeMinimumConnection  = 180 * time.Minute
+)
+
+// buildJourneys groups the flights sharing a journey ID into journeys with
+// their layovers, ordered by departure
+func buildJourneys(flights []models.Flight) []models.Journey {
+	indexes := make(map[string][]int)
+	var ids []string
+	for idx, flight := range flights {
+		if flight.JourneyID == "" {
+			continue
+		}
+		if _, seen := indexes[flight.JourneyID]; !seen {
+			ids = append(ids, flight.JourneyID)
+		}
+		indexes[flight.JourneyID] = append(indexes[flight.JourneyID], idx)
+	}
+
+	journeys := make([]models.Journey, 0, len(ids))
+	for _, id := range ids {
+		legs := indexes[id]
+		sort.SliceStable(legs, func(i, j int) bool {
+			return flights[legs[i]].DepartureTime.Before(flights[legs[j]].DepartureTime)
+		})
+
+		first, last := flights[legs[0]], flights[legs[len(legs)-1]]
+		journey := models.Journey{
+			ID:            id,
+			FlightIndexes: legs,
+			Origin:        first.DepartureAirport,
+			Destination:   last.ArrivalAirport,
+			DepartureTime: first.DepartureTime,
+			ArrivalTime:   last.ArrivalTime,
+		}
+		for i := 1; i < len(legs); i++ {
+			journey.Layovers = append(journey.Layovers, newLayover(flights[legs[i-1]], flights[legs[i]]))
+		}
+		journeys = append(journeys, journey)
+	}
+
+	sort.SliceStable(journeys, func(i, j int) bool {
+		return journeys[i].DepartureTime.Before(journeys[j].DepartureTime)
+	})
+	return journeys
+}
+
+func newLayover(arriving, departing models.Flight) models.Layover {
+	return models.Layover{
+		ArrivalAirport:           arriving.ArrivalAirport,
+		DepartureAirport:         departing.DepartureAirport,
+		DurationMinutes:          int(departing.DepartureTime.Sub(arriving.ArrivalTime).Minutes()),
+		MinimumConnectionMinutes: int(minimumConnection(arriving, departing).Minutes()),
+	}
+}
+
+// minimumConnection is the time needed to make a connection, longer when it
+// involves changing terminal or airport
+func minimumConnection(arriving, departing models.Flight) time.Duration {
+	switch {
+	case !strings.EqualFold(arriving.ArrivalAirport, departing.DepartureAirport):
+		return airportChangeMinimumConnection
+	case arriving.ArrivalTerminal != "" && departing.DepartureTerminal != "" &&
+		!strings.EqualFold(arriving.ArrivalTerminal, departing.DepartureTerminal):
+		return terminalChangeMinimumConnection
+	default:
+		return defaultMinimumConnection
+	}
+}
+
+// connectionWarnings flags layovers shorter than their minimum connection
+// time and connections that change airport
+func connectionWarnings(flights []models.Flight) []models.TravelWarning {
+	var warnings []models.TravelWarning
+	for _, journey := range buildJourneys(flights) {
+		for i, layover := range journey.Layovers {
+			arriving := flights[journey.FlightIndexes[i]]
+			departing := flights[journey.FlightIndexes[i+1]]
+
+			if layover.ChangesAirport() {
+				warnings = append(warnings, models.TravelWarning{
+					Code:     "airport_change",
+					Severity: models.WarningSeverityWarning,
+					Message: fmt.Sprintf("Journey %s arrives at %s on %s but %s departs from %s",
+						journey.ID, layover.ArrivalAirport, arriving.FlightNumber, departing.FlightNumber, layover.DepartureAirport),
+				})
+			}
+			if layover.DurationMinutes < layover.MinimumConnectionMinutes {
+				warnings = append(warnings, models.TravelWarning{
+					Code:     "short_connection",
+					Severity: models.WarningSeverityWarning,
+					Message: fmt.Sprintf("Connection from %s to %s at %s is %s, less than the %s minimum",
+						arriving.FlightNumber, departing.FlightNumber, layover.ArrivalAirport,
+						formatMinutes(layover.DurationMinutes), formatMinutes(layover.MinimumConnectionMinutes)),
+				})
+			}
+		}
+	}
+	return warnings
+}
+
+// flightGroup is a connecting journey, or a single flight when Journey is nil,
+// as laid out by the renderers
+type flightGroup struct {
+	Journey *models.Journey
+	Legs    []flightLeg
+}
+
+// flightLeg is a flight with its position in the itinerary and the layover
+// before it
+type flightLeg struct {
+	Index   int
+	Flight  models.Flight
+	Layover *models.Layover
+}
+
+// groupFlights lists the flights in their original order with the legs of
+// each journey kept together where its first leg appears
+func groupFlights(flights []models.Flight) []flightGroup {
+	journeys := make(map[string]models.Journey)
+	for _, journey := range buildJourneys(flights) {
+		journeys[journey.ID] = journey
+	}
+
+	var groups []flightGroup
+	emitted := make(map[string]bool)
+	for idx, flight := range flights {
+		journey, ok := journeys[flight.JourneyID]
+		if !ok || len(journey.FlightIndexes) < 2 {
+			groups = append(groups, flightGroup{Legs: []flightLeg{{Index: idx, Flight: flight}}})
+			continue
+		}
+		if emitted[journey.ID] {
+			continue
+		}
+		emitted[journey.ID] = true
+
+		group := flightGroup{Journey: &journey}
+		for i, legIdx := range journey.FlightIndexes {
+			leg := flightLeg{Index: legIdx, Flight: flights[legIdx]}
+			if i > 0 {
+				leg.Layover = &journey.Layovers[i-1]
+			}
+			group.Legs = append(group.Legs, leg)
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// journeyTitle names a journey by its route, e.g. "Journey: DEL - DXB - CDG"
+func journeyTitle(journey *models.Journey, flights []models.Flight) string {
+	stops := []string{journey.Origin}
+	for i, idx := range journey.FlightIndexes {
+		if i > 0 && !strings.EqualFold(flights[idx].DepartureAirport, stops[len(stops)-1]) {
+			stops = append(stops, flights[idx].DepartureAirport)
+		}
+		stops = append(stops, flights[idx].ArrivalAirport)
+	}
+	return "Journey: " + strings.Join(stops, " - ")
+}
+
+// layoverLabel describes a connection, e.g. "Layover at DXB: 2h 35m"
+func layoverLabel(layover *models.Layover) string {
+	label := fmt.Sprintf("Layover at %s: %s", layover.ArrivalAirport, formatMinutes(layover.DurationMinutes))
+	if layover.ChangesAirport() {
+		label += fmt.Sprintf(", change to %s", layover.DepartureAirport)
+	}
+	if layover.DurationMinutes < layover.MinimumConnectionMinutes {
+		label += fmt.Sprintf(" (short connection, %s minimum)", formatMinutes(layover.MinimumConnectionMinutes))
+	}
+	return label
+}
+
+// formatMinutes prints a duration such as "2h 05m" or "45m"
+func formatMinutes(minutes int) string {
+	sign := ""
+	if minutes < 0 {
+		sign, minutes = "-", -minutes
+	}
+	if minutes < 60 {
+		return fmt.Sprintf("%s%dm", sign, minutes)
+	}
+	return fmt.Sprintf("%s%dh %02dm", sign, minutes/60, minutes%60)
+}
@@ -0,0 +1,340 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+
+	"vigovia-task/models"
+)
+
+// HTMLRenderer renders itineraries as a single self-contained HTML page
+type HTMLRenderer struct {
+	tmpl *template.Template
+}
+
+// NewHTMLRenderer creates a new instance of HTMLRenderer
+func NewHTMLRenderer() *HTMLRenderer {
+	funcs := template.FuncMap{
+		"formatDate":         formatDate,
+		"formatDateTime":     formatDateTime,
+		"formatAmount":       formatAmount,
+		"toTitleCase":        toTitleCase,
+		"nightsLabel":        nightsLabel,
+		"flightTitle":        flightTitle,
+		"mealPlanLabel":      mealPlanLabel,
+		"groupFlights":       groupFlights,
+		"journeyTitle":       journeyTitle,
+		"layoverLabel":       layoverLabel,
+		"cabinLabel":         cabinLabel,
+		"airportLabel":       airportLabel,
+		"transferTimeLabel":  transferTimeLabel,
+		"vehicleLabel":       vehicleLabel,
+		"contactLabel":       contactLabel,
+		"roomLabel":          roomLabel,
+		"formatGeoPoint":     func(point *models.GeoPoint) string { return formatGeoPoint(*point) },
+		"mapURL":             func(point *models.GeoPoint) string { return mapURL(*point) },
+		"groupActivities":    groupActivitiesByPeriod,
+		"sortedInstallments": sortedInstallments,
+		"installmentStatus":  installmentStatus,
+		"add":                func(a, b int) int { return a + b },
+		"operationsCopy":     func() string { return operationsCopyLabel },
+	}
+
+	return &HTMLRenderer{
+		tmpl: template.Must(template.New("itinerary").Funcs(funcs).Parse(htmlTemplate)),
+	}
+}
+
+// htmlView is the data passed to the HTML template
+type htmlView struct {
+	*models.Itinerary
+	Theme    *models.BrandingProfile
+	Layout   PageLayout
+	LogoURI  template.URL
+	Internal bool
+}
+
+// Render implements Renderer for HTML output
+func (hr *HTMLRenderer) Render(itinerary *models.Itinerary, opts RenderOptions) ([]byte, error) {
+	view := htmlView{Itinerary: opts.content(itinerary), Theme: opts.theme(), Layout: opts.layout(), Internal: opts.Internal}
+	if len(view.Theme.Logo) > 0 {
+		view.LogoURI = template.URL(fmt.Sprintf("data:image/%s;base64,%s", view.Theme.LogoFormat, base64.StdEncoding.EncodeToString(view.Theme.Logo)))
+	}
+
+	buf := new(bytes.Buffer)
+	if err := hr.tmpl.Execute(buf, view); err != nil {
+		return nil, fmt.Errorf("failed to generate HTML: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ContentType implements Renderer
+func (hr *HTMLRenderer) ContentType() string {
+	return "text/html; charset=utf-8"
+}
+
+// FileExtension implements Renderer
+func (hr *HTMLRenderer) FileExtension() string {
+	return ".html"
+}
+
+// htmlTemplate mirrors the sections produced by PDFService.GeneratePDF. The
+// stylesheet is inlined so the exported file renders without network access.
+const htmlTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}} - Itinerary Plan</title>
+<style>
+  :root { --primary: {{.Theme.Colors.Primary}}; --accent: {{.Theme.Colors.Accent}}; --heading: {{.Theme.Colors.Heading}}; --divider: {{.Theme.Colors.Divider}}; }
+  * { box-sizing: border-box; }
+  body { margin: 0; font-family: Arial, Helvetica, sans-serif; color: #222; background: #f4f6f8; line-height: 1.5; }
+  .page { max-width: 820px; margin: 24px auto; padding: 32px 40px; background: #fff; box-shadow: 0 1px 4px rgba(0,0,0,.08); }
+  header { display: flex; justify-content: space-between; align-items: flex-start; gap: 16px; }
+  header img { max-height: 56px; max-width: 180px; }
+  header h1 { margin: 0; font-size: 26px; color: var(--heading); }
+  header p { margin: 4px 0 0; color: #5a5a5a; font-size: 16px; }
+  h2 { color: var(--primary); font-size: 17px; margin: 28px 0 10px; padding-bottom: 6px; border-bottom: 1px solid var(--divider); }
+  h3 { font-size: 15px; margin: 14px 0 4px; color: #282828; }
+  h4 { font-size: 14px; margin: 10px 0 4px; color: #464646; }
+  dl { display: grid; grid-template-columns: max-content 1fr; gap: 2px 16px; margin: 0; font-size: 14px; }
+  dt { font-weight: bold; color: #464646; }
+  dd { margin: 0; color: #232323; }
+  .muted { color: #6e6e6e; font-size: 13px; }
+  .day { padding: 8px 0 16px; border-bottom: 1px solid #dcdcdc; }
+  .day h3 { color: var(--primary); }
+  .activity { margin: 6px 0 10px 16px; font-size: 14px; }
+  .activity .time { color: var(--accent); font-weight: bold; display: inline-block; min-width: 90px; }
+  .activity p { margin: 2px 0 0 16px; color: #5a5a5a; }
+  table { width: 100%; border-collapse: collapse; font-size: 14px; }
+  th, td { text-align: left; padding: 6px 8px; border-bottom: 1px solid #e5e5e5; }
+  th { color: #3c3c3c; }
+  ul { margin: 4px 0 10px; padding-left: 22px; font-size: 14px; }
+  footer { margin-top: 32px; padding-top: 8px; border-top: 1px solid #dcdcdc; color: #969696; font-size: 12px; }
+  .terms { white-space: pre-line; font-size: 13px; color: #323232; }
+  .internal { color: #8a4b00; font-size: 13px; }
+  .operations-copy { margin: 0 0 16px; padding: 8px 12px; border: 1px solid #e6a23c; background: #fff4e0; color: #8a4b00; font-weight: bold; }
+  @media (max-width: 600px) {
+    .page { margin: 0; padding: 20px 16px; box-shadow: none; }
+    dl { grid-template-columns: 1fr; }
+    dt { margin-top: 6px; }
+    th, td { padding: 4px; }
+  }
+  @page { size: {{.Layout.PageSize}} {{.Layout.Orientation}}; margin: {{.Layout.Margin}}mm; }
+  @media print {
+    body { background: #fff; }
+    .page { margin: 0; padding: 0; max-width: none; box-shadow: none; }
+    h2, h3 { page-break-after: avoid; break-after: avoid; }
+    .day, .activity, tr { page-break-inside: avoid; break-inside: avoid; }
+    .terms-page { page-break-before: always; break-before: page; }
+  }
+</style>
+</head>
+<body>
+<div class="page">
+{{if .Internal}}<p class="operations-copy">{{operationsCopy}}</p>{{end}}
+<header>
+  <div>
+    <h1>Itinerary Plan</h1>
+    <p>{{.Title}}</p>
+  </div>
+  {{if .LogoURI}}<img src="{{.LogoURI}}" alt="{{.Theme.Name}}">{{end}}
+</header>
+
+<section>
+  <h2>Trip Information</h2>
+  <dl>
+    {{if .UserID}}<dt>User ID</dt><dd>{{.UserID}}</dd>{{end}}
+    {{if .Location}}<dt>Location</dt><dd>{{.Location}}</dd>{{end}}
+    <dt>Start Date</dt><dd>{{formatDate .StartDate}}</dd>
+    <dt>End Date</dt><dd>{{formatDate .EndDate}}</dd>
+    <dt>Duration</dt><dd>{{len .Days}} Days</dd>
+  </dl>
+</section>
+
+{{if .InternalNotes}}
+<section>
+  <h2>Internal Notes</h2>
+  <p class="internal">{{.InternalNotes}}</p>
+</section>
+{{end}}
+
+{{if .Hotels}}
+<section>
+  <h2>Hotel Accommodations</h2>
+  {{range $idx, $hotel := .Hotels}}
+  <h3>Hotel {{add $idx 1}}: {{$hotel.Name}}</h3>
+  <dl>
+    {{if $hotel.City}}<dt>Location</dt><dd>{{$hotel.City}}</dd>{{end}}
+    {{if $hotel.Address}}<dt>Address</dt><dd>{{$hotel.Address}}</dd>{{end}}
+    {{if $hotel.Phone}}<dt>Phone</dt><dd>{{$hotel.Phone}}</dd>{{end}}
+    {{with $hotel.Coordinates}}<dt>Map</dt><dd><a href="{{mapURL .}}">{{formatGeoPoint .}}</a></dd>{{end}}
+    {{if not $hotel.CheckIn.IsZero}}<dt>Check-in</dt><dd>{{formatDate $hotel.CheckIn}}</dd>{{end}}
+    {{if not $hotel.CheckOut.IsZero}}<dt>Check-out</dt><dd>{{formatDate $hotel.CheckOut}}</dd>{{end}}
+    {{if gt $hotel.Nights 0}}<dt>Duration</dt><dd>{{nightsLabel $hotel.Nights}}</dd>{{end}}
+    {{if $hotel.MealPlan}}<dt>Meal Plan</dt><dd>{{mealPlanLabel $hotel.MealPlan}}</dd>{{end}}
+    {{if $hotel.ConfirmationNumber}}<dt>Confirmation</dt><dd>{{$hotel.ConfirmationNumber}}</dd>{{end}}
+    {{range $hotel.Rooms}}<dt>Room</dt><dd>{{roomLabel .}}</dd>{{end}}
+    {{if $hotel.InternalNotes}}<dt>Internal</dt><dd class="internal">{{$hotel.InternalNotes}}</dd>{{end}}
+  </dl>
+  {{end}}
+</section>
+{{end}}
+
+{{if .Flights}}
+<section>
+  <h2>Flight Details</h2>
+  {{range $group := groupFlights .Flights}}
+  {{with $group.Journey}}<h3>{{journeyTitle . $.Flights}}</h3>{{end}}
+  {{range $leg := $group.Legs}}
+  {{with $leg.Layover}}<p class="muted"><em>{{layoverLabel .}}</em></p>{{end}}
+  {{$flight := $leg.Flight}}
+  <h3>{{flightTitle $leg.Index $flight}}</h3>
+  {{if or $flight.BookingReference $flight.CabinClass $flight.Seat $flight.BaggageAllowance $flight.InternalNotes}}
+  <dl>
+    {{if $flight.BookingReference}}<dt>Booking</dt><dd>{{$flight.BookingReference}}</dd>{{end}}
+    {{with cabinLabel $flight}}<dt>Cabin</dt><dd>{{.}}</dd>{{end}}
+    {{if $flight.BaggageAllowance}}<dt>Baggage</dt><dd>{{$flight.BaggageAllowance}}</dd>{{end}}
+    {{if $flight.InternalNotes}}<dt>Internal</dt><dd class="internal">{{$flight.InternalNotes}}</dd>{{end}}
+  </dl>
+  {{end}}
+  <h4>Departure</h4>
+  <dl>
+    {{if $flight.DepartureCity}}<dt>City</dt><dd>{{$flight.DepartureCity}}</dd>{{end}}
+    {{if $flight.DepartureAirport}}<dt>Airport</dt><dd>{{airportLabel $flight.DepartureAirport $flight.DepartureTerminal}}</dd>{{end}}
+    {{if not $flight.DepartureTime.IsZero}}<dt>Time</dt><dd>{{formatDateTime $flight.DepartureTime}}</dd>{{end}}
+  </dl>
+  <h4>Arrival</h4>
+  <dl>
+    {{if $flight.ArrivalCity}}<dt>City</dt><dd>{{$flight.ArrivalCity}}</dd>{{end}}
+    {{if $flight.ArrivalAirport}}<dt>Airport</dt><dd>{{airportLabel $flight.ArrivalAirport $flight.ArrivalTerminal}}</dd>{{end}}
+    {{if not $flight.ArrivalTime.IsZero}}<dt>Time</dt><dd>{{formatDateTime $flight.ArrivalTime}}</dd>{{end}}
+  </dl>
+  {{end}}
+  {{end}}
+</section>
+{{end}}
+
+{{if .Transfers}}
+<section>
+  <h2>Transfers</h2>
+  {{range .Transfers}}
+  <h3>{{toTitleCase .Mode}} Transfer</h3>
+  <p class="muted">Pickup: {{.Pickup}} &nbsp; Drop-off: {{.Dropoff}} &nbsp; Time: {{transferTimeLabel .}}</p>
+  {{with vehicleLabel .}}<p class="muted">Vehicle: {{.}}</p>{{end}}
+  {{with contactLabel .Vendor}}<p class="muted">Vendor: {{.}}</p>{{end}}
+  {{with contactLabel .Driver}}<p class="muted">Driver: {{.}}</p>{{end}}
+  {{if gt .Price 0.0}}<p class="muted">Price: {{formatAmount .Price .Currency}}</p>{{end}}
+  {{if .Notes}}<p class="muted">Notes: {{.Notes}}</p>{{end}}
+  {{if .InternalNotes}}<p class="internal">Internal: {{.InternalNotes}}</p>{{end}}
+  {{end}}
+</section>
+{{end}}
+
+{{if .Description}}
+<section>
+  <h2>Overview</h2>
+  <p>{{.Description}}</p>
+</section>
+{{end}}
+
+<section>
+  <h2>Day-by-Day Itinerary</h2>
+  {{range .Days}}
+  <div class="day">
+    <h3>Day {{.DayNumber}}: {{.Title}}</h3>
+    <div class="muted">Date: {{formatDate .Date}}</div>
+    {{if .InternalNotes}}<p class="internal">Internal: {{.InternalNotes}}</p>{{end}}
+    {{range groupActivities .Activities}}
+    <h4>{{.Label}} Session</h4>
+    {{range .Activities}}
+    <div class="activity">
+      <span class="time">{{.Time}}</span> <strong>{{.Title}}</strong>
+      {{if .Description}}<p>Description: {{.Description}}</p>{{end}}
+      {{if .Location}}<p>Location: {{.Location}}</p>{{end}}
+      {{if .Duration}}<p>Duration: {{.Duration}}</p>{{end}}
+      {{if .InternalNotes}}<p class="internal">Internal: {{.InternalNotes}}</p>{{end}}
+    </div>
+    {{end}}
+    {{else}}
+    <p class="muted">No activities planned for this day</p>
+    {{end}}
+  </div>
+  {{else}}
+  <p class="muted">No days planned yet</p>
+  {{end}}
+</section>
+
+{{if .PaymentPlan}}
+<section>
+  <h2>Payment Plan</h2>
+  <table>
+    <thead><tr><th>Installment</th><th>Amount</th><th>Due Date</th><th>Status</th></tr></thead>
+    <tbody>
+    {{range sortedInstallments .PaymentPlan}}
+      <tr><td>#{{.InstallmentNumber}}</td><td>{{formatAmount .Amount .Currency}}</td><td>{{formatDate .DueDate}}</td><td>{{installmentStatus .}}</td></tr>
+      {{if .InternalNotes}}<tr><td></td><td colspan="3" class="internal">Internal: {{.InternalNotes}}</td></tr>{{end}}
+    {{end}}
+    </tbody>
+  </table>
+</section>
+{{end}}
+
+{{if or .Inclusions .Exclusions}}
+<section>
+  <h2>Inclusions and Exclusions</h2>
+  {{if .Inclusions}}
+  <h4>Inclusions</h4>
+  <ul>{{range .Inclusions}}<li>{{.}}</li>{{end}}</ul>
+  {{end}}
+  {{if .Exclusions}}
+  <h4>Exclusions</h4>
+  <ul>{{range .Exclusions}}<li>{{.}}</li>{{end}}</ul>
+  {{end}}
+</section>
+{{end}}
+
+{{if .Travellers}}
+<section>
+  <h2>Passenger Manifest</h2>
+  <table>
+    <thead><tr><th>No.</th><th>Name</th><th>Date of Birth</th><th>Nationality</th><th>Passport</th><th>Expiry</th></tr></thead>
+    <tbody>
+    {{range $idx, $traveller := .Travellers}}
+      <tr><td>{{add $idx 1}}</td><td>{{$traveller.FullName}}</td><td>{{formatDate $traveller.DateOfBirth}}</td><td>{{$traveller.Nationality}}</td><td>{{$traveller.PassportNumber}}</td><td>{{formatDate $traveller.PassportExpiry}}</td></tr>
+    {{end}}
+    </tbody>
+  </table>
+  {{range .Travellers}}{{if or .DietaryRequirements .AccessibilityNeeds}}
+  <p class="muted"><strong>{{.FullName}}</strong>{{if .DietaryRequirements}} &middot; Dietary: {{.DietaryRequirements}}{{end}}{{if .AccessibilityNeeds}} &middot; Accessibility: {{.AccessibilityNeeds}}{{end}}</p>
+  {{end}}{{end}}
+</section>
+{{end}}
+
+{{with .Theme.Contact}}{{if not .IsEmpty}}
+<section>
+  <h2>Contact Us</h2>
+  <dl>
+    {{if .Phone}}<dt>Phone</dt><dd>{{.Phone}}</dd>{{end}}
+    {{if .Email}}<dt>Email</dt><dd>{{.Email}}</dd>{{end}}
+    {{if .Website}}<dt>Website</dt><dd>{{.Website}}</dd>{{end}}
+    {{if .Address}}<dt>Address</dt><dd>{{.Address}}</dd>{{end}}
+  </dl>
+</section>
+{{end}}{{end}}
+
+{{if .Theme.TermsAndConditions}}
+<section class="terms-page">
+  <h2>Terms and Conditions</h2>
+  <p class="terms">{{.Theme.TermsAndConditions}}</p>
+</section>
+{{end}}
+
+<footer>{{.Theme.FooterText}}</footer>
+</div>
+</body>
+</html>
+`
@@ -0,0 +1,362 @@
+package services
+
+import (
+	"fmt"
+
+	"vigovia-task/models"
+	"vigovia-task/utils"
+)
+
+// GetHotel finds a hotel by its ID
+func (is *ItineraryService) GetHotel(itineraryID, hotelID string) (*models.Hotel, error) {
+	itinerary, err := is.store.GetByID(itineraryID)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := componentIndex("hotel", hotelIDs(itinerary.Hotels), hotelID)
+	if err != nil {
+		return nil, err
+	}
+	hotel := itinerary.Hotels[idx]
+	return &hotel, nil
+}
+
+// AddHotel adds a hotel with a new ID
+func (is *ItineraryService) AddHotel(itineraryID string, hotel *models.Hotel) (*models.Itinerary, error) {
+	return is.editComponents(itineraryID, func(itinerary *models.Itinerary) (*models.UpdateItineraryRequest, error) {
+		added := *hotel
+		added.ID = ""
+		hotels := append(append([]models.Hotel(nil), itinerary.Hotels...), added)
+		return &models.UpdateItineraryRequest{Hotels: hotels}, nil
+	})
+}
+
+// UpdateHotel replaces a hotel, keeping its ID
+func (is *ItineraryService) UpdateHotel(itineraryID, hotelID string, hotel *models.Hotel) (*models.Itinerary, error) {
+	return is.editComponents(itineraryID, func(itinerary *models.Itinerary) (*models.UpdateItineraryRequest, error) {
+		idx, err := componentIndex("hotel", hotelIDs(itinerary.Hotels), hotelID)
+		if err != nil {
+			return nil, err
+		}
+		hotels := append([]models.Hotel(nil), itinerary.Hotels...)
+		hotels[idx] = *hotel
+		hotels[idx].ID = hotelID
+		return &models.UpdateItineraryRequest{Hotels: hotels}, nil
+	})
+}
+
+// DeleteHotel removes a hotel
+func (is *ItineraryService) DeleteHotel(itineraryID, hotelID string) (*models.Itinerary, error) {
+	return is.editComponents(itineraryID, func(itinerary *models.Itinerary) (*models.UpdateItineraryRequest, error) {
+		idx, err := componentIndex("hotel", hotelIDs(itinerary.Hotels), hotelID)
+		if err != nil {
+			return nil, err
+		}
+		hotels := append(append([]models.Hotel{}, itinerary.Hotels[:idx]...), itinerary.Hotels[idx+1:]...)
+		return &models.UpdateItineraryRequest{Hotels: hotels}, nil
+	})
+}
+
+// GetFlight finds a flight by its ID
+func (is *ItineraryService) GetFlight(itineraryID, flightID string) (*models.Flight, error) {
+	itinerary, err := is.store.GetByID(itineraryID)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := componentIndex("flight", flightIDs(itinerary.Flights), flightID)
+	if err != nil {
+		return nil, err
+	}
+	flight := itinerary.Flights[idx]
+	return &flight, nil
+}
+
+// AddFlight adds a flight with a new ID
+func (is *ItineraryService) AddFlight(itineraryID string, flight *models.Flight) (*models.Itinerary, error) {
+	return is.editComponents(itineraryID, func(itinerary *models.Itinerary) (*models.UpdateItineraryRequest, error) {
+		added := *flight
+		added.ID = ""
+		flights := append(append([]models.Flight(nil), itinerary.Flights...), added)
+		return &models.UpdateItineraryRequest{Flights: flights}, nil
+	})
+}
+
+// UpdateFlight replaces a flight, keeping its ID
+func (is *ItineraryService) UpdateFlight(itineraryID, flightID string, flight *models.Flight) (*models.Itinerary, error) {
+	return is.editComponents(itineraryID, func(itinerary *models.Itinerary) (*models.UpdateItineraryRequest, error) {
+		idx, err := componentIndex("flight", flightIDs(itinerary.Flights), flightID)
+		if err != nil {
+			return nil, err
+		}
+		flights := append([]models.Flight(nil), itinerary.Flights...)
+		flights[idx] = *flight
+		flights[idx].ID = flightID
+		return &models.UpdateItineraryRequest{Flights: flights}, nil
+	})
+}
+
+// DeleteFlight removes a flight
+func (is *ItineraryService) DeleteFlight(itineraryID, flightID string) (*models.Itinerary, error) {
+	return is.editComponents(itineraryID, func(itinerary *models.Itinerary) (*models.UpdateItineraryRequest, error) {
+		idx, err := componentIndex("flight", flightIDs(itinerary.Flights), flightID)
+		if err != nil {
+			return nil, err
+		}
+		flights := append(append([]models.Flight{}, itinerary.Flights[:idx]...), itinerary.Flights[idx+1:]...)
+		return &models.UpdateItineraryRequest{Flights: flights}, nil
+	})
+}
+
+// GetTransfer finds a transfer by its ID
+func (is *ItineraryService) GetTransfer(itineraryID, transferID string) (*models.Transfer, error) {
+	itinerary, err := is.store.GetByID(itineraryID)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := componentIndex("transfer", transferIDs(itinerary.Transfers), transferID)
+	if err != nil {
+		return nil, err
+	}
+	transfer := itinerary.Transfers[idx]
+	return &transfer, nil
+}
+
+// AddTransfer adds a transfer with a new ID
+func (is *ItineraryService) AddTransfer(itineraryID string, transfer *models.Transfer) (*models.Itinerary, error) {
+	return is.editComponents(itineraryID, func(itinerary *models.Itinerary) (*models.UpdateItineraryRequest, error) {
+		added := *transfer
+		added.ID = ""
+		transfers := append(append([]models.Transfer(nil), itinerary.Transfers...), added)
+		return &models.UpdateItineraryRequest{Transfers: transfers}, nil
+	})
+}
+
+// UpdateTransfer replaces a transfer, keeping its ID
+func (is *ItineraryService) UpdateTransfer(itineraryID, transferID string, transfer *models.Transfer) (*models.Itinerary, error) {
+	return is.editComponents(itineraryID, func(itinerary *models.Itinerary) (*models.UpdateItineraryRequest, error) {
+		idx, err := componentIndex("transfer", transferIDs(itinerary.Transfers), transferID)
+		if err != nil {
+			return nil, err
+		}
+		transfers := append([]models.Transfer(nil), itinerary.Transfers...)
+		transfers[idx] = *transfer
+		transfers[idx].ID = transferID
+		return &models.UpdateItineraryRequest{Transfers: transfers}, nil
+	})
+}
+
+// DeleteTransfer removes a transfer
+func (is *ItineraryService) DeleteTransfer(itineraryID, transferID string) (*models.Itinerary, error) {
+	return is.editComponents(itineraryID, func(itinerary *models.Itinerary) (*models.UpdateItineraryRequest, error) {
+		idx, err := componentIndex("transfer", transferIDs(itinerary.Transfers), transferID)
+		if err != nil {
+			return nil, err
+		}
+		transfers := append(append([]models.Transfer{}, itinerary.Transfers[:idx]...), itinerary.Transfers[idx+1:]...)
+		return &models.UpdateItineraryRequest{Transfers: transfers}, nil
+	})
+}
+
+// GetPaymentInstallment finds a payment installment by its ID
+func (is *ItineraryService) GetPaymentInstallment(itineraryID, installmentID string) (*models.PaymentInstallment, error) {
+	itinerary, err := is.store.GetByID(itineraryID)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := componentIndex("payment installment", installmentIDs(itinerary.PaymentPlan), installmentID)
+	if err != nil {
+		return nil, err
+	}
+	installment := itinerary.PaymentPlan[idx]
+	return &installment, nil
+}
+
+// AddPaymentInstallment adds a payment installment with a new ID
+func (is *ItineraryService) AddPaymentInstallment(itineraryID string, installment *models.PaymentInstallment) (*models.Itinerary, error) {
+	return is.editComponents(itineraryID, func(itinerary *models.Itinerary) (*models.UpdateItineraryRequest, error) {
+		added := *installment
+		added.ID = ""
+		plan := append(append([]models.PaymentInstallment(nil), itinerary.PaymentPlan...), added)
+		return &models.UpdateItineraryRequest{PaymentPlan: plan}, nil
+	})
+}
+
+// UpdatePaymentInstallment replaces a payment installment, keeping its ID
+func (is *ItineraryService) UpdatePaymentInstallment(itineraryID, installmentID string, installment *models.PaymentInstallment) (*models.Itinerary, error) {
+	return is.editComponents(itineraryID, func(itinerary *models.Itinerary) (*models.UpdateItineraryRequest, error) {
+		idx, err := componentIndex("payment installment", installmentIDs(itinerary.PaymentPlan), installmentID)
+		if err != nil {
+			return nil, err
+		}
+		plan := append([]models.PaymentInstallment(nil), itinerary.PaymentPlan...)
+		plan[idx] = *installment
+		plan[idx].ID = installmentID
+		return &models.UpdateItineraryRequest{PaymentPlan: plan}, nil
+	})
+}
+
+// DeletePaymentInstallment removes a payment installment
+func (is *ItineraryService) DeletePaymentInstallment(itineraryID, installmentID string) (*models.Itinerary, error) {
+	return is.editComponents(itineraryID, func(itinerary *models.Itinerary) (*models.UpdateItineraryRequest, error) {
+		idx, err := componentIndex("payment installment", installmentIDs(itinerary.PaymentPlan), installmentID)
+		if err != nil {
+			return nil, err
+		}
+		plan := append(append([]models.PaymentInstallment{}, itinerary.PaymentPlan[:idx]...), itinerary.PaymentPlan[idx+1:]...)
+		return &models.UpdateItineraryRequest{PaymentPlan: plan}, nil
+	})
+}
+
+// editComponents builds an update from the stored itinerary and applies it
+// like a full update, so a changed hotel or flight is checked against the
+// rest of the itinerary
+func (is *ItineraryService) editComponents(itineraryID string, build func(itinerary *models.Itinerary) (*models.UpdateItineraryRequest, error)) (*models.Itinerary, error) {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	itinerary, err := is.store.GetByID(itineraryID)
+	if err != nil {
+		return nil, err
+	}
+	req, err := build(itinerary)
+	if err != nil {
+		return nil, err
+	}
+	return is.applyUpdate(itinerary, req)
+}
+
+// checkUpdateIDs makes sure every component ID sent in an update belongs to
+// the itinerary. Components sent without an ID are new.
+func checkUpdateIDs(itinerary *models.Itinerary, req *models.UpdateItineraryRequest) error {
+	if req.Hotels != nil {
+		if err := checkComponentIDs("hotel", hotelIDs(req.Hotels), hotelIDs(itinerary.Hotels)); err != nil {
+			return err
+		}
+	}
+	if req.Flights != nil {
+		if err := checkComponentIDs("flight", flightIDs(req.Flights), flightIDs(itinerary.Flights)); err != nil {
+			return err
+		}
+	}
+	if req.Transfers != nil {
+		if err := checkComponentIDs("transfer", transferIDs(req.Transfers), transferIDs(itinerary.Transfers)); err != nil {
+			return err
+		}
+	}
+	if req.Days != nil {
+		if err := checkComponentIDs("day", dayIDs(req.Days), dayIDs(itinerary.Days)); err != nil {
+			return err
+		}
+		if err := checkComponentIDs("activity", activityIDs(req.Days), activityIDs(itinerary.Days)); err != nil {
+			return err
+		}
+	}
+	if req.PaymentPlan != nil {
+		if err := checkComponentIDs("payment installment", installmentIDs(req.PaymentPlan), installmentIDs(itinerary.PaymentPlan)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkComponentIDs rejects sent IDs that are unknown or repeated. Empty IDs
+// are skipped.
+func checkComponentIDs(kind string, sent, current []string) error {
+	known := make(map[string]bool, len(current))
+	for _, id := range current {
+		known[id] = true
+	}
+	seen := make(map[string]bool, len(sent))
+	for _, id := range sent {
+		if id == "" {
+			continue
+		}
+		if !known[id] {
+			return utils.NewValidationError(fmt.Sprintf("%s %s not found in itinerary", kind, id))
+		}
+		if seen[id] {
+			return utils.NewValidationError(fmt.Sprintf("%s id %s is used more than once", kind, id))
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// clearComponentIDs drops the component IDs of a new itinerary's content
+func clearComponentIDs(req *models.CreateItineraryRequest) {
+	for i := range req.Hotels {
+		req.Hotels[i].ID = ""
+	}
+	for i := range req.Flights {
+		req.Flights[i].ID = ""
+	}
+	for i := range req.Transfers {
+		req.Transfers[i].ID = ""
+	}
+	for i := range req.Days {
+		req.Days[i].ID = ""
+		req.Days[i].Activities = newActivities(req.Days[i].Activities)
+	}
+	for i := range req.PaymentPlan {
+		req.PaymentPlan[i].ID = ""
+	}
+}
+
+func componentIndex(kind string, ids []string, id string) (int, error) {
+	for i, candidate := range ids {
+		if candidate == id {
+			return i, nil
+		}
+	}
+	return 0, utils.NewValidationError(fmt.Sprintf("%s %s not found in itinerary", kind, id))
+}
+
+func hotelIDs(hotels []models.Hotel) []string {
+	ids := make([]string, len(hotels))
+	for i, hotel := range hotels {
+		ids[i] = hotel.ID
+	}
+	return ids
+}
+
+func flightIDs(flights []models.Flight) []string {
+	ids := make([]string, len(flights))
+	for i, flight := range flights {
+		ids[i] = flight.ID
+	}
+	return ids
+}
+
+func transferIDs(transfers []models.Transfer) []string {
+	ids := make([]string, len(transfers))
+	for i, transfer := range transfers {
+		ids[i] = transfer.ID
+	}
+	return ids
+}
+
+func installmentIDs(plan []models.PaymentInstallment) []string {
+	ids := make([]string, len(plan))
+	for i, installment := range plan {
+		ids[i] = installment.ID
+	}
+	return ids
+}
+
+func dayIDs(days []models.DayPlan) []string {
+	ids := make([]string, len(days))
+	for i, day := range days {
+		ids[i] = day.ID
+	}
+	return ids
+}
+
+func activityIDs(days []models.DayPlan) []string {
+	var ids []string
+	for _, day := range days {
+		for _, activity := range day.Activities {
+			ids = append(ids, activity.ID)
+		}
+	}
+	return ids
+}
@@ -1,7 +1,10 @@
 package services
 
 import (
+	"fmt"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"vigovia-task/models"
@@ -11,50 +14,92 @@ import (
 
 // ItineraryService handles business logic for itineraries
 type ItineraryService struct {
-	store *storage.MemoryStore
+	store     *storage.MemoryStore
+	checks    *TravelCheckService
+	listeners []func(itineraryID string)
+	// mu serialises updates and day plan edits so each applies to the latest
+	// stored itinerary
+	mu sync.Mutex
 }
 
 // NewItineraryService creates a new instance of ItineraryService
-func NewItineraryService(store *storage.MemoryStore) *ItineraryService {
+func NewItineraryService(store *storage.MemoryStore, checks *TravelCheckService) *ItineraryService {
 	return &ItineraryService{
-		store: store,
+		store:  store,
+		checks: checks,
+	}
+}
+
+// OnChange registers fn to be called whenever an itinerary is modified or deleted
+func (is *ItineraryService) OnChange(fn func(itineraryID string)) {
+	is.listeners = append(is.listeners, fn)
+}
+
+func (is *ItineraryService) notifyChange(itineraryID string) {
+	for _, fn := range is.listeners {
+		fn(itineraryID)
 	}
 }
 
 // CreateItinerary creates a new itinerary
 func (is *ItineraryService) CreateItinerary(req *models.CreateItineraryRequest) (*models.Itinerary, error) {
+	// A new itinerary's components always get new IDs, even when the content
+	// was copied from another itinerary
+	clearComponentIDs(req)
+	organization := is.ownerOrganization(req.UserID)
+	if organization != nil {
+		applyOrganizationDefaults(req, &organization.Settings)
+	}
+	req.Travellers = normalizeTravellers(req.Travellers)
+	req.Hotels = normalizeHotels(req.Hotels)
+	req.Flights = normalizeFlights(req.Flights)
+	req.Transfers = normalizeTransfers(req.Transfers, req.Flights, req.Hotels)
+	req.Days = normalizeDays(req.Days)
+	req.PaymentPlan = normalizePaymentPlan(req.PaymentPlan)
+	req.Destination = strings.ToUpper(strings.TrimSpace(req.Destination))
+	organizationID := ""
+	if organization != nil {
+		organizationID = organization.ID
+	}
+	if err := is.applyCatalogue(organizationID, req.UserID, req.Days); err != nil {
+		return nil, err
+	}
+
 	// Validate the request
 	if err := utils.ValidateItinerary(req); err != nil {
 		return nil, err
 	}
+	if err := utils.ValidateTravellerAssignments(req.Travellers, req.Hotels, req.Flights); err != nil {
+		return nil, err
+	}
 
 	now := time.Now()
 	itinerary := &models.Itinerary{
-		ID:          utils.GenerateID(),
-		UserID:      strings.TrimSpace(req.UserID),
-		Title:       req.Title,
-		Description: req.Description,
-		StartDate:   req.StartDate,
-		EndDate:     req.EndDate,
-		Location:    req.Location,
-		Hotels:      req.Hotels,
-		Flights:     req.Flights,
-		Transfers:   req.Transfers,
-		Days:        req.Days,
-		PaymentPlan: req.PaymentPlan,
-		Inclusions:  req.Inclusions,
-		Exclusions:  req.Exclusions,
-		CreatedAt:   now,
-		UpdatedAt:   now,
-	}
-
-	// Normalise activity periods immediately for consistent downstream usage.
-	for i := range itinerary.Days {
-		for j := range itinerary.Days[i].Activities {
-			itinerary.Days[i].Activities[j] = normalizeActivity(itinerary.Days[i].Activities[j])
-		}
+		ID:            utils.GenerateID(),
+		UserID:        strings.TrimSpace(req.UserID),
+		Title:         req.Title,
+		Description:   req.Description,
+		StartDate:     req.StartDate,
+		EndDate:       req.EndDate,
+		Location:      req.Location,
+		Destination:   req.Destination,
+		Hotels:        req.Hotels,
+		Flights:       req.Flights,
+		Transfers:     req.Transfers,
+		Days:          req.Days,
+		PaymentPlan:   req.PaymentPlan,
+		Inclusions:    req.Inclusions,
+		Exclusions:    req.Exclusions,
+		Travellers:    req.Travellers,
+		InternalNotes: strings.TrimSpace(req.InternalNotes),
+		Revision:      1,
+		CreatedAt:     now,
+		UpdatedAt:     now,
 	}
 
+	itinerary.OrganizationID = organizationID
+	is.refreshDerived(itinerary)
+
 	if err := is.store.Create(itinerary); err != nil {
 		return nil, err
 	}
@@ -62,42 +107,238 @@ func (is *ItineraryService) CreateItinerary(req *models.CreateItineraryRequest)
 	return itinerary, nil
 }
 
+// MoveToOrganization brings a user's personal itineraries into the
+// organization they have joined
+func (is *ItineraryService) MoveToOrganization(userID, organizationID string) error {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	for _, itinerary := range is.store.GetByOrganization("") {
+		if itinerary.UserID != userID {
+			continue
+		}
+		moved := *itinerary
+		moved.OrganizationID = organizationID
+		if err := is.store.Update(moved.ID, &moved); err != nil {
+			return err
+		}
+		is.notifyChange(moved.ID)
+	}
+	return nil
+}
+
+// SetApprovalStatus sets an itinerary's approval status, failing when the
+// itinerary has moved on from the given revision. The status belongs to the
+// revision, so setting it keeps the revision.
+func (is *ItineraryService) SetApprovalStatus(id string, revision int, status string) error {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	itinerary, err := is.store.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if itinerary.Revision != revision {
+		return utils.NewValidationError(fmt.Sprintf("the itinerary has changed since revision %d; request approval of revision %d instead", revision, itinerary.Revision))
+	}
+
+	updated := *itinerary
+	updated.ApprovalStatus = status
+	return is.save(&updated, false)
+}
+
+// save stores an edited copy of an itinerary and drops the documents
+// rendered from the old one. A content edit starts a new revision, which
+// has not been sent for approval: the approval status is cleared and a
+// request still waiting for an answer is superseded.
+func (is *ItineraryService) save(updated *models.Itinerary, newRevision bool) error {
+	if newRevision {
+		updated.Revision++
+		updated.ApprovalStatus = ""
+	}
+	updated.UpdatedAt = time.Now()
+	if err := is.store.Update(updated.ID, updated); err != nil {
+		return err
+	}
+	if newRevision {
+		if err := supersedePendingApprovals(is.store, updated.ID); err != nil {
+			return err
+		}
+	}
+	is.notifyChange(updated.ID)
+	return nil
+}
+
+// ownerOrganization returns the organization of the user creating an
+// itinerary, or nil when they belong to none
+func (is *ItineraryService) ownerOrganization(userID string) *models.Organization {
+	user, err := is.store.GetUserByID(strings.TrimSpace(userID))
+	if err != nil || user.OrganizationID == "" {
+		return nil
+	}
+	organization, err := is.store.GetOrganization(user.OrganizationID)
+	if err != nil {
+		return nil
+	}
+	return organization
+}
+
+// applyOrganizationDefaults fills in what a new itinerary leaves out from
+// its organization's settings
+func applyOrganizationDefaults(req *models.CreateItineraryRequest, settings *models.OrganizationSettings) {
+	if len(req.Inclusions) == 0 {
+		req.Inclusions = append([]string(nil), settings.Inclusions...)
+	}
+	if len(req.Exclusions) == 0 {
+		req.Exclusions = append([]string(nil), settings.Exclusions...)
+	}
+	if settings.DefaultCurrency == "" {
+		return
+	}
+	for i := range req.PaymentPlan {
+		if strings.TrimSpace(req.PaymentPlan[i].Currency) == "" {
+			req.PaymentPlan[i].Currency = settings.DefaultCurrency
+		}
+	}
+	for i := range req.Transfers {
+		if req.Transfers[i].Price > 0 && strings.TrimSpace(req.Transfers[i].Currency) == "" {
+			req.Transfers[i].Currency = settings.DefaultCurrency
+		}
+	}
+}
+
 // GetItinerary retrieves an itinerary by ID
 func (is *ItineraryService) GetItinerary(id string) (*models.Itinerary, error) {
 	return is.store.GetByID(id)
 }
 
-// ListItineraries retrieves all itineraries
-func (is *ItineraryService) ListItineraries() []*models.Itinerary {
-	return is.store.GetAll()
+// ListItineraries retrieves an organization's itineraries, or the personal
+// ones for an empty organization ID, with sensitive traveller details masked
+func (is *ItineraryService) ListItineraries(organizationID string) []*models.Itinerary {
+	itineraries := is.store.GetByOrganization(organizationID)
+	masked := make([]*models.Itinerary, 0, len(itineraries))
+	for _, itinerary := range itineraries {
+		masked = append(masked, maskTravellers(itinerary))
+	}
+	return masked
+}
+
+// FindItineraries retrieves an organization's itineraries matching filter,
+// ordered by start date
+func (is *ItineraryService) FindItineraries(organizationID string, filter *models.ItineraryFilter) []*models.Itinerary {
+	matches := make([]*models.Itinerary, 0)
+	for _, itinerary := range is.store.GetByOrganization(organizationID) {
+		if filter.UserID != "" && itinerary.UserID != filter.UserID {
+			continue
+		}
+		if filter.Location != "" && !strings.Contains(strings.ToLower(itinerary.Location), strings.ToLower(filter.Location)) {
+			continue
+		}
+		if filter.Title != "" && !strings.Contains(strings.ToLower(itinerary.Title), strings.ToLower(filter.Title)) {
+			continue
+		}
+		if !filter.StartFrom.IsZero() && itinerary.StartDate.Before(filter.StartFrom) {
+			continue
+		}
+		if !filter.StartTo.IsZero() && itinerary.StartDate.After(filter.StartTo) {
+			continue
+		}
+		matches = append(matches, itinerary)
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if !matches[i].StartDate.Equal(matches[j].StartDate) {
+			return matches[i].StartDate.Before(matches[j].StartDate)
+		}
+		return matches[i].ID < matches[j].ID
+	})
+
+	return matches
 }
 
 // UpdateItinerary updates an existing itinerary
 func (is *ItineraryService) UpdateItinerary(id string, req *models.UpdateItineraryRequest) (*models.Itinerary, error) {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
 	// Get the existing itinerary
 	itinerary, err := is.store.GetByID(id)
 	if err != nil {
 		return nil, err
 	}
+	return is.applyUpdate(itinerary, req)
+}
+
+// applyUpdate updates a stored itinerary; the caller holds is.mu. The update
+// is built on a copy, so a rejected update leaves the stored itinerary as it
+// was and readers never see a half-applied one.
+func (is *ItineraryService) applyUpdate(itinerary *models.Itinerary, req *models.UpdateItineraryRequest) (*models.Itinerary, error) {
+	if err := checkUpdateIDs(itinerary, req); err != nil {
+		return nil, err
+	}
+
+	// Traveller assignments span several sections, so check them against the
+	// result of the whole update before anything is applied
+	if req.Travellers != nil {
+		req.Travellers = normalizeTravellers(req.Travellers)
+		for _, traveller := range req.Travellers {
+			if err := utils.ValidateTraveller(&traveller); err != nil {
+				return nil, err
+			}
+		}
+	}
+	travellers, hotels, flights := itinerary.Travellers, itinerary.Hotels, itinerary.Flights
+	if req.Travellers != nil {
+		travellers = req.Travellers
+	}
+	if req.Hotels != nil {
+		req.Hotels = normalizeHotels(req.Hotels)
+		hotels = req.Hotels
+	}
+	if req.Flights != nil {
+		req.Flights = normalizeFlights(req.Flights)
+		flights = req.Flights
+	}
+	if err := utils.ValidateTravellerAssignments(travellers, hotels, flights); err != nil {
+		return nil, err
+	}
+
+	// Transfers are timed against the flights and hotels, so re-derive the
+	// stored ones when only those change
+	transfers := req.Transfers
+	if transfers == nil {
+		transfers = append([]models.Transfer(nil), itinerary.Transfers...)
+	}
+	transfers = normalizeTransfers(transfers, flights, hotels)
+	if err := utils.ValidateTransferLinks(transfers, flights, hotels); err != nil {
+		return nil, err
+	}
 
 	// Update fields if provided
+	updated := *itinerary
 	if strings.TrimSpace(req.UserID) != "" {
-		itinerary.UserID = strings.TrimSpace(req.UserID)
+		updated.UserID = strings.TrimSpace(req.UserID)
 	}
 	if req.Title != "" {
-		itinerary.Title = req.Title
+		updated.Title = req.Title
 	}
 	if req.Description != "" {
-		itinerary.Description = req.Description
+		updated.Description = req.Description
 	}
 	if !req.StartDate.IsZero() {
-		itinerary.StartDate = req.StartDate
+		updated.StartDate = req.StartDate
 	}
 	if !req.EndDate.IsZero() {
-		itinerary.EndDate = req.EndDate
+		updated.EndDate = req.EndDate
 	}
 	if req.Location != "" {
-		itinerary.Location = req.Location
+		updated.Location = req.Location
+	}
+	if destination := strings.ToUpper(strings.TrimSpace(req.Destination)); destination != "" {
+		if err := utils.ValidateCountryCode(destination, "destination_country"); err != nil {
+			return nil, err
+		}
+		updated.Destination = destination
 	}
 	if req.Hotels != nil {
 		if len(req.Hotels) == 0 {
@@ -108,7 +349,7 @@ func (is *ItineraryService) UpdateItinerary(id string, req *models.UpdateItinera
 				return nil, err
 			}
 		}
-		itinerary.Hotels = req.Hotels
+		updated.Hotels = req.Hotels
 	}
 	if req.Flights != nil {
 		if len(req.Flights) == 0 {
@@ -119,7 +360,10 @@ func (is *ItineraryService) UpdateItinerary(id string, req *models.UpdateItinera
 				return nil, err
 			}
 		}
-		itinerary.Flights = req.Flights
+		if err := utils.ValidateJourneys(req.Flights); err != nil {
+			return nil, err
+		}
+		updated.Flights = req.Flights
 	}
 	if req.Transfers != nil {
 		if len(req.Transfers) == 0 {
@@ -130,23 +374,26 @@ func (is *ItineraryService) UpdateItinerary(id string, req *models.UpdateItinera
 				return nil, err
 			}
 		}
-		itinerary.Transfers = req.Transfers
+		updated.Transfers = req.Transfers
+	} else {
+		updated.Transfers = transfers
 	}
 	if req.Days != nil {
 		if len(req.Days) == 0 {
 			return nil, utils.NewValidationError("at least one day plan is required")
 		}
+		if err := is.applyCatalogue(updated.OrganizationID, updated.UserID, req.Days); err != nil {
+			return nil, err
+		}
 		for _, day := range req.Days {
 			if err := utils.ValidateDayPlan(&day); err != nil {
 				return nil, err
 			}
 		}
-		itinerary.Days = req.Days
-		for i := range itinerary.Days {
-			for j := range itinerary.Days[i].Activities {
-				itinerary.Days[i].Activities[j] = normalizeActivity(itinerary.Days[i].Activities[j])
-			}
+		if err := utils.ValidateActivityIDs(req.Days); err != nil {
+			return nil, err
 		}
+		updated.Days = normalizeDays(req.Days)
 	}
 	if req.PaymentPlan != nil {
 		if len(req.PaymentPlan) == 0 {
@@ -157,63 +404,361 @@ func (is *ItineraryService) UpdateItinerary(id string, req *models.UpdateItinera
 				return nil, err
 			}
 		}
-		itinerary.PaymentPlan = req.PaymentPlan
+		updated.PaymentPlan = normalizePaymentPlan(req.PaymentPlan)
 	}
 	if req.Inclusions != nil {
 		if err := utils.ValidateStringList(req.Inclusions, "inclusion"); err != nil {
 			return nil, err
 		}
-		itinerary.Inclusions = req.Inclusions
+		updated.Inclusions = req.Inclusions
 	}
 	if req.Exclusions != nil {
 		if err := utils.ValidateStringList(req.Exclusions, "exclusion"); err != nil {
 			return nil, err
 		}
-		itinerary.Exclusions = req.Exclusions
+		updated.Exclusions = req.Exclusions
+	}
+	if req.Travellers != nil {
+		updated.Travellers = req.Travellers
+	}
+	if req.InternalNotes != nil {
+		updated.InternalNotes = strings.TrimSpace(*req.InternalNotes)
 	}
 
-	itinerary.UpdatedAt = time.Now()
-
-	// Update in storage
-	if err := is.store.Update(id, itinerary); err != nil {
+	is.refreshDerived(&updated)
+	if err := is.save(&updated, true); err != nil {
 		return nil, err
 	}
 
-	return itinerary, nil
+	return &updated, nil
 }
 
 // DeleteItinerary deletes an itinerary
 func (is *ItineraryService) DeleteItinerary(id string) error {
-	return is.store.Delete(id)
+	if err := is.store.Delete(id); err != nil {
+		return err
+	}
+	is.notifyChange(id)
+	return nil
 }
 
-// AddActivity adds an activity to a specific day
+// AddActivity adds an activity to a specific day. Details left out of an
+// activity referencing a catalogue entry come from the entry.
 func (is *ItineraryService) AddActivity(itineraryID string, dayNumber int, activity *models.Activity) (*models.Itinerary, error) {
-	if err := utils.ValidateActivity(activity); err != nil {
+	return is.editDays(itineraryID, func(days []models.DayPlan) ([]models.DayPlan, error) {
+		idx, err := dayIndex(days, dayNumber)
+		if err != nil {
+			return nil, err
+		}
+		days[idx].Activities = append(days[idx].Activities, newActivities([]models.Activity{*activity})...)
+		return days, nil
+	})
+}
+
+// CloneItinerary copies an itinerary for userID. A new start date moves
+// every trip date by the same number of days. Internal notes, travellers and
+// their assignments are copied only with canEdit, for users who may read them.
+func (is *ItineraryService) CloneItinerary(itineraryID, userID string, canEdit bool, req *models.CloneItineraryRequest) (*models.Itinerary, error) {
+	itinerary, err := is.store.GetByID(itineraryID)
+	if err != nil {
 		return nil, err
 	}
+	if !canEdit {
+		itinerary = itinerary.WithoutInternalNotes()
+	}
 
+	content, err := itineraryContent(itinerary)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit {
+		dropTravellers(content)
+	}
+	if !req.StartDate.IsZero() {
+		shiftDates(content, daysBetween(itinerary.StartDate, req.StartDate))
+	}
+	if userID != "" {
+		content.UserID = userID
+	}
+	if title := strings.TrimSpace(req.Title); title != "" {
+		content.Title = title
+	}
+
+	return is.CreateItinerary(content)
+}
+
+// ProposeTransfers suggests transfers for an itinerary's flights and hotel
+// stays that its transfers do not cover yet
+func (is *ItineraryService) ProposeTransfers(itineraryID string) ([]models.TransferProposal, error) {
 	itinerary, err := is.store.GetByID(itineraryID)
 	if err != nil {
 		return nil, err
 	}
+	return proposeTransfers(itinerary), nil
+}
 
-	// Find the day and add the activity
-	for i := range itinerary.Days {
-		if itinerary.Days[i].DayNumber == dayNumber {
-			itinerary.Days[i].Activities = append(itinerary.Days[i].Activities, normalizeActivity(*activity))
-			itinerary.UpdatedAt = time.Now()
-			if err := is.store.Update(itineraryID, itinerary); err != nil {
-				return nil, err
+// AcceptTransferProposals adds the chosen proposals, or all of them when no
+// IDs are given, to the itinerary's transfers
+func (is *ItineraryService) AcceptTransferProposals(itineraryID string, proposalIDs []string) (*models.Itinerary, error) {
+	return is.editComponents(itineraryID, func(itinerary *models.Itinerary) (*models.UpdateItineraryRequest, error) {
+		proposals := proposeTransfers(itinerary)
+		byID := make(map[string]models.TransferProposal, len(proposals))
+		for _, proposal := range proposals {
+			byID[proposal.ID] = proposal
+		}
+		if len(proposalIDs) == 0 {
+			for _, proposal := range proposals {
+				proposalIDs = append(proposalIDs, proposal.ID)
 			}
-			return itinerary, nil
 		}
-	}
+		if len(proposalIDs) == 0 {
+			return nil, utils.NewValidationError("there are no transfer proposals to accept")
+		}
+
+		transfers := append([]models.Transfer(nil), itinerary.Transfers...)
+		accepted := make(map[string]bool, len(proposalIDs))
+		for _, id := range proposalIDs {
+			proposal, ok := byID[id]
+			if !ok {
+				return nil, utils.NewValidationError(fmt.Sprintf("transfer proposal %s not found", id))
+			}
+			if accepted[id] {
+				continue
+			}
+			accepted[id] = true
+			transfers = append(transfers, proposal.Transfer)
+		}
+		return &models.UpdateItineraryRequest{Transfers: transfers}, nil
+	})
+}
 
-	return nil, utils.NewValidationError("day not found in itinerary")
+// normalizeDays gives new day plans their IDs and normalises their activities
+func normalizeDays(days []models.DayPlan) []models.DayPlan {
+	for i := range days {
+		day := &days[i]
+		day.ID = strings.TrimSpace(day.ID)
+		if day.ID == "" {
+			day.ID = generateID("day")
+		}
+		for j := range day.Activities {
+			day.Activities[j] = normalizeActivity(day.Activities[j])
+		}
+	}
+	return days
 }
 
+// normalizeActivity lower-cases the period and gives a new activity its ID
 func normalizeActivity(activity models.Activity) models.Activity {
+	activity.ID = strings.TrimSpace(activity.ID)
+	if activity.ID == "" {
+		activity.ID = generateID("act")
+	}
 	activity.Period = strings.ToLower(activity.Period)
 	return activity
 }
+
+// normalizePaymentPlan gives new installments their IDs
+func normalizePaymentPlan(plan []models.PaymentInstallment) []models.PaymentInstallment {
+	for i := range plan {
+		plan[i].ID = strings.TrimSpace(plan[i].ID)
+		if plan[i].ID == "" {
+			plan[i].ID = generateID("pay")
+		}
+	}
+	return plan
+}
+
+// normalizeTravellers trims names, upper-cases codes and assigns IDs to new
+// travellers so flights and rooms can reference them
+func normalizeTravellers(travellers []models.Traveller) []models.Traveller {
+	for i := range travellers {
+		traveller := &travellers[i]
+		traveller.ID = strings.TrimSpace(traveller.ID)
+		if traveller.ID == "" {
+			traveller.ID = generateID("trv")
+		}
+		traveller.FirstName = strings.TrimSpace(traveller.FirstName)
+		traveller.LastName = strings.TrimSpace(traveller.LastName)
+		traveller.Nationality = strings.ToUpper(strings.TrimSpace(traveller.Nationality))
+		traveller.PassportNumber = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(traveller.PassportNumber), " ", ""))
+	}
+	return travellers
+}
+
+// refreshDerived recalculates the journeys and warnings that follow from the
+// itinerary's flights, travellers and dates
+func (is *ItineraryService) refreshDerived(itinerary *models.Itinerary) {
+	itinerary.Journeys = buildJourneys(itinerary.Flights)
+	itinerary.Warnings = is.checks.Check(itinerary)
+}
+
+// normalizeFlights gives new flights their IDs, upper-cases flight numbers
+// and booking codes, resolves airport codes against the reference data and
+// tidies the free-text fields
+func normalizeFlights(flights []models.Flight) []models.Flight {
+	for i := range flights {
+		flight := &flights[i]
+		flight.ID = strings.TrimSpace(flight.ID)
+		if flight.ID == "" {
+			flight.ID = generateID("flt")
+		}
+		flight.FlightNumber = utils.NormalizeFlightNumber(flight.FlightNumber)
+		flight.DepartureAirport, flight.DepartureCity = normalizeAirport(flight.DepartureAirport, flight.DepartureCity)
+		flight.ArrivalAirport, flight.ArrivalCity = normalizeAirport(flight.ArrivalAirport, flight.ArrivalCity)
+		flight.Airline = strings.TrimSpace(flight.Airline)
+		if flight.Airline == "" {
+			if airline, ok := utils.LookupAirline(utils.AirlineDesignator(flight.FlightNumber)); ok {
+				flight.Airline = airline.Name
+			}
+		}
+		flight.JourneyID = strings.TrimSpace(flight.JourneyID)
+		flight.BookingReference = strings.ToUpper(strings.TrimSpace(flight.BookingReference))
+		flight.CabinClass = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(flight.CabinClass), " ", "_"))
+		flight.Seat = strings.ToUpper(strings.TrimSpace(flight.Seat))
+		flight.BaggageAllowance = strings.TrimSpace(flight.BaggageAllowance)
+		flight.DepartureTerminal = strings.TrimSpace(flight.DepartureTerminal)
+		flight.ArrivalTerminal = strings.TrimSpace(flight.ArrivalTerminal)
+	}
+	return flights
+}
+
+// normalizeAirport upper-cases an airport code, swaps a known ICAO code for
+// its IATA code and fills in the city when only the code is given
+func normalizeAirport(code, city string) (string, string) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	city = strings.TrimSpace(city)
+	airport, ok := utils.LookupAirport(code)
+	if !ok {
+		return code, city
+	}
+	if city == "" {
+		city = airport.Location()
+	}
+	return airport.IATA, city
+}
+
+// defaultPickupAfterLanding gives travellers time to clear immigration and
+// collect bags before an airport pickup
+const defaultPickupAfterLanding = 45
+
+// normalizeTransfers gives new transfers their IDs, tidies transfer details
+// and fills in what a linked flight or hotel implies: the pickup time after
+// landing, the arrival airport as pickup and the hotel as dropoff
+func normalizeTransfers(transfers []models.Transfer, flights []models.Flight, hotels []models.Hotel) []models.Transfer {
+	for i := range transfers {
+		transfer := &transfers[i]
+		transfer.ID = strings.TrimSpace(transfer.ID)
+		if transfer.ID == "" {
+			transfer.ID = generateID("trf")
+		}
+		transfer.FlightNumber = utils.NormalizeFlightNumber(transfer.FlightNumber)
+		transfer.HotelName = strings.TrimSpace(transfer.HotelName)
+		transfer.VehicleType = strings.ToLower(strings.TrimSpace(transfer.VehicleType))
+		transfer.Currency = strings.ToUpper(strings.TrimSpace(transfer.Currency))
+		transfer.Pickup = strings.TrimSpace(transfer.Pickup)
+		transfer.Dropoff = strings.TrimSpace(transfer.Dropoff)
+
+		if flight, ok := uniqueFlight(transfer.FlightNumber, flights); ok {
+			if transfer.PickupAt.IsZero() && transfer.PickupAfterLandingMinutes == nil {
+				minutes := defaultPickupAfterLanding
+				transfer.PickupAfterLandingMinutes = &minutes
+			}
+			if transfer.PickupAfterLandingMinutes != nil {
+				transfer.PickupAt = flight.ArrivalTime.Add(time.Duration(*transfer.PickupAfterLandingMinutes) * time.Minute)
+			}
+			if transfer.Pickup == "" {
+				transfer.Pickup = airportLabel(airportName(flight.ArrivalAirport), flight.ArrivalTerminal)
+			}
+		}
+		for _, hotel := range hotels {
+			if transfer.HotelName != "" && strings.EqualFold(hotel.Name, transfer.HotelName) {
+				transfer.HotelName = hotel.Name
+				if transfer.Dropoff == "" {
+					transfer.Dropoff = hotel.Name
+				}
+				break
+			}
+		}
+		if !transfer.PickupAt.IsZero() {
+			transfer.PickupTime = transfer.PickupAt.Format("15:04")
+		}
+	}
+	return transfers
+}
+
+// uniqueFlight finds the flight with the given number when exactly one has it
+func uniqueFlight(number string, flights []models.Flight) (models.Flight, bool) {
+	var match models.Flight
+	count := 0
+	for _, flight := range flights {
+		if number != "" && flight.FlightNumber == number {
+			match = flight
+			count++
+		}
+	}
+	return match, count == 1
+}
+
+// airportName is the airport's full name, or the code when it is not known
+func airportName(code string) string {
+	if airport, ok := utils.LookupAirport(code); ok {
+		return airport.Name
+	}
+	return code
+}
+
+// normalizeHotels gives new hotels their IDs, trims booking details,
+// upper-cases the meal plan and fills in a room's quantity and label when
+// they are left out
+func normalizeHotels(hotels []models.Hotel) []models.Hotel {
+	for i := range hotels {
+		hotel := &hotels[i]
+		hotel.ID = strings.TrimSpace(hotel.ID)
+		if hotel.ID == "" {
+			hotel.ID = generateID("htl")
+		}
+		hotel.Address = strings.TrimSpace(hotel.Address)
+		hotel.Phone = strings.TrimSpace(hotel.Phone)
+		hotel.MealPlan = strings.ToUpper(strings.TrimSpace(hotel.MealPlan))
+		hotel.ConfirmationNumber = strings.TrimSpace(hotel.ConfirmationNumber)
+		for j := range hotel.Rooms {
+			room := &hotel.Rooms[j]
+			room.RoomType = strings.TrimSpace(room.RoomType)
+			room.Label = strings.TrimSpace(room.Label)
+			if room.Label == "" {
+				room.Label = room.RoomType
+			}
+			if room.Quantity == 0 {
+				room.Quantity = 1
+			}
+		}
+	}
+	return hotels
+}
+
+// maskTravellers returns a copy of the itinerary whose travellers only show
+// the last four characters of passport numbers and phone numbers and no
+// date of birth
+func maskTravellers(itinerary *models.Itinerary) *models.Itinerary {
+	if len(itinerary.Travellers) == 0 {
+		return itinerary
+	}
+
+	masked := *itinerary
+	masked.Travellers = make([]models.Traveller, len(itinerary.Travellers))
+	for i, traveller := range itinerary.Travellers {
+		traveller.PassportNumber = maskValue(traveller.PassportNumber)
+		traveller.DateOfBirth = time.Time{}
+		traveller.EmergencyContact.Phone = maskValue(traveller.EmergencyContact.Phone)
+		masked.Travellers[i] = traveller
+	}
+	return &masked
+}
+
+func maskValue(value string) string {
+	runes := []rune(value)
+	if len(runes) <= 4 {
+		return strings.Repeat("*", len(runes))
+	}
+	return strings.Repeat("*", len(runes)-4) + string(runes[len(runes)-4:])
+}
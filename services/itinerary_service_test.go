@@ -0,0 +1,138 @@
+package services
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"vigovia-task/models"
+	"vigovia-task/storage"
+)
+
+func TestRejectedUpdateLeavesItinerary(t *testing.T) {
+	store := storage.NewMemoryStore()
+	itineraries := NewItineraryService(store, NewTravelCheckService(nil))
+	changed := 0
+	itineraries.OnChange(func(string) { changed++ })
+	stored := notedItinerary(t, store, "trip-1", "owner", "")
+
+	_, err := itineraries.UpdateItinerary(stored.ID, &models.UpdateItineraryRequest{
+		Title:      "Renamed",
+		Inclusions: []string{" "},
+	})
+	if err == nil {
+		t.Fatal("update with an empty inclusion was accepted")
+	}
+
+	current, err := store.GetByID(stored.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.Title != "Rajasthan Circuit" || current.Revision != 1 {
+		t.Errorf("rejected update changed the itinerary: title %q, revision %d", current.Title, current.Revision)
+	}
+	if changed != 0 {
+		t.Errorf("rejected update notified %d changes", changed)
+	}
+}
+
+func TestUpdateReplacesStoredItinerary(t *testing.T) {
+	store := storage.NewMemoryStore()
+	itineraries := NewItineraryService(store, NewTravelCheckService(nil))
+	changed := 0
+	itineraries.OnChange(func(string) { changed++ })
+	stored := notedItinerary(t, store, "trip-1", "owner", "")
+
+	updated, err := itineraries.UpdateItinerary(stored.ID, &models.UpdateItineraryRequest{Title: "Renamed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Title != "Renamed" || updated.Revision != 2 || !updated.UpdatedAt.After(stored.UpdatedAt) {
+		t.Errorf("updated itinerary: title %q, revision %d, updated at %v", updated.Title, updated.Revision, updated.UpdatedAt)
+	}
+	if stored.Title != "Rajasthan Circuit" || stored.Revision != 1 {
+		t.Error("update changed the itinerary readers already hold")
+	}
+	if current, _ := store.GetByID(stored.ID); current != updated {
+		t.Error("the store does not hold the updated itinerary")
+	}
+	if changed != 1 {
+		t.Errorf("update notified %d changes, want 1", changed)
+	}
+}
+
+// bookedItinerary stores a complete two-day itinerary with one traveller on
+// its flight and in its hotel room
+func bookedItinerary(t *testing.T, store *storage.MemoryStore, start time.Time) *models.Itinerary {
+	t.Helper()
+	itinerary := datedItinerary(t, store, start, 2)
+	itinerary.Location, itinerary.Destination = "Kochi", "IN"
+	itinerary.Travellers = []models.Traveller{{
+		ID:             "t1",
+		FirstName:      "Asha",
+		LastName:       "Rao",
+		DateOfBirth:    time.Date(1990, 4, 12, 0, 0, 0, 0, time.UTC),
+		Nationality:    "IN",
+		PassportNumber: "Z1234567",
+		PassportExpiry: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+		EmergencyContact: models.EmergencyContact{
+			Name:  "Vikram Rao",
+			Phone: "98290 00000",
+		},
+	}}
+	itinerary.Flights = []models.Flight{{
+		ID:               "flight-1",
+		Airline:          "IndiGo",
+		FlightNumber:     "6E100",
+		DepartureCity:    "Delhi",
+		DepartureAirport: "DEL",
+		DepartureTime:    start.Add(6 * time.Hour),
+		ArrivalCity:      "Kochi",
+		ArrivalAirport:   "COK",
+		ArrivalTime:      start.Add(9 * time.Hour),
+		TravellerIDs:     []string{"t1"},
+	}}
+	itinerary.Hotels = []models.Hotel{{
+		ID:       "hotel-1",
+		Name:     "Lake Palace",
+		City:     "Alleppey",
+		CheckIn:  start.Add(14 * time.Hour),
+		CheckOut: start.AddDate(0, 0, 1).Add(11 * time.Hour),
+		Nights:   1,
+		Rooms:    []models.HotelRoom{{Label: "Deluxe", TravellerIDs: []string{"t1"}}},
+	}}
+	itinerary.Transfers = []models.Transfer{{ID: "transfer-1", Mode: "private", Pickup: "COK", Dropoff: "Lake Palace", PickupTime: "10:00"}}
+	itinerary.PaymentPlan = []models.PaymentInstallment{{ID: "pay-1", InstallmentNumber: 1, Amount: 50000, Currency: "INR", DueDate: start.AddDate(0, 0, -30), Status: "pending"}}
+	itinerary.Inclusions = []string{"Breakfast"}
+	itinerary.Exclusions = []string{"Visa"}
+	return itinerary
+}
+
+func TestViewerCloneLeavesOutTravellers(t *testing.T) {
+	store := storage.NewMemoryStore()
+	itineraries := NewItineraryService(store, NewTravelCheckService(nil))
+	source := bookedItinerary(t, store, time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC))
+
+	clone, err := itineraries.CloneItinerary(source.ID, "viewer", false, &models.CloneItineraryRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(clone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, detail := range []string{"Asha", "Z1234567", "1990-04-12", `"t1"`} {
+		if strings.Contains(string(data), detail) {
+			t.Errorf("a viewer's clone contains the traveller detail %q", detail)
+		}
+	}
+
+	copied, err := itineraries.CloneItinerary(source.ID, "owner", true, &models.CloneItineraryRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(copied.Travellers) != 1 || copied.Travellers[0].PassportNumber != "Z1234567" || len(copied.Flights[0].TravellerIDs) != 1 {
+		t.Errorf("an editor's clone has travellers %+v", copied.Travellers)
+	}
+}
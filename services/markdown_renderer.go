@@ -0,0 +1,319 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"vigovia-task/models"
+)
+
+// MarkdownRenderer renders itineraries as a Markdown document
+type MarkdownRenderer struct{}
+
+// NewMarkdownRenderer creates a new instance of MarkdownRenderer
+func NewMarkdownRenderer() *MarkdownRenderer {
+	return &MarkdownRenderer{}
+}
+
+// Render implements Renderer for Markdown output
+func (mr *MarkdownRenderer) Render(itinerary *models.Itinerary, opts RenderOptions) ([]byte, error) {
+	theme := opts.theme()
+	itinerary = opts.content(itinerary)
+	var sb strings.Builder
+
+	sb.WriteString("# Itinerary Plan\n\n")
+	fmt.Fprintf(&sb, "_%s_\n\n", escapeMarkdown(itinerary.Title))
+	if opts.Internal {
+		fmt.Fprintf(&sb, "> **%s**\n\n", operationsCopyLabel)
+	}
+
+	mr.writeMetadataSection(&sb, itinerary)
+	if itinerary.InternalNotes != "" {
+		sb.WriteString("## Internal Notes\n\n")
+		sb.WriteString(escapeMarkdown(itinerary.InternalNotes) + "\n\n")
+	}
+	mr.writeHotelsSection(&sb, itinerary.Hotels)
+	mr.writeFlightsSection(&sb, itinerary.Flights)
+	mr.writeTransfersSection(&sb, itinerary.Transfers)
+
+	if itinerary.Description != "" {
+		sb.WriteString("## Overview\n\n")
+		sb.WriteString(escapeMarkdown(itinerary.Description) + "\n\n")
+	}
+
+	mr.writeDaysSection(&sb, itinerary.Days)
+	mr.writePaymentPlanSection(&sb, itinerary.PaymentPlan)
+	mr.writeInclusionsExclusionsSection(&sb, itinerary.Inclusions, itinerary.Exclusions)
+	mr.writeTravellersSection(&sb, itinerary.Travellers)
+
+	if !theme.Contact.IsEmpty() {
+		sb.WriteString("## Contact Us\n\n")
+		writeMarkdownField(&sb, "Phone", theme.Contact.Phone)
+		writeMarkdownField(&sb, "Email", theme.Contact.Email)
+		writeMarkdownField(&sb, "Website", theme.Contact.Website)
+		writeMarkdownField(&sb, "Address", theme.Contact.Address)
+		sb.WriteString("\n")
+	}
+
+	if theme.TermsAndConditions != "" {
+		sb.WriteString("## Terms and Conditions\n\n")
+		sb.WriteString(escapeMarkdown(theme.TermsAndConditions) + "\n\n")
+	}
+
+	fmt.Fprintf(&sb, "---\n\n_%s_\n", escapeMarkdown(theme.FooterText))
+
+	return []byte(sb.String()), nil
+}
+
+// ContentType implements Renderer
+func (mr *MarkdownRenderer) ContentType() string {
+	return "text/markdown; charset=utf-8"
+}
+
+// FileExtension implements Renderer
+func (mr *MarkdownRenderer) FileExtension() string {
+	return ".md"
+}
+
+func (mr *MarkdownRenderer) writeMetadataSection(sb *strings.Builder, itinerary *models.Itinerary) {
+	sb.WriteString("## Trip Information\n\n")
+	writeMarkdownField(sb, "User ID", itinerary.UserID)
+	writeMarkdownField(sb, "Location", itinerary.Location)
+	writeMarkdownField(sb, "Start Date", formatDate(itinerary.StartDate))
+	writeMarkdownField(sb, "End Date", formatDate(itinerary.EndDate))
+	writeMarkdownField(sb, "Duration", fmt.Sprintf("%d Days", len(itinerary.Days)))
+	sb.WriteString("\n")
+}
+
+func (mr *MarkdownRenderer) writeHotelsSection(sb *strings.Builder, hotels []models.Hotel) {
+	if len(hotels) == 0 {
+		return
+	}
+
+	sb.WriteString("## Hotel Accommodations\n\n")
+	for idx, hotel := range hotels {
+		fmt.Fprintf(sb, "### Hotel %d: %s\n\n", idx+1, escapeMarkdown(hotel.Name))
+		writeMarkdownField(sb, "Location", hotel.City)
+		writeMarkdownField(sb, "Address", hotel.Address)
+		writeMarkdownField(sb, "Phone", hotel.Phone)
+		if point := hotel.Coordinates; point != nil {
+			fmt.Fprintf(sb, "- **Map:** [%s](%s)\n", formatGeoPoint(*point), mapURL(*point))
+		}
+		if !hotel.CheckIn.IsZero() {
+			writeMarkdownField(sb, "Check-in", formatDate(hotel.CheckIn))
+		}
+		if !hotel.CheckOut.IsZero() {
+			writeMarkdownField(sb, "Check-out", formatDate(hotel.CheckOut))
+		}
+		if hotel.Nights > 0 {
+			writeMarkdownField(sb, "Duration", nightsLabel(hotel.Nights))
+		}
+		writeMarkdownField(sb, "Meal Plan", mealPlanLabel(hotel.MealPlan))
+		writeMarkdownField(sb, "Confirmation", hotel.ConfirmationNumber)
+		for _, room := range hotel.Rooms {
+			writeMarkdownField(sb, "Room", roomLabel(room))
+		}
+		writeMarkdownField(sb, "Internal", hotel.InternalNotes)
+		sb.WriteString("\n")
+	}
+}
+
+func (mr *MarkdownRenderer) writeFlightsSection(sb *strings.Builder, flights []models.Flight) {
+	if len(flights) == 0 {
+		return
+	}
+
+	sb.WriteString("## Flight Details\n\n")
+	for _, group := range groupFlights(flights) {
+		heading := "###"
+		if group.Journey != nil {
+			fmt.Fprintf(sb, "### %s\n\n", escapeMarkdown(journeyTitle(group.Journey, flights)))
+			heading = "####"
+		}
+
+		for _, leg := range group.Legs {
+			flight := leg.Flight
+			if leg.Layover != nil {
+				fmt.Fprintf(sb, "_%s_\n\n", escapeMarkdown(layoverLabel(leg.Layover)))
+			}
+			fmt.Fprintf(sb, "%s %s\n\n", heading, escapeMarkdown(flightTitle(leg.Index, flight)))
+			writeMarkdownField(sb, "Booking", flight.BookingReference)
+			writeMarkdownField(sb, "Cabin", cabinLabel(flight))
+			writeMarkdownField(sb, "Baggage", flight.BaggageAllowance)
+			writeMarkdownField(sb, "Internal", flight.InternalNotes)
+			if flight.BookingReference != "" || flight.CabinClass != "" || flight.Seat != "" || flight.BaggageAllowance != "" || flight.InternalNotes != "" {
+				sb.WriteString("\n")
+			}
+
+			sb.WriteString("**Departure**\n\n")
+			writeMarkdownField(sb, "City", flight.DepartureCity)
+			writeMarkdownField(sb, "Airport", airportLabel(flight.DepartureAirport, flight.DepartureTerminal))
+			if !flight.DepartureTime.IsZero() {
+				writeMarkdownField(sb, "Time", formatDateTime(flight.DepartureTime))
+			}
+			sb.WriteString("\n**Arrival**\n\n")
+			writeMarkdownField(sb, "City", flight.ArrivalCity)
+			writeMarkdownField(sb, "Airport", airportLabel(flight.ArrivalAirport, flight.ArrivalTerminal))
+			if !flight.ArrivalTime.IsZero() {
+				writeMarkdownField(sb, "Time", formatDateTime(flight.ArrivalTime))
+			}
+			sb.WriteString("\n")
+		}
+	}
+}
+
+func (mr *MarkdownRenderer) writeTransfersSection(sb *strings.Builder, transfers []models.Transfer) {
+	if len(transfers) == 0 {
+		return
+	}
+
+	sb.WriteString("## Transfers\n\n")
+	for _, transfer := range transfers {
+		fmt.Fprintf(sb, "### %s Transfer\n\n", escapeMarkdown(toTitleCase(transfer.Mode)))
+		writeMarkdownField(sb, "Pickup", transfer.Pickup)
+		writeMarkdownField(sb, "Drop-off", transfer.Dropoff)
+		writeMarkdownField(sb, "Time", transferTimeLabel(transfer))
+		writeMarkdownField(sb, "Vehicle", vehicleLabel(transfer))
+		writeMarkdownField(sb, "Vendor", contactLabel(transfer.Vendor))
+		writeMarkdownField(sb, "Driver", contactLabel(transfer.Driver))
+		if transfer.Price > 0 {
+			writeMarkdownField(sb, "Price", formatAmount(transfer.Price, transfer.Currency))
+		}
+		writeMarkdownField(sb, "Notes", strings.TrimSpace(transfer.Notes))
+		writeMarkdownField(sb, "Internal", transfer.InternalNotes)
+		sb.WriteString("\n")
+	}
+}
+
+func (mr *MarkdownRenderer) writeDaysSection(sb *strings.Builder, days []models.DayPlan) {
+	sb.WriteString("## Day-by-Day Itinerary\n\n")
+	if len(days) == 0 {
+		sb.WriteString("_No days planned yet_\n\n")
+		return
+	}
+
+	for _, day := range days {
+		fmt.Fprintf(sb, "### Day %d: %s\n\n", day.DayNumber, escapeMarkdown(day.Title))
+		fmt.Fprintf(sb, "Date: %s\n\n", formatDate(day.Date))
+		if day.InternalNotes != "" {
+			fmt.Fprintf(sb, "_Internal: %s_\n\n", escapeMarkdown(day.InternalNotes))
+		}
+
+		if len(day.Activities) == 0 {
+			sb.WriteString("_No activities planned for this day_\n\n")
+			continue
+		}
+
+		for _, group := range groupActivitiesByPeriod(day.Activities) {
+			fmt.Fprintf(sb, "#### %s Session\n\n", group.Label)
+			for _, activity := range group.Activities {
+				fmt.Fprintf(sb, "- **%s** %s\n", escapeMarkdown(activity.Time), escapeMarkdown(activity.Title))
+				if activity.Description != "" {
+					fmt.Fprintf(sb, "  - Description: %s\n", escapeMarkdown(activity.Description))
+				}
+				if activity.Location != "" {
+					fmt.Fprintf(sb, "  - Location: %s\n", escapeMarkdown(activity.Location))
+				}
+				if activity.Duration != "" {
+					fmt.Fprintf(sb, "  - Duration: %s\n", escapeMarkdown(activity.Duration))
+				}
+				if activity.InternalNotes != "" {
+					fmt.Fprintf(sb, "  - Internal: %s\n", escapeMarkdown(activity.InternalNotes))
+				}
+			}
+			sb.WriteString("\n")
+		}
+	}
+}
+
+func (mr *MarkdownRenderer) writePaymentPlanSection(sb *strings.Builder, plan []models.PaymentInstallment) {
+	if len(plan) == 0 {
+		return
+	}
+
+	sb.WriteString("## Payment Plan\n\n")
+	sb.WriteString("| Installment | Amount | Due Date | Status |\n")
+	sb.WriteString("| --- | --- | --- | --- |\n")
+	for _, installment := range sortedInstallments(plan) {
+		fmt.Fprintf(sb, "| #%d | %s | %s | %s |\n",
+			installment.InstallmentNumber,
+			formatAmount(installment.Amount, installment.Currency),
+			formatDate(installment.DueDate),
+			escapeMarkdown(installmentStatus(installment)))
+	}
+	sb.WriteString("\n")
+
+	for _, installment := range sortedInstallments(plan) {
+		writeMarkdownField(sb, fmt.Sprintf("Installment #%d internal", installment.InstallmentNumber), installment.InternalNotes)
+	}
+}
+
+func (mr *MarkdownRenderer) writeInclusionsExclusionsSection(sb *strings.Builder, inclusions, exclusions []string) {
+	if len(inclusions) == 0 && len(exclusions) == 0 {
+		return
+	}
+
+	sb.WriteString("## Inclusions and Exclusions\n\n")
+	if len(inclusions) > 0 {
+		sb.WriteString("### Inclusions\n\n")
+		for _, item := range inclusions {
+			sb.WriteString("- " + escapeMarkdown(item) + "\n")
+		}
+		sb.WriteString("\n")
+	}
+	if len(exclusions) > 0 {
+		sb.WriteString("### Exclusions\n\n")
+		for _, item := range exclusions {
+			sb.WriteString("- " + escapeMarkdown(item) + "\n")
+		}
+		sb.WriteString("\n")
+	}
+}
+
+func (mr *MarkdownRenderer) writeTravellersSection(sb *strings.Builder, travellers []models.Traveller) {
+	if len(travellers) == 0 {
+		return
+	}
+
+	sb.WriteString("## Passenger Manifest\n\n")
+	sb.WriteString("| No. | Name | Date of Birth | Nationality | Passport | Expiry |\n")
+	sb.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+	for idx, traveller := range travellers {
+		fmt.Fprintf(sb, "| %d | %s | %s | %s | %s | %s |\n",
+			idx+1,
+			escapeMarkdown(traveller.FullName()),
+			formatDate(traveller.DateOfBirth),
+			escapeMarkdown(traveller.Nationality),
+			escapeMarkdown(traveller.PassportNumber),
+			formatDate(traveller.PassportExpiry))
+	}
+	sb.WriteString("\n")
+
+	for _, traveller := range travellers {
+		if traveller.DietaryRequirements == "" && traveller.AccessibilityNeeds == "" {
+			continue
+		}
+		fmt.Fprintf(sb, "**%s**\n\n", escapeMarkdown(traveller.FullName()))
+		writeMarkdownField(sb, "Dietary", traveller.DietaryRequirements)
+		writeMarkdownField(sb, "Accessibility", traveller.AccessibilityNeeds)
+		sb.WriteString("\n")
+	}
+}
+
+func writeMarkdownField(sb *strings.Builder, label, value string) {
+	if value == "" {
+		return
+	}
+	fmt.Fprintf(sb, "- **%s:** %s\n", label, escapeMarkdown(value))
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "|", `\|`,
+	"[", `\[`, "]", `\]`, "<", "&lt;", ">", "&gt;",
+)
+
+// escapeMarkdown neutralises characters that would otherwise be interpreted
+// as Markdown or inline HTML in user-supplied text
+func escapeMarkdown(value string) string {
+	return markdownEscaper.Replace(value)
+}
@@ -0,0 +1,477 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"vigovia-task/models"
+	"vigovia-task/storage"
+	"vigovia-task/utils"
+)
+
+// ErrOrganizationAdminRequired is returned when an agent tries something
+// only an organization's admins may do
+var ErrOrganizationAdminRequired = errors.New("only organization admins can do this")
+
+// ErrInvitationNotFound is returned for invitations that do not exist, were
+// already answered or were sent to another email
+var ErrInvitationNotFound = errors.New("invitation not found")
+
+// OrganizationService manages agencies, their members and their settings
+type OrganizationService struct {
+	store       *storage.MemoryStore
+	itineraries *ItineraryService
+	access      *AccessService
+	mu          sync.Mutex // serialises membership changes
+}
+
+// NewOrganizationService creates a new instance of OrganizationService
+func NewOrganizationService(store *storage.MemoryStore, itineraries *ItineraryService, access *AccessService) *OrganizationService {
+	return &OrganizationService{
+		store:       store,
+		itineraries: itineraries,
+		access:      access,
+	}
+}
+
+// CreateOrganization creates an organization with userID as its first
+// admin. The user's personal itineraries and catalogue entries move into it
+// when the request asks for it.
+func (orgs *OrganizationService) CreateOrganization(userID string, req *models.OrganizationRequest) (*models.Organization, error) {
+	normalizeOrganizationRequest(req)
+	if err := utils.ValidateOrganization(req); err != nil {
+		return nil, err
+	}
+
+	orgs.mu.Lock()
+	defer orgs.mu.Unlock()
+
+	user, err := orgs.store.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.OrganizationID != "" {
+		return nil, utils.NewValidationError("you already belong to an organization")
+	}
+	if err := orgs.checkBrandingProfile(req.Settings.BrandingProfileID, func(owner string) bool { return owner == userID }); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	organization := &models.Organization{
+		ID:        generateID("org"),
+		Name:      req.Name,
+		Settings:  req.Settings,
+		CreatedBy: userID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := orgs.store.CreateOrganization(organization); err != nil {
+		return nil, err
+	}
+	if err := orgs.join(user, organization.ID, models.OrgRoleAdmin, req.MovePersonalItems); err != nil {
+		return nil, err
+	}
+	return organization, nil
+}
+
+// GetOrganization retrieves an organization userID belongs to
+func (orgs *OrganizationService) GetOrganization(userID, id string) (*models.Organization, error) {
+	organization, _, err := orgs.member(userID, id)
+	return organization, err
+}
+
+// UpdateOrganization replaces an organization's name and settings
+func (orgs *OrganizationService) UpdateOrganization(userID, id string, req *models.OrganizationRequest) (*models.Organization, error) {
+	normalizeOrganizationRequest(req)
+	if err := utils.ValidateOrganization(req); err != nil {
+		return nil, err
+	}
+
+	organization, err := orgs.admin(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := orgs.checkBrandingProfile(req.Settings.BrandingProfileID, func(owner string) bool {
+		user, err := orgs.store.GetUserByID(owner)
+		return err == nil && user.OrganizationID == id
+	}); err != nil {
+		return nil, err
+	}
+
+	updated := *organization
+	updated.Name = req.Name
+	updated.Settings = req.Settings
+	updated.UpdatedAt = time.Now()
+	if err := orgs.store.UpdateOrganization(&updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// ListMembers returns the members of an organization userID belongs to
+func (orgs *OrganizationService) ListMembers(userID, id string) ([]*models.OrganizationMember, error) {
+	if _, _, err := orgs.member(userID, id); err != nil {
+		return nil, err
+	}
+
+	users := orgs.store.GetUsersByOrganization(id)
+	members := make([]*models.OrganizationMember, 0, len(users))
+	for _, user := range users {
+		members = append(members, organizationMember(user))
+	}
+	return members, nil
+}
+
+// InviteMember invites an email to join an organization. The invitee
+// becomes a member only once they accept; re-inviting an email with a
+// pending invitation changes its role.
+func (orgs *OrganizationService) InviteMember(userID, id string, req *models.InviteMemberRequest) (*models.OrganizationInvitation, error) {
+	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+	req.Role = strings.ToLower(strings.TrimSpace(req.Role))
+	if err := utils.ValidateOrganizationRole(req.Role); err != nil {
+		return nil, err
+	}
+
+	orgs.mu.Lock()
+	defer orgs.mu.Unlock()
+
+	organization, err := orgs.admin(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if user, err := orgs.store.GetUserByEmail(req.Email); err == nil && user.OrganizationID == id {
+		return nil, utils.NewValidationError(fmt.Sprintf("%s is already a member", req.Email))
+	}
+
+	now := time.Now()
+	for _, existing := range orgs.store.GetInvitationsByOrganization(id) {
+		if existing.Email != req.Email || existing.Status != models.InvitationPending {
+			continue
+		}
+		invitation := *existing
+		invitation.Role = req.Role
+		invitation.UpdatedAt = now
+		if err := orgs.store.UpdateInvitation(&invitation); err != nil {
+			return nil, err
+		}
+		return &invitation, nil
+	}
+
+	invitation := &models.OrganizationInvitation{
+		ID:               generateID("inv"),
+		OrganizationID:   id,
+		OrganizationName: organization.Name,
+		Email:            req.Email,
+		Role:             req.Role,
+		Status:           models.InvitationPending,
+		InvitedBy:        userID,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	if err := orgs.store.CreateInvitation(invitation); err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+// ListInvitations returns an organization's pending invitations to its
+// admins
+func (orgs *OrganizationService) ListInvitations(userID, id string) ([]*models.OrganizationInvitation, error) {
+	if _, err := orgs.admin(userID, id); err != nil {
+		return nil, err
+	}
+
+	pending := make([]*models.OrganizationInvitation, 0)
+	for _, invitation := range orgs.store.GetInvitationsByOrganization(id) {
+		if invitation.Status == models.InvitationPending {
+			pending = append(pending, invitation)
+		}
+	}
+	return pending, nil
+}
+
+// RevokeInvitation withdraws a pending invitation
+func (orgs *OrganizationService) RevokeInvitation(userID, id, invitationID string) error {
+	orgs.mu.Lock()
+	defer orgs.mu.Unlock()
+
+	if _, err := orgs.admin(userID, id); err != nil {
+		return err
+	}
+	invitation, err := orgs.store.GetInvitation(invitationID)
+	if err != nil || invitation.OrganizationID != id || invitation.Status != models.InvitationPending {
+		return utils.NewValidationError(fmt.Sprintf("invitation %s not found in organization", invitationID))
+	}
+	return orgs.store.DeleteInvitation(invitationID)
+}
+
+// UserInvitations returns the pending invitations sent to userID's email
+func (orgs *OrganizationService) UserInvitations(userID string) []*models.OrganizationInvitation {
+	user, err := orgs.store.GetUserByID(userID)
+	if err != nil {
+		return []*models.OrganizationInvitation{}
+	}
+
+	pending := make([]*models.OrganizationInvitation, 0)
+	for _, invitation := range orgs.store.GetInvitationsByEmail(strings.ToLower(user.Email)) {
+		if invitation.Status == models.InvitationPending {
+			pending = append(pending, invitation)
+		}
+	}
+	return pending
+}
+
+// AcceptInvitation makes userID a member of the organization that invited
+// their email. Their personal itineraries and catalogue entries move in
+// only when the request asks for it.
+func (orgs *OrganizationService) AcceptInvitation(userID, invitationID string, req *models.AcceptInvitationRequest) (*models.OrganizationMember, error) {
+	orgs.mu.Lock()
+	defer orgs.mu.Unlock()
+
+	user, invitation, err := orgs.pendingInvitation(userID, invitationID)
+	if err != nil {
+		return nil, err
+	}
+	switch user.OrganizationID {
+	case "":
+	case invitation.OrganizationID:
+		return nil, utils.NewValidationError("you are already a member")
+	default:
+		return nil, utils.NewValidationError("leave your current organization before joining another")
+	}
+	if _, err := orgs.store.GetOrganization(invitation.OrganizationID); err != nil {
+		return nil, err
+	}
+
+	if err := orgs.answerInvitation(invitation, models.InvitationAccepted); err != nil {
+		return nil, err
+	}
+	if err := orgs.join(user, invitation.OrganizationID, invitation.Role, req.MovePersonalItems); err != nil {
+		return nil, err
+	}
+	joined, err := orgs.store.GetUserByID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return organizationMember(joined), nil
+}
+
+// DeclineInvitation turns down an invitation sent to userID's email
+func (orgs *OrganizationService) DeclineInvitation(userID, invitationID string) error {
+	orgs.mu.Lock()
+	defer orgs.mu.Unlock()
+
+	_, invitation, err := orgs.pendingInvitation(userID, invitationID)
+	if err != nil {
+		return err
+	}
+	return orgs.answerInvitation(invitation, models.InvitationDeclined)
+}
+
+// pendingInvitation finds a pending invitation sent to userID's email.
+// Invitations to other emails get the same error as missing ones.
+func (orgs *OrganizationService) pendingInvitation(userID, invitationID string) (*models.User, *models.OrganizationInvitation, error) {
+	user, err := orgs.store.GetUserByID(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	invitation, err := orgs.store.GetInvitation(invitationID)
+	if err != nil || invitation.Status != models.InvitationPending || invitation.Email != strings.ToLower(user.Email) {
+		return nil, nil, ErrInvitationNotFound
+	}
+	return user, invitation, nil
+}
+
+func (orgs *OrganizationService) answerInvitation(invitation *models.OrganizationInvitation, status string) error {
+	answered := *invitation
+	answered.Status = status
+	answered.UpdatedAt = time.Now()
+	return orgs.store.UpdateInvitation(&answered)
+}
+
+// UpdateMember changes a member's role. The last admin cannot become an
+// agent.
+func (orgs *OrganizationService) UpdateMember(userID, id, memberID string, req *models.UpdateMemberRequest) (*models.OrganizationMember, error) {
+	req.Role = strings.ToLower(strings.TrimSpace(req.Role))
+	if err := utils.ValidateOrganizationRole(req.Role); err != nil {
+		return nil, err
+	}
+
+	orgs.mu.Lock()
+	defer orgs.mu.Unlock()
+
+	if _, err := orgs.admin(userID, id); err != nil {
+		return nil, err
+	}
+	user, err := orgs.memberUser(id, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if user.OrganizationRole == models.OrgRoleAdmin && req.Role != models.OrgRoleAdmin && orgs.adminCount(id) == 1 {
+		return nil, utils.NewValidationError("an organization needs at least one admin")
+	}
+
+	updated := *user
+	updated.OrganizationRole = req.Role
+	updated.UpdatedAt = time.Now()
+	if err := orgs.store.UpdateUser(&updated); err != nil {
+		return nil, err
+	}
+	return organizationMember(&updated), nil
+}
+
+// RemoveMember takes a member out of an organization. Admins can remove
+// anyone and members can leave; the last admin cannot. Itineraries the
+// member created stay with the organization.
+func (orgs *OrganizationService) RemoveMember(userID, id, memberID string) error {
+	orgs.mu.Lock()
+	defer orgs.mu.Unlock()
+
+	if userID == memberID {
+		if _, _, err := orgs.member(userID, id); err != nil {
+			return err
+		}
+	} else if _, err := orgs.admin(userID, id); err != nil {
+		return err
+	}
+	user, err := orgs.memberUser(id, memberID)
+	if err != nil {
+		return err
+	}
+	if user.OrganizationRole == models.OrgRoleAdmin && orgs.adminCount(id) == 1 {
+		return utils.NewValidationError("an organization needs at least one admin")
+	}
+
+	updated := *user
+	updated.OrganizationID = ""
+	updated.OrganizationRole = ""
+	updated.UpdatedAt = time.Now()
+	return orgs.store.UpdateUser(&updated)
+}
+
+// ListItineraries returns every itinerary of an organization userID belongs
+// to, ordered by start date. An owner ID keeps only that member's
+// itineraries. Internal notes are removed where userID cannot edit.
+func (orgs *OrganizationService) ListItineraries(userID, id, ownerID string) ([]*models.Itinerary, error) {
+	if _, _, err := orgs.member(userID, id); err != nil {
+		return nil, err
+	}
+
+	itineraries := make([]*models.Itinerary, 0)
+	for _, itinerary := range orgs.itineraries.ListItineraries(id) {
+		if ownerID == "" || itinerary.UserID == ownerID {
+			itineraries = append(itineraries, itinerary)
+		}
+	}
+	sort.Slice(itineraries, func(i, j int) bool {
+		if !itineraries[i].StartDate.Equal(itineraries[j].StartDate) {
+			return itineraries[i].StartDate.Before(itineraries[j].StartDate)
+		}
+		return itineraries[i].ID < itineraries[j].ID
+	})
+	return orgs.access.HideInternalNotes(userID, itineraries), nil
+}
+
+// member returns an organization and the user when the user belongs to it.
+// Others get the same error as for a missing organization.
+func (orgs *OrganizationService) member(userID, id string) (*models.Organization, *models.User, error) {
+	user, err := orgs.store.GetUserByID(userID)
+	if err != nil || user.OrganizationID != id {
+		return nil, nil, fmt.Errorf("organization with id %s not found", id)
+	}
+	organization, err := orgs.store.GetOrganization(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return organization, user, nil
+}
+
+// admin returns an organization when the user is one of its admins
+func (orgs *OrganizationService) admin(userID, id string) (*models.Organization, error) {
+	organization, user, err := orgs.member(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if user.OrganizationRole != models.OrgRoleAdmin {
+		return nil, ErrOrganizationAdminRequired
+	}
+	return organization, nil
+}
+
+func (orgs *OrganizationService) memberUser(id, memberID string) (*models.User, error) {
+	user, err := orgs.store.GetUserByID(memberID)
+	if err != nil || user.OrganizationID != id {
+		return nil, utils.NewValidationError(fmt.Sprintf("member %s not found in organization", memberID))
+	}
+	return user, nil
+}
+
+func (orgs *OrganizationService) adminCount(id string) int {
+	count := 0
+	for _, user := range orgs.store.GetUsersByOrganization(id) {
+		if user.OrganizationRole == models.OrgRoleAdmin {
+			count++
+		}
+	}
+	return count
+}
+
+// join makes a user a member and, with movePersonal, moves their personal
+// itineraries and catalogue entries in
+func (orgs *OrganizationService) join(user *models.User, id, role string, movePersonal bool) error {
+	joined := *user
+	joined.OrganizationID = id
+	joined.OrganizationRole = role
+	joined.UpdatedAt = time.Now()
+	if err := orgs.store.UpdateUser(&joined); err != nil {
+		return err
+	}
+	if !movePersonal {
+		return nil
+	}
+	for _, entry := range orgs.store.GetCatalogueByOrganization("") {
+		if entry.CreatedBy != user.ID {
+			continue
+		}
+		moved := *entry
+		moved.OrganizationID = id
+		if err := orgs.store.UpdateCatalogueEntry(&moved); err != nil {
+			return err
+		}
+	}
+	return orgs.itineraries.MoveToOrganization(user.ID, id)
+}
+
+// checkBrandingProfile checks that a settings branding profile exists and
+// is owned by someone allowed to lend it to the organization
+func (orgs *OrganizationService) checkBrandingProfile(profileID string, allowed func(owner string) bool) error {
+	if profileID == "" {
+		return nil
+	}
+	profile, err := orgs.store.GetBrandingProfile(profileID)
+	if err != nil || !allowed(profile.UserID) {
+		return utils.NewValidationError(fmt.Sprintf("branding profile %s must belong to a member of the organization", profileID))
+	}
+	return nil
+}
+
+func normalizeOrganizationRequest(req *models.OrganizationRequest) {
+	req.Name = strings.TrimSpace(req.Name)
+	req.Settings.DefaultCurrency = strings.ToUpper(strings.TrimSpace(req.Settings.DefaultCurrency))
+	req.Settings.BrandingProfileID = strings.TrimSpace(req.Settings.BrandingProfileID)
+}
+
+func organizationMember(user *models.User) *models.OrganizationMember {
+	return &models.OrganizationMember{
+		UserID:   user.ID,
+		Email:    user.Email,
+		Username: user.Username,
+		FullName: user.FullName,
+		Role:     user.OrganizationRole,
+	}
+}
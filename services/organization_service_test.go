@@ -0,0 +1,116 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"vigovia-task/models"
+	"vigovia-task/storage"
+)
+
+func newTestOrganizations(t *testing.T) (*storage.MemoryStore, *OrganizationService, *AccessService, *models.Organization) {
+	t.Helper()
+	store := storage.NewMemoryStore()
+	access := NewAccessService(store)
+	orgs := NewOrganizationService(store, NewItineraryService(store, NewTravelCheckService(nil)), access)
+	addUser(t, store, "admin", "", "")
+	organization, err := orgs.CreateOrganization("admin", &models.OrganizationRequest{Name: "Acme Travel"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store, orgs, access, organization
+}
+
+func TestInvitationChangesNothingUntilAccepted(t *testing.T) {
+	store, orgs, access, organization := newTestOrganizations(t)
+	addUser(t, store, "victim", "", "")
+	personal := notedItinerary(t, store, "trip-1", "victim", "")
+
+	invitation, err := orgs.InviteMember("admin", organization.ID, &models.InviteMemberRequest{Email: "Victim@Example.com", Role: models.OrgRoleAdmin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user, _ := store.GetUserByID("victim"); user.OrganizationID != "" {
+		t.Error("inviting made the invitee a member")
+	}
+	if role := access.Role(personal, "admin"); role != "" {
+		t.Errorf("the inviting admin has the %q role on the invitee's itinerary", role)
+	}
+
+	addUser(t, store, "stranger", "", "")
+	if _, err := orgs.AcceptInvitation("stranger", invitation.ID, &models.AcceptInvitationRequest{}); !errors.Is(err, ErrInvitationNotFound) {
+		t.Errorf("accepting another email's invitation got %v, want ErrInvitationNotFound", err)
+	}
+	if got := orgs.UserInvitations("victim"); len(got) != 1 || got[0].ID != invitation.ID {
+		t.Errorf("invitee sees invitations %v", got)
+	}
+}
+
+func TestAcceptInvitationKeepsPersonalItineraries(t *testing.T) {
+	store, orgs, access, organization := newTestOrganizations(t)
+	addUser(t, store, "agent", "", "")
+	personal := notedItinerary(t, store, "trip-1", "agent", "")
+
+	invitation, err := orgs.InviteMember("admin", organization.ID, &models.InviteMemberRequest{Email: "agent@example.com", Role: models.OrgRoleAgent})
+	if err != nil {
+		t.Fatal(err)
+	}
+	member, err := orgs.AcceptInvitation("agent", invitation.ID, &models.AcceptInvitationRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if member.Role != models.OrgRoleAgent {
+		t.Errorf("member role %q, want agent", member.Role)
+	}
+
+	kept, _ := store.GetByID(personal.ID)
+	if kept.OrganizationID != "" {
+		t.Error("accepting moved a personal itinerary into the organization")
+	}
+	if role := access.Role(kept, "agent"); role != models.RoleOwner {
+		t.Errorf("the new member has the %q role on their personal itinerary, want owner", role)
+	}
+	if role := access.Role(kept, "admin"); role != "" {
+		t.Errorf("the organization admin has the %q role on a personal itinerary", role)
+	}
+	if _, err := orgs.AcceptInvitation("agent", invitation.ID, &models.AcceptInvitationRequest{}); !errors.Is(err, ErrInvitationNotFound) {
+		t.Errorf("accepting twice got %v", err)
+	}
+}
+
+func TestAcceptInvitationMovesPersonalItemsOnRequest(t *testing.T) {
+	store, orgs, access, organization := newTestOrganizations(t)
+	addUser(t, store, "agent", "", "")
+	personal := notedItinerary(t, store, "trip-1", "agent", "")
+
+	invitation, err := orgs.InviteMember("admin", organization.ID, &models.InviteMemberRequest{Email: "agent@example.com", Role: models.OrgRoleAgent})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := orgs.AcceptInvitation("agent", invitation.ID, &models.AcceptInvitationRequest{MovePersonalItems: true}); err != nil {
+		t.Fatal(err)
+	}
+	moved, _ := store.GetByID(personal.ID)
+	if moved.OrganizationID != organization.ID || access.Role(moved, "admin") != models.RoleAdmin {
+		t.Errorf("itinerary in organization %q, admin role %q", moved.OrganizationID, access.Role(moved, "admin"))
+	}
+}
+
+func TestDeclineInvitation(t *testing.T) {
+	store, orgs, _, organization := newTestOrganizations(t)
+	addUser(t, store, "agent", "", "")
+
+	invitation, err := orgs.InviteMember("admin", organization.ID, &models.InviteMemberRequest{Email: "agent@example.com", Role: models.OrgRoleAgent})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := orgs.DeclineInvitation("agent", invitation.ID); err != nil {
+		t.Fatal(err)
+	}
+	if user, _ := store.GetUserByID("agent"); user.OrganizationID != "" {
+		t.Error("declining made the invitee a member")
+	}
+	if pending, _ := orgs.ListInvitations("admin", organization.ID); len(pending) != 0 {
+		t.Errorf("%d invitations still pending after the decline", len(pending))
+	}
+}
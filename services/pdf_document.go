@@ -0,0 +1,489 @@
+package services
+
+import (
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"vigovia-task/assets"
+	"vigovia-task/models"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Font families registered on every generated PDF
+const (
+	defaultFontFamily    = "DejaVu"
+	devanagariFontFamily = "NotoDevanagari"
+)
+
+// fontFace maps a bundled TrueType file to a family and style
+type fontFace struct {
+	family string
+	style  string
+	file   string
+}
+
+var bundledFontFaces = []fontFace{
+	{defaultFontFamily, "", "fonts/DejaVuSansCondensed.ttf"},
+	{defaultFontFamily, "B", "fonts/DejaVuSansCondensed-Bold.ttf"},
+	{defaultFontFamily, "I", "fonts/DejaVuSansCondensed-Oblique.ttf"},
+	{defaultFontFamily, "BI", "fonts/DejaVuSansCondensed-BoldOblique.ttf"},
+	{devanagariFontFamily, "", "fonts/NotoSansDevanagari-Regular.ttf"},
+}
+
+// loadedFont is a font face whose bytes have been read from the bundle
+type loadedFont struct {
+	fontFace
+	data []byte
+}
+
+// loadBundledFonts reads the embedded font files. Every face must be present
+// in the bundle.
+func loadBundledFonts() ([]loadedFont, error) {
+	fonts := make([]loadedFont, 0, len(bundledFontFaces))
+	for _, face := range bundledFontFaces {
+		data, err := fs.ReadFile(assets.Fonts, face.file)
+		if err != nil {
+			return nil, fmt.Errorf("bundled font %s: %w", face.file, err)
+		}
+		fonts = append(fonts, loadedFont{fontFace: face, data: data})
+	}
+	return fonts, nil
+}
+
+// pdfDocument wraps gofpdf with Unicode-aware text output. It tracks the
+// active font so that text in scripts the default family cannot display is
+// switched to a fallback family. gofpdf performs no text shaping, so Arabic
+// letters are replaced by their joined presentation forms and right-to-left
+// and Devanagari text is reordered into visual order.
+type pdfDocument struct {
+	*gofpdf.Fpdf
+	theme    *models.BrandingProfile
+	layout   PageLayout
+	geometry pageGeometry
+	family   string
+	style    string
+	size     float64
+	faces    map[string]map[string]bool
+	current  string
+
+	// continuation is repeated at the top of a page when a section or day
+	// spans a page break
+	continuation string
+	// previous holds the pagination measured by an earlier rendering pass
+	previous *pdfPagination
+	dayLinks []int
+	dayPages []int
+	// dayDone is called after each day is laid out
+	dayDone func(done int)
+	// internal marks the operations copy on every page
+	internal bool
+}
+
+// newPDFDocument creates a gofpdf document for the page layout and registers
+// the bundled fonts and any custom fonts of the theme
+func newPDFDocument(layout PageLayout, fonts []loadedFont, theme *models.BrandingProfile) *pdfDocument {
+	pdf := gofpdf.New(layout.gofpdfOrientation(), "mm", layout.PageSize, "")
+	pageWidth, pageHeight := pdf.GetPageSize()
+
+	doc := &pdfDocument{
+		Fpdf:     pdf,
+		theme:    theme,
+		layout:   layout,
+		geometry: pageGeometry{pageWidth: pageWidth, pageHeight: pageHeight, margin: layout.Margin},
+		family:   defaultFontFamily,
+		faces:    make(map[string]map[string]bool),
+	}
+	doc.SetMargins(doc.geometry.left(), doc.geometry.top(), doc.geometry.margin)
+	doc.SetAutoPageBreak(true, doc.geometry.bottom())
+
+	for _, font := range fonts {
+		doc.addFont(font.family, font.style, font.data)
+	}
+
+	if theme.Fonts.Family != "" && len(theme.Fonts.Regular) > 0 {
+		doc.addFont(theme.Fonts.Family, "", theme.Fonts.Regular)
+		if len(theme.Fonts.Bold) > 0 {
+			doc.addFont(theme.Fonts.Family, "B", theme.Fonts.Bold)
+		}
+		doc.family = theme.Fonts.Family
+	}
+
+	return doc
+}
+
+func (d *pdfDocument) addFont(family, style string, data []byte) {
+	d.AddUTF8FontFromBytes(family, style, data)
+	if d.faces[family] == nil {
+		d.faces[family] = make(map[string]bool)
+	}
+	d.faces[family][style] = true
+}
+
+// indent moves to the left margin plus offset millimetres
+func (d *pdfDocument) indent(offset float64) {
+	d.SetX(d.geometry.left() + offset)
+}
+
+// hline draws a horizontal rule across the content area at y
+func (d *pdfDocument) hline(y float64) {
+	d.Line(d.geometry.left(), y, d.geometry.right(), y)
+}
+
+// setTextColorHex sets the text colour from a "#RRGGBB" value
+func (d *pdfDocument) setTextColorHex(hex string) {
+	r, g, b := hexToRGB(hex)
+	d.SetTextColor(r, g, b)
+}
+
+// setDrawColorHex sets the line colour from a "#RRGGBB" value
+func (d *pdfDocument) setDrawColorHex(hex string) {
+	r, g, b := hexToRGB(hex)
+	d.SetDrawColor(r, g, b)
+}
+
+// hexToRGB converts "#RRGGBB" to its components, returning black for
+// malformed input
+func hexToRGB(hex string) (int, int, int) {
+	value, err := strconv.ParseUint(strings.TrimPrefix(hex, "#"), 16, 32)
+	if err != nil || len(hex) != 7 {
+		return 0, 0, 0
+	}
+	return int(value >> 16 & 0xFF), int(value >> 8 & 0xFF), int(value & 0xFF)
+}
+
+// useFont selects a style and size of the document font family
+func (d *pdfDocument) useFont(style string, size float64) {
+	d.style = style
+	d.size = size
+	d.applyFont(d.family)
+}
+
+// preservingFont runs fn and restores the font selection afterwards, for
+// page callbacks that fire in the middle of other output
+func (d *pdfDocument) preservingFont(fn func()) {
+	style, size, current := d.style, d.size, d.current
+	fn()
+	d.style, d.size = style, size
+	if size > 0 {
+		d.applyFont(current)
+	}
+}
+
+// applyFont activates a family with the current style, falling back to the
+// regular face when the family has no matching style
+func (d *pdfDocument) applyFont(family string) {
+	style := d.style
+	if !d.faces[family][style] {
+		style = ""
+	}
+	d.current = family
+	d.SetFont(family, style, d.size)
+}
+
+// prepareText picks a font able to display txt and converts it to visual
+// order. It reports whether the text reads right-to-left.
+func (d *pdfDocument) prepareText(txt string) (string, bool) {
+	family := d.family
+	if containsDevanagari(txt) && len(d.faces[devanagariFontFamily]) > 0 {
+		family = devanagariFontFamily
+	}
+	if family != d.current {
+		d.applyFont(family)
+	}
+
+	txt = reorderDevanagari(txt)
+	if !containsRTL(txt) {
+		return txt, false
+	}
+	return visualOrder(shapeArabic(txt))
+}
+
+// restoreFont switches back to the document family after fallback output
+func (d *pdfDocument) restoreFont() {
+	if d.current != d.family {
+		d.applyFont(d.family)
+	}
+}
+
+// CellFormat shadows gofpdf's CellFormat with script-aware output
+func (d *pdfDocument) CellFormat(w, h float64, txtStr, borderStr string, ln int, alignStr string, fill bool, link int, linkStr string) {
+	visual, rtl := d.prepareText(txtStr)
+	if rtl && (alignStr == "" || strings.Contains(alignStr, "L")) {
+		alignStr = strings.Replace(alignStr, "L", "", 1) + "R"
+	}
+	d.Fpdf.CellFormat(w, h, visual, borderStr, ln, alignStr, fill, link, linkStr)
+	d.restoreFont()
+}
+
+// Cell shadows gofpdf's Cell with script-aware output
+func (d *pdfDocument) Cell(w, h float64, txtStr string) {
+	d.CellFormat(w, h, txtStr, "", 0, "L", false, 0, "")
+}
+
+// MultiCell shadows gofpdf's MultiCell. Right-to-left text is wrapped in
+// logical order first and each line is then reordered and right aligned.
+func (d *pdfDocument) MultiCell(w, h float64, txtStr, borderStr, alignStr string, fill bool) {
+	if !containsRTL(txtStr) {
+		visual, _ := d.prepareText(txtStr)
+		d.Fpdf.MultiCell(w, h, visual, borderStr, alignStr, fill)
+		d.restoreFont()
+		return
+	}
+
+	if w == 0 {
+		pageWidth, _ := d.GetPageSize()
+		_, _, right, _ := d.GetMargins()
+		w = pageWidth - right - d.GetX()
+	}
+
+	x := d.GetX()
+	d.prepareText(txtStr)
+	for _, line := range d.SplitText(txtStr, w) {
+		visual, _ := d.prepareText(line)
+		d.SetX(x)
+		d.Fpdf.CellFormat(w, h, visual, borderStr, 2, "R", fill, 0, "")
+	}
+	d.restoreFont()
+	left, _, _, _ := d.GetMargins()
+	d.SetX(left)
+}
+
+func isRTLRune(r rune) bool {
+	return unicode.In(r, unicode.Hebrew, unicode.Arabic, unicode.Syriac, unicode.Thaana, unicode.Nko)
+}
+
+func containsRTL(txt string) bool {
+	for _, r := range txt {
+		if isRTLRune(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsDevanagari(txt string) bool {
+	for _, r := range txt {
+		if unicode.Is(unicode.Devanagari, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// visualOrder applies a simplified bidi algorithm: runs of right-to-left
+// characters are reversed, and when the first strong character is
+// right-to-left the order of the runs is reversed as well. Neutral characters
+// stay with the run they follow.
+func visualOrder(txt string) (string, bool) {
+	type run struct {
+		rtl   bool
+		runes []rune
+	}
+
+	var runs []run
+	baseRTL, baseKnown := false, false
+	for _, r := range txt {
+		strong := unicode.IsLetter(r) || unicode.IsDigit(r)
+		rtl := isRTLRune(r)
+		if strong && !baseKnown {
+			baseRTL, baseKnown = rtl, true
+		}
+		if len(runs) == 0 || (strong && runs[len(runs)-1].rtl != rtl) {
+			runs = append(runs, run{rtl: rtl && strong})
+		}
+		runs[len(runs)-1].runes = append(runs[len(runs)-1].runes, r)
+	}
+
+	for i := range runs {
+		if runs[i].rtl {
+			reverseRunes(runs[i].runes)
+		}
+	}
+	if baseRTL {
+		for i, j := 0, len(runs)-1; i < j; i, j = i+1, j-1 {
+			runs[i], runs[j] = runs[j], runs[i]
+		}
+	}
+
+	var sb strings.Builder
+	for _, r := range runs {
+		sb.WriteString(string(r.runes))
+	}
+	return strings.TrimSpace(sb.String()), baseRTL
+}
+
+func reverseRunes(runes []rune) {
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+}
+
+const (
+	devanagariVowelSignI = 'ि'
+	devanagariVirama     = '्'
+)
+
+func isDevanagariConsonant(r rune) bool {
+	return (r >= 'क' && r <= 'ह') || (r >= 'क़' && r <= 'य़')
+}
+
+// reorderDevanagari moves the short-i vowel sign in front of the consonant
+// cluster it follows. Without a shaping engine this is the reordering needed
+// for most place names to read correctly; conjunct ligatures are not formed.
+func reorderDevanagari(txt string) string {
+	if !strings.ContainsRune(txt, devanagariVowelSignI) {
+		return txt
+	}
+
+	runes := []rune(txt)
+	for i := 1; i < len(runes); i++ {
+		if runes[i] != devanagariVowelSignI || !isDevanagariConsonant(runes[i-1]) {
+			continue
+		}
+		start := i - 1
+		for start >= 2 && runes[start-1] == devanagariVirama && isDevanagariConsonant(runes[start-2]) {
+			start -= 2
+		}
+		copy(runes[start+1:i+1], runes[start:i])
+		runes[start] = devanagariVowelSignI
+	}
+	return string(runes)
+}
+
+// arabicForms holds the isolated, final, initial and medial presentation
+// forms of an Arabic letter. Letters that only join to the preceding letter
+// have no initial or medial form.
+type arabicForms [4]rune
+
+const (
+	arabicIsolated = iota
+	arabicFinal
+	arabicInitial
+	arabicMedial
+)
+
+const (
+	arabicTatweel = '\u0640'
+	arabicLam     = '\u0644'
+)
+
+var arabicLetterForms = map[rune]arabicForms{
+	'\u0621': {0xFE80, 0, 0, 0},
+	'\u0622': {0xFE81, 0xFE82, 0, 0},
+	'\u0623': {0xFE83, 0xFE84, 0, 0},
+	'\u0624': {0xFE85, 0xFE86, 0, 0},
+	'\u0625': {0xFE87, 0xFE88, 0, 0},
+	'\u0626': {0xFE89, 0xFE8A, 0xFE8B, 0xFE8C},
+	'\u0627': {0xFE8D, 0xFE8E, 0, 0},
+	'\u0628': {0xFE8F, 0xFE90, 0xFE91, 0xFE92},
+	'\u0629': {0xFE93, 0xFE94, 0, 0},
+	'\u062A': {0xFE95, 0xFE96, 0xFE97, 0xFE98},
+	'\u062B': {0xFE99, 0xFE9A, 0xFE9B, 0xFE9C},
+	'\u062C': {0xFE9D, 0xFE9E, 0xFE9F, 0xFEA0},
+	'\u062D': {0xFEA1, 0xFEA2, 0xFEA3, 0xFEA4},
+	'\u062E': {0xFEA5, 0xFEA6, 0xFEA7, 0xFEA8},
+	'\u062F': {0xFEA9, 0xFEAA, 0, 0},
+	'\u0630': {0xFEAB, 0xFEAC, 0, 0},
+	'\u0631': {0xFEAD, 0xFEAE, 0, 0},
+	'\u0632': {0xFEAF, 0xFEB0, 0, 0},
+	'\u0633': {0xFEB1, 0xFEB2, 0xFEB3, 0xFEB4},
+	'\u0634': {0xFEB5, 0xFEB6, 0xFEB7, 0xFEB8},
+	'\u0635': {0xFEB9, 0xFEBA, 0xFEBB, 0xFEBC},
+	'\u0636': {0xFEBD, 0xFEBE, 0xFEBF, 0xFEC0},
+	'\u0637': {0xFEC1, 0xFEC2, 0xFEC3, 0xFEC4},
+	'\u0638': {0xFEC5, 0xFEC6, 0xFEC7, 0xFEC8},
+	'\u0639': {0xFEC9, 0xFECA, 0xFECB, 0xFECC},
+	'\u063A': {0xFECD, 0xFECE, 0xFECF, 0xFED0},
+	'\u0641': {0xFED1, 0xFED2, 0xFED3, 0xFED4},
+	'\u0642': {0xFED5, 0xFED6, 0xFED7, 0xFED8},
+	'\u0643': {0xFED9, 0xFEDA, 0xFEDB, 0xFEDC},
+	'\u0644': {0xFEDD, 0xFEDE, 0xFEDF, 0xFEE0},
+	'\u0645': {0xFEE1, 0xFEE2, 0xFEE3, 0xFEE4},
+	'\u0646': {0xFEE5, 0xFEE6, 0xFEE7, 0xFEE8},
+	'\u0647': {0xFEE9, 0xFEEA, 0xFEEB, 0xFEEC},
+	'\u0648': {0xFEED, 0xFEEE, 0, 0},
+	'\u0649': {0xFEEF, 0xFEF0, 0, 0},
+	'\u064A': {0xFEF1, 0xFEF2, 0xFEF3, 0xFEF4},
+}
+
+// arabicLamAlef holds the isolated and final ligatures of lam followed by
+// each form of alef
+var arabicLamAlef = map[rune][2]rune{
+	'\u0622': {0xFEF5, 0xFEF6},
+	'\u0623': {0xFEF7, 0xFEF8},
+	'\u0625': {0xFEF9, 0xFEFA},
+	'\u0627': {0xFEFB, 0xFEFC},
+}
+
+// isArabicTransparent reports whether r is a combining mark that does not
+// interrupt the joining of the letters around it
+func isArabicTransparent(r rune) bool {
+	return (r >= '\u064B' && r <= '\u065F') || r == '\u0670'
+}
+
+// joinsForward reports whether r connects to the letter after it
+func joinsForward(r rune) bool {
+	return r == arabicTatweel || arabicLetterForms[r][arabicInitial] != 0
+}
+
+// joinsBackward reports whether r connects to the letter before it
+func joinsBackward(r rune) bool {
+	return r == arabicTatweel || arabicLetterForms[r][arabicFinal] != 0
+}
+
+// shapeArabic replaces Arabic letters with the presentation form matching
+// how they join their neighbours, and lam followed by alef with its
+// ligature. The bundled fonts have no shaping tables that gofpdf could apply,
+// so without this every letter would print in its isolated form. Text stays
+// in logical order.
+func shapeArabic(txt string) string {
+	runes := []rune(txt)
+	neighbour := func(i, step int) rune {
+		for i += step; i >= 0 && i < len(runes); i += step {
+			if !isArabicTransparent(runes[i]) {
+				return runes[i]
+			}
+		}
+		return 0
+	}
+
+	shaped := make([]rune, 0, len(runes))
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		forms, ok := arabicLetterForms[r]
+		if !ok {
+			shaped = append(shaped, r)
+			continue
+		}
+		joinedBefore := joinsForward(neighbour(i, -1))
+
+		if r == arabicLam && i+1 < len(runes) {
+			if ligature, ok := arabicLamAlef[runes[i+1]]; ok {
+				if joinedBefore {
+					shaped = append(shaped, ligature[1])
+				} else {
+					shaped = append(shaped, ligature[0])
+				}
+				i++
+				continue
+			}
+		}
+
+		form := arabicIsolated
+		joinedAfter := forms[arabicInitial] != 0 && joinsBackward(neighbour(i, 1))
+		switch {
+		case joinedBefore && joinedAfter:
+			form = arabicMedial
+		case joinedBefore && forms[arabicFinal] != 0:
+			form = arabicFinal
+		case joinedAfter:
+			form = arabicInitial
+		}
+		shaped = append(shaped, forms[form])
+	}
+	return string(shaped)
+}
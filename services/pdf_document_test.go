@@ -0,0 +1,61 @@
+package services
+
+import "testing"
+
+func TestLoadBundledFonts(t *testing.T) {
+	fonts, err := loadBundledFonts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fonts) != len(bundledFontFaces) {
+		t.Fatalf("loaded %d fonts, want %d", len(fonts), len(bundledFontFaces))
+	}
+	for _, font := range fonts {
+		if len(font.data) == 0 {
+			t.Errorf("font %s is empty", font.file)
+		}
+	}
+}
+
+func TestReorderDevanagari(t *testing.T) {
+	for _, tc := range []struct{ name, in, want string }{
+		{"latin", "Delhi", "Delhi"},
+		{"no short i", "आगरा", "आगरा"},
+		{"short i after a consonant", "दिल्ली", "िदल्ली"},
+		{"short i after a conjunct", "स्थिति", "िस्थित"},
+	} {
+		if got := reorderDevanagari(tc.in); got != tc.want {
+			t.Errorf("%s: reorderDevanagari(%q) = %q, want %q", tc.name, tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestVisualOrder(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+		rtl      bool
+	}{
+		{"Hello שלום", "Hello םולש", false},
+		{"שלום World", "World םולש", true},
+	} {
+		got, rtl := visualOrder(tc.in)
+		if got != tc.want || rtl != tc.rtl {
+			t.Errorf("visualOrder(%q) = %q, %v; want %q, %v", tc.in, got, rtl, tc.want, tc.rtl)
+		}
+	}
+}
+
+func TestShapeArabic(t *testing.T) {
+	for _, tc := range []struct{ name, in, want string }{
+		{"latin", "Dubai", "Dubai"},
+		{"initial, final and isolated", "باب", "ﺑﺎﺏ"},
+		{"lam alef ligature", "سلام", "ﺳﻼﻡ"},
+		{"marks do not break joining", "بَب", "ﺑَﺐ"},
+		{"medial", "ببب", "ﺑﺒﺐ"},
+		{"words join separately", "بب بب", "ﺑﺐ ﺑﺐ"},
+	} {
+		if got := shapeArabic(tc.in); got != tc.want {
+			t.Errorf("%s: shapeArabic(%q) = %+q, want %+q", tc.name, tc.in, got, tc.want)
+		}
+	}
+}
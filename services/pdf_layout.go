@@ -0,0 +1,148 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"vigovia-task/utils"
+)
+
+// Layout variants for PDF exports
+const (
+	LayoutDetailed = "detailed"
+	LayoutSummary  = "summary"
+)
+
+// Page orientations for PDF exports
+const (
+	OrientationPortrait  = "portrait"
+	OrientationLandscape = "landscape"
+)
+
+const (
+	defaultPageSize = "A4"
+	defaultMargin   = 15.0
+	minMargin       = 5.0
+	maxMargin       = 40.0
+)
+
+// pageSizes maps accepted paper size names to their gofpdf names
+var pageSizes = map[string]string{
+	"a3":     "A3",
+	"a4":     "A4",
+	"a5":     "A5",
+	"letter": "Letter",
+	"legal":  "Legal",
+}
+
+// PageLayout describes the paper and the level of detail of a PDF export
+type PageLayout struct {
+	PageSize    string  `json:"page_size"`
+	Orientation string  `json:"orientation"`
+	Margin      float64 `json:"margin"`
+	Variant     string  `json:"layout"`
+}
+
+// DefaultPageLayout returns A4 portrait with 15mm margins and the detailed layout
+func DefaultPageLayout() PageLayout {
+	return PageLayout{
+		PageSize:    defaultPageSize,
+		Orientation: OrientationPortrait,
+		Margin:      defaultMargin,
+		Variant:     LayoutDetailed,
+	}
+}
+
+// NewPageLayout validates raw request values; empty values keep the defaults
+func NewPageLayout(pageSize, orientation, margin, variant string) (PageLayout, error) {
+	layout := DefaultPageLayout()
+
+	if strings.TrimSpace(pageSize) != "" {
+		name, ok := pageSizes[strings.ToLower(strings.TrimSpace(pageSize))]
+		if !ok {
+			return layout, utils.NewValidationError("page_size must be one of A3, A4, A5, Letter or Legal")
+		}
+		layout.PageSize = name
+	}
+
+	switch value := strings.ToLower(strings.TrimSpace(orientation)); value {
+	case "":
+	case OrientationPortrait, OrientationLandscape:
+		layout.Orientation = value
+	default:
+		return layout, utils.NewValidationError("orientation must be portrait or landscape")
+	}
+
+	if strings.TrimSpace(margin) != "" {
+		value, err := strconv.ParseFloat(strings.TrimSpace(margin), 64)
+		if err != nil || value < minMargin || value > maxMargin {
+			return layout, utils.NewValidationError(fmt.Sprintf("margin must be a number of millimetres between %.0f and %.0f", minMargin, maxMargin))
+		}
+		layout.Margin = value
+	}
+
+	switch value := strings.ToLower(strings.TrimSpace(variant)); value {
+	case "":
+	case LayoutDetailed, LayoutSummary:
+		layout.Variant = value
+	default:
+		return layout, utils.NewValidationError("layout must be detailed or summary")
+	}
+
+	return layout, nil
+}
+
+// withDefaults fills unset fields of a zero or partial layout
+func (l PageLayout) withDefaults() PageLayout {
+	defaults := DefaultPageLayout()
+	if l.PageSize == "" {
+		l.PageSize = defaults.PageSize
+	}
+	if l.Orientation == "" {
+		l.Orientation = defaults.Orientation
+	}
+	if l.Margin == 0 {
+		l.Margin = defaults.Margin
+	}
+	if l.Variant == "" {
+		l.Variant = defaults.Variant
+	}
+	return l
+}
+
+// gofpdfOrientation returns the orientation code expected by gofpdf
+func (l PageLayout) gofpdfOrientation() string {
+	if l.Orientation == OrientationLandscape {
+		return "L"
+	}
+	return "P"
+}
+
+// Horizontal indents, relative to the left margin, for nested content
+const (
+	indentNone    = 0.0
+	indentSession = 3.0
+	indentItem    = 5.0
+	indentDetail  = 10.0
+)
+
+// pageGeometry derives every drawing coordinate from the page size and margin.
+// The running header sits above the top margin and the footer below the
+// bottom margin, so body content never overlaps them.
+type pageGeometry struct {
+	pageWidth  float64
+	pageHeight float64
+	margin     float64
+}
+
+func (g pageGeometry) left() float64           { return g.margin }
+func (g pageGeometry) right() float64          { return g.pageWidth - g.margin }
+func (g pageGeometry) contentWidth() float64   { return g.pageWidth - 2*g.margin }
+func (g pageGeometry) top() float64            { return g.margin + 7 }
+func (g pageGeometry) bottom() float64         { return g.margin + 15 }
+func (g pageGeometry) titleY() float64         { return g.margin + 3 }
+func (g pageGeometry) runningHeaderY() float64 { return math.Max(g.margin-5, 3) }
+func (g pageGeometry) footerDividerY() float64 { return g.pageHeight - g.margin - 12 }
+func (g pageGeometry) footerTextY() float64    { return g.pageHeight - g.margin - 10 }
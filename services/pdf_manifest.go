@@ -0,0 +1,168 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"vigovia-task/models"
+)
+
+// manifestColumns are the passenger table columns as fractions of the content width
+var manifestColumns = []struct {
+	title string
+	width float64
+}{
+	{"No.", 0.07},
+	{"Name", 0.29},
+	{"Date of Birth", 0.17},
+	{"Nationality", 0.12},
+	{"Passport", 0.17},
+	{"Expiry", 0.18},
+}
+
+// addPassengerManifest prints the travellers on a page of their own together
+// with their special requirements, emergency contacts and assignments
+func (ps *PDFService) addPassengerManifest(pdf *pdfDocument, itinerary *models.Itinerary) {
+	pdf.continuation = ""
+	pdf.AddPage()
+	ps.addSectionHeader(pdf, "Passenger Manifest")
+
+	width := pdf.geometry.contentWidth()
+	pdf.useFont("B", 9)
+	pdf.SetTextColor(60, 60, 60)
+	pdf.indent(indentNone)
+	for _, column := range manifestColumns {
+		pdf.CellFormat(width*column.width, 6, column.title, "B", 0, "L", false, 0, "")
+	}
+	pdf.Ln(6)
+
+	pdf.useFont("", 9)
+	pdf.SetTextColor(40, 40, 40)
+	for idx, traveller := range itinerary.Travellers {
+		ps.ensureSpace(pdf, 6)
+		values := []string{
+			fmt.Sprintf("%d", idx+1),
+			traveller.FullName(),
+			formatDate(traveller.DateOfBirth),
+			traveller.Nationality,
+			traveller.PassportNumber,
+			formatDate(traveller.PassportExpiry),
+		}
+		pdf.indent(indentNone)
+		for i, column := range manifestColumns {
+			pdf.CellFormat(width*column.width, 6, values[i], "", 0, "L", false, 0, "")
+		}
+		pdf.Ln(6)
+	}
+	pdf.Ln(4)
+
+	ps.addTravelChecklist(pdf, itinerary)
+
+	var requirements []string
+	for _, traveller := range itinerary.Travellers {
+		var needs []string
+		if traveller.DietaryRequirements != "" {
+			needs = append(needs, "Dietary: "+traveller.DietaryRequirements)
+		}
+		if traveller.AccessibilityNeeds != "" {
+			needs = append(needs, "Accessibility: "+traveller.AccessibilityNeeds)
+		}
+		if len(needs) > 0 {
+			requirements = append(requirements, fmt.Sprintf("%s - %s", traveller.FullName(), strings.Join(needs, "; ")))
+		}
+	}
+	ps.addManifestList(pdf, "Special Requirements", requirements)
+
+	contacts := make([]string, 0, len(itinerary.Travellers))
+	for _, traveller := range itinerary.Travellers {
+		contact := traveller.EmergencyContact
+		label := contact.Name
+		if contact.Relationship != "" {
+			label += " (" + contact.Relationship + ")"
+		}
+		contacts = append(contacts, fmt.Sprintf("%s - %s, %s", traveller.FullName(), label, contact.Phone))
+	}
+	ps.addManifestList(pdf, "Emergency Contacts", contacts)
+
+	names := make(map[string]string, len(itinerary.Travellers))
+	for _, traveller := range itinerary.Travellers {
+		names[traveller.ID] = traveller.FullName()
+	}
+
+	var flights []string
+	for idx, flight := range itinerary.Flights {
+		if len(flight.TravellerIDs) > 0 {
+			flights = append(flights, fmt.Sprintf("%s: %s", flightTitle(idx, flight), travellerNames(names, flight.TravellerIDs)))
+		}
+	}
+	ps.addManifestList(pdf, "Flight Assignments", flights)
+
+	var rooms []string
+	for _, hotel := range itinerary.Hotels {
+		for _, room := range hotel.Rooms {
+			rooms = append(rooms, fmt.Sprintf("%s, %s: %s", hotel.Name, room.Label, travellerNames(names, room.TravellerIDs)))
+		}
+	}
+	ps.addManifestList(pdf, "Room Assignments", rooms)
+}
+
+func (ps *PDFService) addManifestList(pdf *pdfDocument, title string, lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+
+	ps.ensureSpace(pdf, 20)
+	pdf.useFont("B", 11)
+	pdf.SetTextColor(70, 70, 70)
+	pdf.indent(indentNone)
+	pdf.CellFormat(0, 6, title, "", 1, "L", false, 0, "")
+
+	pdf.useFont("", 9)
+	pdf.SetTextColor(60, 60, 60)
+	for _, line := range lines {
+		pdf.indent(indentItem)
+		pdf.MultiCell(0, 5, "- "+line, "", "L", false)
+	}
+	pdf.Ln(3)
+}
+
+// addTravelChecklist prints the passport and visa warnings as tick boxes for
+// the agent to work through before departure
+func (ps *PDFService) addTravelChecklist(pdf *pdfDocument, itinerary *models.Itinerary) {
+	if len(itinerary.Warnings) == 0 {
+		return
+	}
+
+	ps.ensureSpace(pdf, 20)
+	pdf.useFont("B", 11)
+	pdf.SetTextColor(70, 70, 70)
+	pdf.indent(indentNone)
+	pdf.CellFormat(0, 6, "Travel Document Checklist", "", 1, "L", false, 0, "")
+
+	pdf.useFont("", 9)
+	pdf.SetDrawColor(120, 120, 120)
+	for _, warning := range itinerary.Warnings {
+		ps.ensureSpace(pdf, 5)
+		if warning.Severity == models.WarningSeverityError {
+			pdf.SetTextColor(192, 57, 43)
+		} else {
+			pdf.SetTextColor(60, 60, 60)
+		}
+		pdf.Rect(pdf.geometry.left()+indentItem, pdf.GetY()+1, 3, 3, "D")
+		pdf.indent(indentDetail)
+		pdf.MultiCell(0, 5, warning.Message, "", "L", false)
+	}
+	pdf.Ln(3)
+}
+
+// travellerNames lists the names of the referenced travellers
+func travellerNames(names map[string]string, ids []string) string {
+	if len(ids) == 0 {
+		return "-"
+	}
+	listed := make([]string, 0, len(ids))
+	for _, id := range ids {
+		listed = append(listed, names[id])
+	}
+	return strings.Join(listed, ", ")
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+
+	"vigovia-task/models"
+)
+
+// pdfPagination records where content landed during a rendering pass. The
+// first pass of GeneratePDF only measures; its pagination is handed to the
+// second pass so page totals and table of contents entries are known up front.
+type pdfPagination struct {
+	totalPages int
+	dayPages   []int
+}
+
+// pagination returns the page layout produced by this document
+func (d *pdfDocument) pagination() *pdfPagination {
+	pages := make([]int, len(d.dayPages))
+	copy(pages, d.dayPages)
+	return &pdfPagination{totalPages: d.PageCount(), dayPages: pages}
+}
+
+// installPageCallbacks registers the running header and footer drawn on
+// every page
+func (ps *PDFService) installPageCallbacks(pdf *pdfDocument, itinerary *models.Itinerary) {
+	pdf.SetHeaderFuncMode(func() {
+		pdf.preservingFont(func() { ps.drawRunningHeader(pdf, itinerary) })
+	}, false)
+	pdf.SetFooterFunc(func() {
+		pdf.preservingFont(func() { ps.drawFooter(pdf) })
+	})
+}
+
+// drawRunningHeader prints the itinerary title on continuation pages and
+// repeats the heading of the section or day that spans the page break
+func (ps *PDFService) drawRunningHeader(pdf *pdfDocument, itinerary *models.Itinerary) {
+	if pdf.PageNo() == 1 {
+		return
+	}
+
+	geometry := pdf.geometry
+
+	pdf.SetXY(geometry.left(), geometry.runningHeaderY())
+	pdf.useFont("", 9)
+	pdf.SetTextColor(150, 150, 150)
+	pdf.CellFormat(0, 5, itinerary.Title, "", 1, "L", false, 0, "")
+	pdf.setDrawColorHex(pdf.theme.Colors.Divider)
+	pdf.hline(pdf.GetY() + 1)
+
+	pdf.SetXY(geometry.left(), geometry.top())
+	if pdf.continuation != "" {
+		pdf.useFont("B", 11)
+		pdf.setTextColorHex(pdf.theme.Colors.Primary)
+		pdf.CellFormat(0, 7, pdf.continuation, "", 1, "L", false, 0, "")
+		pdf.Ln(2)
+	}
+}
+
+// drawFooter prints the footer text and "Page X of Y" below the content area
+func (ps *PDFService) drawFooter(pdf *pdfDocument) {
+	geometry := pdf.geometry
+
+	pdf.SetDrawColor(220, 220, 220)
+	pdf.hline(geometry.footerDividerY())
+
+	total := "?"
+	if pdf.previous != nil {
+		total = strconv.Itoa(pdf.previous.totalPages)
+	}
+
+	pdf.SetXY(geometry.left(), geometry.footerTextY())
+	pdf.useFont("", 9)
+	pdf.SetTextColor(150, 150, 150)
+	footer := pdf.theme.FooterText
+	if pdf.internal {
+		footer = operationsCopyLabel
+	}
+	pdf.CellFormat(0, 10, footer, "", 0, "L", false, 0, "")
+	pdf.indent(indentNone)
+	pdf.CellFormat(0, 10, fmt.Sprintf("Page %d of %s", pdf.PageNo(), total), "", 0, "R", false, 0, "")
+}
+
+// ensureSpace starts a new page when fewer than height millimetres remain
+// above the bottom margin, keeping a block together with its heading
+func (ps *PDFService) ensureSpace(pdf *pdfDocument, height float64) {
+	if pdf.GetY()+height > pdf.geometry.pageHeight-pdf.geometry.bottom() {
+		pdf.AddPage()
+	}
+}
+
+// addTableOfContents lists each day with its page number and a link to it
+func (ps *PDFService) addTableOfContents(pdf *pdfDocument, days []models.DayPlan) {
+	ps.ensureSpace(pdf, 30)
+	ps.addSectionHeader(pdf, "Contents")
+
+	pdf.dayLinks = make([]int, len(days))
+	for idx, day := range days {
+		pdf.dayLinks[idx] = pdf.AddLink()
+
+		page := ""
+		if pdf.previous != nil && idx < len(pdf.previous.dayPages) {
+			page = strconv.Itoa(pdf.previous.dayPages[idx])
+		}
+
+		pdf.useFont("", 10)
+		pdf.setTextColorHex(pdf.theme.Colors.Accent)
+		pdf.indent(indentItem)
+		pdf.CellFormat(pdf.geometry.contentWidth()-30, 6, fmt.Sprintf("Day %d: %s", day.DayNumber, day.Title), "", 0, "L", false, pdf.dayLinks[idx], "")
+		pdf.SetTextColor(90, 90, 90)
+		pdf.CellFormat(0, 6, page, "", 1, "R", false, pdf.dayLinks[idx], "")
+	}
+
+	pdf.Ln(6)
+}
+
+// markDay records the page a day starts on and points its contents link there
+func (ps *PDFService) markDay(pdf *pdfDocument, idx int) {
+	for len(pdf.dayPages) <= idx {
+		pdf.dayPages = append(pdf.dayPages, 0)
+	}
+	pdf.dayPages[idx] = pdf.PageNo()
+	if idx < len(pdf.dayLinks) {
+		pdf.SetLink(pdf.dayLinks[idx], pdf.GetY(), -1)
+	}
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"vigovia-task/models"
+)
+
+// longItinerary builds an itinerary whose days span several pages
+func longItinerary(days int) *models.Itinerary {
+	start := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	itinerary := &models.Itinerary{
+		ID:        "trip-1",
+		Title:     "Grand Tour of India",
+		StartDate: start,
+		EndDate:   start.AddDate(0, 0, days-1),
+		UpdatedAt: start,
+	}
+	for i := 0; i < days; i++ {
+		day := models.DayPlan{DayNumber: i + 1, Date: start.AddDate(0, 0, i), Title: fmt.Sprintf("City %d", i+1)}
+		for _, period := range periodOrder {
+			day.Activities = append(day.Activities, models.Activity{
+				Title:       "Heritage walk",
+				Description: strings.Repeat("Old town lanes, markets and temples. ", 8),
+				Period:      period,
+			})
+		}
+		itinerary.Days = append(itinerary.Days, day)
+	}
+	return itinerary
+}
+
+func TestPDFPassesAgreeOnPagination(t *testing.T) {
+	ps, err := NewPDFService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	itinerary := longItinerary(10)
+
+	measured, err := ps.renderDocument(itinerary, RenderOptions{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := measured.pagination()
+	final, err := ps.renderDocument(itinerary, RenderOptions{}, first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second := final.pagination()
+
+	if first.totalPages < 3 {
+		t.Fatalf("itinerary fits on %d pages, want a multi-page document", first.totalPages)
+	}
+	if second.totalPages != first.totalPages {
+		t.Errorf("final pass has %d pages, measuring pass %d", second.totalPages, first.totalPages)
+	}
+	if len(second.dayPages) != len(itinerary.Days) {
+		t.Fatalf("recorded %d day pages, want %d", len(second.dayPages), len(itinerary.Days))
+	}
+	for i, page := range second.dayPages {
+		if page != first.dayPages[i] {
+			t.Errorf("day %d starts on page %d, table of contents says %d", i+1, page, first.dayPages[i])
+		}
+		if page < 1 || page > second.totalPages || (i > 0 && page < second.dayPages[i-1]) {
+			t.Errorf("day %d starts on page %d of %d after day %d on page %d", i+1, page, second.totalPages, i, second.dayPages[max(i-1, 0)])
+		}
+	}
+}
@@ -3,10 +3,7 @@ package services
 import (
 	"bytes"
 	"fmt"
-	"os"
-	"path/filepath"
 	"regexp"
-	"sort"
 	"strings"
 	"time"
 
@@ -16,7 +13,9 @@ import (
 )
 
 // PDFService handles PDF generation for itineraries
-type PDFService struct{}
+type PDFService struct {
+	fonts []loadedFont
+}
 
 var (
 	spacePattern   = regexp.MustCompile(`\s+`)
@@ -29,24 +28,106 @@ var (
 	}
 )
 
-// NewPDFService creates a new instance of PDFService
-func NewPDFService() *PDFService {
-	return &PDFService{}
+// NewPDFService creates a new instance of PDFService. It fails when a
+// bundled font cannot be read.
+func NewPDFService() (*PDFService, error) {
+	fonts, err := loadBundledFonts()
+	if err != nil {
+		return nil, err
+	}
+	return &PDFService{
+		fonts: fonts,
+	}, nil
+}
+
+// Render implements Renderer for PDF output
+func (ps *PDFService) Render(itinerary *models.Itinerary, opts RenderOptions) ([]byte, error) {
+	return ps.GeneratePDF(itinerary, opts)
+}
+
+// ContentType implements Renderer
+func (ps *PDFService) ContentType() string {
+	return "application/pdf"
+}
+
+// FileExtension implements Renderer
+func (ps *PDFService) FileExtension() string {
+	return ".pdf"
 }
 
 // GeneratePDF generates a professional PDF document for an itinerary
-func (ps *PDFService) GeneratePDF(itinerary *models.Itinerary) ([]byte, error) {
-	pdf := gofpdf.New("P", "mm", "A4", "")
+func (ps *PDFService) GeneratePDF(itinerary *models.Itinerary, opts RenderOptions) ([]byte, error) {
+	// The first pass only measures where pages break so that the second can
+	// print "Page X of Y" and the table of contents page numbers
+	measured, err := ps.renderDocument(itinerary, opts, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	pdf, err := ps.renderDocument(itinerary, opts, measured.pagination())
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate PDF bytes
+	buf := new(bytes.Buffer)
+	if err := pdf.Output(buf); err != nil {
+		return nil, fmt.Errorf("failed to generate PDF: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// renderDocument lays out every section of the itinerary
+func (ps *PDFService) renderDocument(itinerary *models.Itinerary, opts RenderOptions, previous *pdfPagination) (*pdfDocument, error) {
+	itinerary = opts.content(itinerary)
+	pdf := newPDFDocument(opts.layout(), ps.fonts, opts.theme())
+	pdf.previous = previous
+	pdf.internal = opts.Internal
+	// Days dominate rendering time; the measuring pass covers the first half
+	// of the reported progress and the final pass the second
+	if opts.Progress != nil && len(itinerary.Days) > 0 {
+		pass := 0
+		if previous != nil {
+			pass = 1
+		}
+		total := len(itinerary.Days)
+		pdf.dayDone = func(done int) {
+			opts.Progress((pass*total + done) * 100 / (2 * total))
+		}
+	}
+	// Stamp the revision time instead of the wall clock so that exporting an
+	// unchanged itinerary yields identical bytes and a stable document key
+	pdf.SetCatalogSort(true)
+	pdf.SetCreationDate(itinerary.UpdatedAt)
+	pdf.SetModificationDate(itinerary.UpdatedAt)
+	ps.installPageCallbacks(pdf, itinerary)
 	pdf.AddPage()
-	pdf.SetLeftMargin(15)
-	pdf.SetRightMargin(15)
 
 	// Header with background color
 	ps.addHeader(pdf, itinerary)
 
+	if pdf.layout.Variant == LayoutSummary {
+		ps.addSummary(pdf, itinerary)
+		if err := pdf.Error(); err != nil {
+			return nil, fmt.Errorf("failed to generate PDF: %w", err)
+		}
+		return pdf, nil
+	}
+
 	// Metadata section
 	ps.addMetadataSection(pdf, itinerary)
 
+	if itinerary.InternalNotes != "" {
+		ps.addSectionHeader(pdf, "Internal Notes")
+		ps.addInternalNote(pdf, indentNone, itinerary.InternalNotes)
+		pdf.Ln(6)
+	}
+
+	if len(itinerary.Days) > 0 {
+		ps.addTableOfContents(pdf, itinerary.Days)
+	}
+
 	if len(itinerary.Hotels) > 0 {
 		ps.addHotelsSection(pdf, itinerary.Hotels)
 	}
@@ -69,9 +150,10 @@ func (ps *PDFService) GeneratePDF(itinerary *models.Itinerary) ([]byte, error) {
 		ps.addItineraryDetailsSection(pdf, itinerary.Days)
 	} else {
 		// If no days, add empty state message
-		pdf.SetFont("Arial", "I", 11)
+		pdf.useFont("I", 11)
 		pdf.SetTextColor(100, 100, 100)
 		pdf.Cell(0, 10, "No days planned yet")
+		pdf.Ln(10)
 	}
 
 	if len(itinerary.PaymentPlan) > 0 {
@@ -82,42 +164,68 @@ func (ps *PDFService) GeneratePDF(itinerary *models.Itinerary) ([]byte, error) {
 		ps.addInclusionsExclusionsSection(pdf, itinerary.Inclusions, itinerary.Exclusions)
 	}
 
-	// Footer
-	ps.addFooter(pdf)
+	if len(itinerary.Travellers) > 0 {
+		ps.addPassengerManifest(pdf, itinerary)
+	}
 
-	// Generate PDF bytes
-	buf := new(bytes.Buffer)
-	if err := pdf.Output(buf); err != nil {
-		return nil, fmt.Errorf("failed to generate PDF: %w", err)
+	if !pdf.theme.Contact.IsEmpty() {
+		ps.addContactSection(pdf, pdf.theme.Contact)
 	}
 
-	pdfBytes := buf.Bytes()
-	if err := ps.savePDFToOutput(pdfBytes, itinerary); err != nil {
-		return nil, fmt.Errorf("failed to save PDF: %w", err)
+	if pdf.theme.TermsAndConditions != "" {
+		ps.addTermsPage(pdf, pdf.theme.TermsAndConditions)
 	}
 
-	return pdfBytes, nil
+	if err := pdf.Error(); err != nil {
+		return nil, fmt.Errorf("failed to generate PDF: %w", err)
+	}
+
+	return pdf, nil
 }
 
 // addHeader adds a professional header to the PDF
-func (ps *PDFService) addHeader(pdf *gofpdf.Fpdf, itinerary *models.Itinerary) {
+func (ps *PDFService) addHeader(pdf *pdfDocument, itinerary *models.Itinerary) {
 	// Minimal header layout keeps document clean
-	pdf.SetXY(15, 18)
-	pdf.SetFont("Arial", "B", 19)
-	pdf.SetTextColor(34, 34, 34)
+	ps.addLogo(pdf)
+	pdf.SetXY(pdf.geometry.left(), pdf.geometry.titleY())
+	pdf.useFont("B", 19)
+	pdf.setTextColorHex(pdf.theme.Colors.Heading)
 	pdf.CellFormat(0, 9, "Itinerary Plan", "", 1, "L", false, 0, "")
 
-	pdf.SetFont("Arial", "", 12)
+	pdf.useFont("", 12)
 	pdf.SetTextColor(90, 90, 90)
 	pdf.CellFormat(0, 6, itinerary.Title, "", 1, "L", false, 0, "")
+	if pdf.internal {
+		pdf.useFont("B", 10)
+		pdf.SetTextColor(166, 94, 0)
+		pdf.CellFormat(0, 6, operationsCopyLabel, "", 1, "L", false, 0, "")
+	}
 	pdf.Ln(2)
 
 	ps.drawDivider(pdf)
 	pdf.Ln(8)
 }
 
+// addLogo places the branding logo in the top right corner of the page
+func (ps *PDFService) addLogo(pdf *pdfDocument) {
+	if len(pdf.theme.Logo) == 0 {
+		return
+	}
+
+	options := gofpdf.ImageOptions{ImageType: pdf.theme.LogoFormat, ReadDpi: true}
+	name := "logo-" + pdf.theme.ID
+	info := pdf.RegisterImageOptionsReader(name, options, bytes.NewReader(pdf.theme.Logo))
+	if info == nil || info.Height() == 0 {
+		return
+	}
+
+	height := 15.0
+	width := height * info.Width() / info.Height()
+	pdf.ImageOptions(name, pdf.geometry.right()-width, pdf.geometry.titleY()-6, width, height, false, options, 0, "")
+}
+
 // addMetadataSection adds trip information
-func (ps *PDFService) addMetadataSection(pdf *gofpdf.Fpdf, itinerary *models.Itinerary) {
+func (ps *PDFService) addMetadataSection(pdf *pdfDocument, itinerary *models.Itinerary) {
 	ps.addSectionHeader(pdf, "Trip Information")
 
 	ps.addLabelValue(pdf, "User ID", itinerary.UserID)
@@ -129,142 +237,88 @@ func (ps *PDFService) addMetadataSection(pdf *gofpdf.Fpdf, itinerary *models.Iti
 }
 
 // addDescriptionSection adds the itinerary description
-func (ps *PDFService) addDescriptionSection(pdf *gofpdf.Fpdf, description string) {
+func (ps *PDFService) addDescriptionSection(pdf *pdfDocument, description string) {
 	ps.addSectionHeader(pdf, "Overview")
 
 	pdf.SetTextColor(50, 50, 50)
-	pdf.SetFont("Arial", "", 11)
+	pdf.useFont("", 11)
 	pdf.MultiCell(0, 6, description, "", "L", false)
 	pdf.Ln(8)
 }
 
 // addItineraryDetailsSection adds detailed day-by-day itinerary
-func (ps *PDFService) addItineraryDetailsSection(pdf *gofpdf.Fpdf, days []models.DayPlan) {
+func (ps *PDFService) addItineraryDetailsSection(pdf *pdfDocument, days []models.DayPlan) {
+	ps.ensureSpace(pdf, 60)
 	ps.addSectionHeader(pdf, "Day-by-Day Itinerary")
 
-	for _, day := range days {
+	for idx, day := range days {
+		pdf.continuation = "Day-by-Day Itinerary (continued)"
+		// Keep the day title together with its first activity
+		ps.ensureSpace(pdf, 45)
+		ps.markDay(pdf, idx)
 		ps.addDaySection(pdf, day)
+		if pdf.dayDone != nil {
+			pdf.dayDone(idx + 1)
+		}
 	}
+	pdf.continuation = ""
 }
 
 // addDaySection adds a single day's information
-func (ps *PDFService) addDaySection(pdf *gofpdf.Fpdf, day models.DayPlan) {
+func (ps *PDFService) addDaySection(pdf *pdfDocument, day models.DayPlan) {
 	// Day header
-	pdf.SetFont("Arial", "B", 12)
-	pdf.SetTextColor(41, 128, 185)
-	pdf.SetX(15)
+	pdf.continuation = fmt.Sprintf("Day %d: %s (continued)", day.DayNumber, day.Title)
+	pdf.useFont("B", 12)
+	pdf.setTextColorHex(pdf.theme.Colors.Primary)
+	pdf.indent(indentNone)
 	pdf.CellFormat(0, 7, fmt.Sprintf("Day %d: %s", day.DayNumber, day.Title), "", 1, "L", false, 0, "")
 
 	// Date line
-	pdf.SetFont("Arial", "", 10)
+	pdf.useFont("", 10)
 	pdf.SetTextColor(110, 110, 110)
-	pdf.SetX(15)
+	pdf.indent(indentNone)
 	pdf.CellFormat(0, 5, fmt.Sprintf("Date: %s", formatDate(day.Date)), "", 1, "L", false, 0, "")
+	ps.addInternalNote(pdf, indentNone, day.InternalNotes)
 	pdf.Ln(1)
 
 	// Activities
 	if len(day.Activities) > 0 {
-		grouped := make(map[string][]models.Activity)
-		for _, activity := range day.Activities {
-			periodKey := strings.ToLower(activity.Period)
-			grouped[periodKey] = append(grouped[periodKey], activity)
-		}
-
-		for _, period := range periodOrder {
-			activities := grouped[period]
-			if len(activities) == 0 {
-				continue
-			}
-
-			label := periodDisplay[period]
-			if label == "" {
-				label = toTitleCase(period)
-			}
-
-			pdf.SetFont("Arial", "B", 11)
-			pdf.SetTextColor(70, 70, 70)
-			pdf.SetX(18)
-			pdf.CellFormat(0, 6, label+" Session", "", 1, "L", false, 0, "")
-			pdf.Ln(1)
-
-			for idx, activity := range activities {
-				pdf.SetFont("Arial", "B", 10)
-				pdf.SetTextColor(52, 152, 219)
-				pdf.SetX(20)
-				pdf.CellFormat(28, 5, activity.Time, "", 0, "L", false, 0, "")
-
-				pdf.SetFont("Arial", "B", 10)
-				pdf.SetTextColor(40, 40, 40)
-				pdf.CellFormat(0, 5, activity.Title, "", 1, "L", false, 0, "")
-
-				pdf.SetFont("Arial", "", 10)
-				pdf.SetTextColor(90, 90, 90)
-				if activity.Description != "" {
-					pdf.SetX(25)
-					pdf.MultiCell(0, 5, "Description: "+activity.Description, "", "L", false)
-				}
-				if activity.Location != "" {
-					pdf.SetX(25)
-					pdf.MultiCell(0, 5, "Location: "+activity.Location, "", "L", false)
-				}
-				if activity.Duration != "" {
-					pdf.SetX(25)
-					pdf.MultiCell(0, 5, "Duration: "+activity.Duration, "", "L", false)
-				}
-
-				if idx < len(activities)-1 {
-					pdf.Ln(2)
-				}
-			}
-
-			pdf.Ln(3)
-		}
-
-		for period, activities := range grouped {
-			skip := false
-			for _, ordered := range periodOrder {
-				if period == ordered {
-					skip = true
-					break
-				}
-			}
-			if skip || len(activities) == 0 {
-				continue
-			}
-
-			label := toTitleCase(period)
-			pdf.SetFont("Arial", "B", 11)
+		for _, group := range groupActivitiesByPeriod(day.Activities) {
+			ps.ensureSpace(pdf, 30)
+			pdf.useFont("B", 11)
 			pdf.SetTextColor(70, 70, 70)
-			pdf.SetX(18)
-			pdf.CellFormat(0, 6, label+" Session", "", 1, "L", false, 0, "")
+			pdf.indent(indentSession)
+			pdf.CellFormat(0, 6, group.Label+" Session", "", 1, "L", false, 0, "")
 			pdf.Ln(1)
 
-			for idx, activity := range activities {
-				pdf.SetFont("Arial", "B", 10)
-				pdf.SetTextColor(52, 152, 219)
-				pdf.SetX(20)
+			for idx, activity := range group.Activities {
+				ps.ensureSpace(pdf, 22)
+				pdf.useFont("B", 10)
+				pdf.setTextColorHex(pdf.theme.Colors.Accent)
+				pdf.indent(indentItem)
 				pdf.CellFormat(28, 5, activity.Time, "", 0, "L", false, 0, "")
 
-				pdf.SetFont("Arial", "B", 10)
+				pdf.useFont("B", 10)
 				pdf.SetTextColor(40, 40, 40)
 				pdf.CellFormat(0, 5, activity.Title, "", 1, "L", false, 0, "")
 
-				pdf.SetFont("Arial", "", 10)
+				pdf.useFont("", 10)
 				pdf.SetTextColor(90, 90, 90)
 				if activity.Description != "" {
-					pdf.SetX(25)
+					pdf.indent(indentDetail)
 					pdf.MultiCell(0, 5, "Description: "+activity.Description, "", "L", false)
 				}
 				if activity.Location != "" {
-					pdf.SetX(25)
+					pdf.indent(indentDetail)
 					pdf.MultiCell(0, 5, "Location: "+activity.Location, "", "L", false)
 				}
 				if activity.Duration != "" {
-					pdf.SetX(25)
+					pdf.indent(indentDetail)
 					pdf.MultiCell(0, 5, "Duration: "+activity.Duration, "", "L", false)
 				}
+				ps.addInternalNote(pdf, indentDetail, activity.InternalNotes)
 
-				if idx < len(activities)-1 {
+				if idx < len(group.Activities)-1 {
 					pdf.Ln(2)
 				}
 			}
@@ -272,9 +326,9 @@ func (ps *PDFService) addDaySection(pdf *gofpdf.Fpdf, day models.DayPlan) {
 			pdf.Ln(3)
 		}
 	} else {
-		pdf.SetFont("Arial", "I", 10)
+		pdf.useFont("I", 10)
 		pdf.SetTextColor(150, 150, 150)
-		pdf.SetX(25)
+		pdf.indent(indentDetail)
 		pdf.Cell(0, 6, "No activities planned for this day")
 		pdf.Ln(8)
 	}
@@ -282,47 +336,64 @@ func (ps *PDFService) addDaySection(pdf *gofpdf.Fpdf, day models.DayPlan) {
 	// Space between days
 	pdf.Ln(3)
 	pdf.SetDrawColor(220, 220, 220)
-	pdf.Line(15, pdf.GetY(), 195, pdf.GetY())
+	pdf.hline(pdf.GetY())
 	pdf.Ln(8)
 }
 
-func (ps *PDFService) addSectionHeader(pdf *gofpdf.Fpdf, title string) {
+func (ps *PDFService) addSectionHeader(pdf *pdfDocument, title string) {
 	if title == "" {
 		return
 	}
 
-	pdf.SetFont("Arial", "B", 12)
-	pdf.SetTextColor(41, 128, 185)
-	pdf.SetX(15)
+	// Never leave a section title alone at the bottom of a page
+	pdf.continuation = ""
+	ps.ensureSpace(pdf, 30)
+	pdf.useFont("B", 12)
+	pdf.setTextColorHex(pdf.theme.Colors.Primary)
+	pdf.indent(indentNone)
 	pdf.CellFormat(0, 7, title, "", 1, "L", false, 0, "")
 	ps.drawDivider(pdf)
 	pdf.Ln(6)
 	pdf.SetTextColor(0, 0, 0)
+	pdf.continuation = title + " (continued)"
 }
 
-func (ps *PDFService) addLabelValue(pdf *gofpdf.Fpdf, label, value string) {
+func (ps *PDFService) addLabelValue(pdf *pdfDocument, label, value string) {
 	if value == "" {
 		return
 	}
 
-	pdf.SetFont("Arial", "B", 10)
+	pdf.useFont("B", 10)
 	pdf.SetTextColor(70, 70, 70)
-	pdf.SetX(15)
+	pdf.indent(indentNone)
 	pdf.CellFormat(30, 5, fmt.Sprintf("%s:", label), "", 0, "L", false, 0, "")
 
-	pdf.SetFont("Arial", "", 10)
+	pdf.useFont("", 10)
 	pdf.SetTextColor(35, 35, 35)
 	pdf.MultiCell(0, 5, value, "", "L", false)
 	pdf.Ln(1)
 }
 
-func (ps *PDFService) drawDivider(pdf *gofpdf.Fpdf) {
+// addInternalNote prints an internal note of the operations copy, set apart
+// from the client-facing details by its colour
+func (ps *PDFService) addInternalNote(pdf *pdfDocument, offset float64, note string) {
+	if note == "" {
+		return
+	}
+
+	pdf.useFont("I", 10)
+	pdf.SetTextColor(166, 94, 0)
+	pdf.indent(offset)
+	pdf.MultiCell(0, 5, "Internal: "+note, "", "L", false)
+}
+
+func (ps *PDFService) drawDivider(pdf *pdfDocument) {
 	y := pdf.GetY()
-	pdf.SetDrawColor(210, 210, 210)
-	pdf.Line(15, y, 195, y)
+	pdf.setDrawColorHex(pdf.theme.Colors.Divider)
+	pdf.hline(y)
 }
 
-func (ps *PDFService) addHotelsSection(pdf *gofpdf.Fpdf, hotels []models.Hotel) {
+func (ps *PDFService) addHotelsSection(pdf *pdfDocument, hotels []models.Hotel) {
 	if len(hotels) == 0 {
 		return
 	}
@@ -330,60 +401,40 @@ func (ps *PDFService) addHotelsSection(pdf *gofpdf.Fpdf, hotels []models.Hotel)
 	ps.addSectionHeader(pdf, "Hotel Accommodations")
 
 	for idx, hotel := range hotels {
+		ps.ensureSpace(pdf, 30)
 		// Hotel name header with number
-		pdf.SetFont("Arial", "B", 11)
+		pdf.useFont("B", 11)
 		pdf.SetTextColor(40, 40, 40)
-		pdf.SetX(15)
+		pdf.indent(indentNone)
 		pdf.CellFormat(0, 6, fmt.Sprintf("Hotel %d: %s", idx+1, hotel.Name), "", 1, "L", false, 0, "")
 
 		// Hotel details with labels
-		pdf.SetFont("Arial", "", 10)
-		pdf.SetTextColor(90, 90, 90)
-		
-		if hotel.City != "" {
-			pdf.SetX(20)
-			pdf.SetFont("Arial", "B", 10)
-			pdf.SetTextColor(70, 70, 70)
-			pdf.CellFormat(25, 5, "Location:", "", 0, "L", false, 0, "")
-			pdf.SetFont("Arial", "", 10)
-			pdf.SetTextColor(90, 90, 90)
-			pdf.CellFormat(0, 5, hotel.City, "", 1, "L", false, 0, "")
+		ps.addHotelField(pdf, "Location:", hotel.City, "")
+		ps.addHotelField(pdf, "Address:", hotel.Address, "")
+		ps.addHotelField(pdf, "Phone:", hotel.Phone, "")
+		if point := hotel.Coordinates; point != nil {
+			ps.addHotelField(pdf, "Map:", formatGeoPoint(*point), mapURL(*point))
 		}
-		
 		if !hotel.CheckIn.IsZero() {
-			pdf.SetX(20)
-			pdf.SetFont("Arial", "B", 10)
-			pdf.SetTextColor(70, 70, 70)
-			pdf.CellFormat(25, 5, "Check-in:", "", 0, "L", false, 0, "")
-			pdf.SetFont("Arial", "", 10)
-			pdf.SetTextColor(90, 90, 90)
-			pdf.CellFormat(0, 5, formatDate(hotel.CheckIn), "", 1, "L", false, 0, "")
+			ps.addHotelField(pdf, "Check-in:", formatDate(hotel.CheckIn), "")
 		}
-		
 		if !hotel.CheckOut.IsZero() {
-			pdf.SetX(20)
-			pdf.SetFont("Arial", "B", 10)
-			pdf.SetTextColor(70, 70, 70)
-			pdf.CellFormat(25, 5, "Check-out:", "", 0, "L", false, 0, "")
-			pdf.SetFont("Arial", "", 10)
-			pdf.SetTextColor(90, 90, 90)
-			pdf.CellFormat(0, 5, formatDate(hotel.CheckOut), "", 1, "L", false, 0, "")
+			ps.addHotelField(pdf, "Check-out:", formatDate(hotel.CheckOut), "")
 		}
-		
 		if hotel.Nights > 0 {
-			pdf.SetX(20)
-			pdf.SetFont("Arial", "B", 10)
-			pdf.SetTextColor(70, 70, 70)
-			pdf.CellFormat(25, 5, "Duration:", "", 0, "L", false, 0, "")
-			pdf.SetFont("Arial", "", 10)
-			pdf.SetTextColor(90, 90, 90)
-			nightsText := fmt.Sprintf("%d night", hotel.Nights)
-			if hotel.Nights > 1 {
-				nightsText += "s"
+			ps.addHotelField(pdf, "Duration:", nightsLabel(hotel.Nights), "")
+		}
+		ps.addHotelField(pdf, "Meal Plan:", mealPlanLabel(hotel.MealPlan), "")
+		ps.addHotelField(pdf, "Confirmation:", hotel.ConfirmationNumber, "")
+		for i, room := range hotel.Rooms {
+			label := ""
+			if i == 0 {
+				label = "Rooms:"
 			}
-			pdf.CellFormat(0, 5, nightsText, "", 1, "L", false, 0, "")
+			ps.addHotelField(pdf, label, roomLabel(room), "")
 		}
-		
+		ps.addInternalNote(pdf, indentItem, hotel.InternalNotes)
+
 		if idx < len(hotels)-1 {
 			pdf.Ln(3)
 		}
@@ -392,109 +443,52 @@ func (ps *PDFService) addHotelsSection(pdf *gofpdf.Fpdf, hotels []models.Hotel)
 	pdf.Ln(4)
 }
 
-func (ps *PDFService) addFlightsSection(pdf *gofpdf.Fpdf, flights []models.Flight) {
+// addHotelField prints one labelled hotel detail, linking it to url when given
+func (ps *PDFService) addHotelField(pdf *pdfDocument, label, value, url string) {
+	if value == "" {
+		return
+	}
+
+	pdf.indent(indentItem)
+	pdf.useFont("B", 10)
+	pdf.SetTextColor(70, 70, 70)
+	pdf.CellFormat(28, 5, label, "", 0, "L", false, 0, "")
+	pdf.useFont("", 10)
+	if url != "" {
+		pdf.setTextColorHex(pdf.theme.Colors.Accent)
+		pdf.CellFormat(0, 5, value, "", 1, "L", false, 0, url)
+		return
+	}
+	pdf.SetTextColor(90, 90, 90)
+	pdf.MultiCell(0, 5, value, "", "L", false)
+}
+
+func (ps *PDFService) addFlightsSection(pdf *pdfDocument, flights []models.Flight) {
 	if len(flights) == 0 {
 		return
 	}
 
 	ps.addSectionHeader(pdf, "Flight Details")
 
-	for idx, flight := range flights {
-		// Flight header with number
-		pdf.SetFont("Arial", "B", 11)
-		pdf.SetTextColor(40, 40, 40)
-		pdf.SetX(15)
-		
-		flightHeader := fmt.Sprintf("Flight %d", idx+1)
-		if flight.Airline != "" && flight.FlightNumber != "" {
-			flightHeader = fmt.Sprintf("Flight %d: %s %s", idx+1, flight.Airline, flight.FlightNumber)
-		} else if flight.Airline != "" {
-			flightHeader = fmt.Sprintf("Flight %d: %s", idx+1, flight.Airline)
-		} else if flight.FlightNumber != "" {
-			flightHeader = fmt.Sprintf("Flight %d: %s", idx+1, flight.FlightNumber)
+	groups := groupFlights(flights)
+	for gdx, group := range groups {
+		if group.Journey != nil {
+			ps.ensureSpace(pdf, 50)
+			pdf.useFont("B", 11)
+			pdf.setTextColorHex(pdf.theme.Colors.Primary)
+			pdf.indent(indentNone)
+			pdf.CellFormat(0, 6, journeyTitle(group.Journey, flights), "", 1, "L", false, 0, "")
+			pdf.Ln(1)
 		}
-		
-		pdf.CellFormat(0, 6, flightHeader, "", 1, "L", false, 0, "")
 
-		// Departure details
-		if flight.DepartureCity != "" || flight.DepartureAirport != "" || !flight.DepartureTime.IsZero() {
-			pdf.SetFont("Arial", "B", 10)
-			pdf.SetTextColor(70, 70, 70)
-			pdf.SetX(20)
-			pdf.CellFormat(0, 5, "Departure", "", 1, "L", false, 0, "")
-			
-			if flight.DepartureCity != "" {
-				pdf.SetX(25)
-				pdf.SetFont("Arial", "B", 10)
-				pdf.SetTextColor(70, 70, 70)
-				pdf.CellFormat(20, 5, "City:", "", 0, "L", false, 0, "")
-				pdf.SetFont("Arial", "", 10)
-				pdf.SetTextColor(90, 90, 90)
-				pdf.CellFormat(0, 5, flight.DepartureCity, "", 1, "L", false, 0, "")
-			}
-			
-			if flight.DepartureAirport != "" {
-				pdf.SetX(25)
-				pdf.SetFont("Arial", "B", 10)
-				pdf.SetTextColor(70, 70, 70)
-				pdf.CellFormat(20, 5, "Airport:", "", 0, "L", false, 0, "")
-				pdf.SetFont("Arial", "", 10)
-				pdf.SetTextColor(90, 90, 90)
-				pdf.CellFormat(0, 5, flight.DepartureAirport, "", 1, "L", false, 0, "")
-			}
-			
-			if !flight.DepartureTime.IsZero() {
-				pdf.SetX(25)
-				pdf.SetFont("Arial", "B", 10)
-				pdf.SetTextColor(70, 70, 70)
-				pdf.CellFormat(20, 5, "Time:", "", 0, "L", false, 0, "")
-				pdf.SetFont("Arial", "", 10)
-				pdf.SetTextColor(90, 90, 90)
-				pdf.CellFormat(0, 5, formatDateTime(flight.DepartureTime), "", 1, "L", false, 0, "")
+		for _, leg := range group.Legs {
+			if leg.Layover != nil {
+				ps.addLayover(pdf, leg.Layover)
 			}
+			ps.addFlightLeg(pdf, leg.Index, leg.Flight)
 		}
 
-		pdf.Ln(1)
-
-		// Arrival details
-		if flight.ArrivalCity != "" || flight.ArrivalAirport != "" || !flight.ArrivalTime.IsZero() {
-			pdf.SetFont("Arial", "B", 10)
-			pdf.SetTextColor(70, 70, 70)
-			pdf.SetX(20)
-			pdf.CellFormat(0, 5, "Arrival", "", 1, "L", false, 0, "")
-			
-			if flight.ArrivalCity != "" {
-				pdf.SetX(25)
-				pdf.SetFont("Arial", "B", 10)
-				pdf.SetTextColor(70, 70, 70)
-				pdf.CellFormat(20, 5, "City:", "", 0, "L", false, 0, "")
-				pdf.SetFont("Arial", "", 10)
-				pdf.SetTextColor(90, 90, 90)
-				pdf.CellFormat(0, 5, flight.ArrivalCity, "", 1, "L", false, 0, "")
-			}
-			
-			if flight.ArrivalAirport != "" {
-				pdf.SetX(25)
-				pdf.SetFont("Arial", "B", 10)
-				pdf.SetTextColor(70, 70, 70)
-				pdf.CellFormat(20, 5, "Airport:", "", 0, "L", false, 0, "")
-				pdf.SetFont("Arial", "", 10)
-				pdf.SetTextColor(90, 90, 90)
-				pdf.CellFormat(0, 5, flight.ArrivalAirport, "", 1, "L", false, 0, "")
-			}
-			
-			if !flight.ArrivalTime.IsZero() {
-				pdf.SetX(25)
-				pdf.SetFont("Arial", "B", 10)
-				pdf.SetTextColor(70, 70, 70)
-				pdf.CellFormat(20, 5, "Time:", "", 0, "L", false, 0, "")
-				pdf.SetFont("Arial", "", 10)
-				pdf.SetTextColor(90, 90, 90)
-				pdf.CellFormat(0, 5, formatDateTime(flight.ArrivalTime), "", 1, "L", false, 0, "")
-			}
-		}
-		
-		if idx < len(flights)-1 {
+		if gdx < len(groups)-1 {
 			pdf.Ln(4)
 		}
 	}
@@ -502,7 +496,80 @@ func (ps *PDFService) addFlightsSection(pdf *gofpdf.Fpdf, flights []models.Fligh
 	pdf.Ln(4)
 }
 
-func (ps *PDFService) addTransfersSection(pdf *gofpdf.Fpdf, transfers []models.Transfer) {
+// addFlightLeg prints one flight with its booking details, departure and arrival
+func (ps *PDFService) addFlightLeg(pdf *pdfDocument, idx int, flight models.Flight) {
+	ps.ensureSpace(pdf, 45)
+	// Flight header with number
+	pdf.useFont("B", 11)
+	pdf.SetTextColor(40, 40, 40)
+	pdf.indent(indentNone)
+	pdf.CellFormat(0, 6, flightTitle(idx, flight), "", 1, "L", false, 0, "")
+
+	ps.addDetailField(pdf, indentItem, "Booking:", flight.BookingReference)
+	ps.addDetailField(pdf, indentItem, "Cabin:", cabinLabel(flight))
+	ps.addDetailField(pdf, indentItem, "Baggage:", flight.BaggageAllowance)
+	ps.addInternalNote(pdf, indentItem, flight.InternalNotes)
+
+	// Departure details
+	if flight.DepartureCity != "" || flight.DepartureAirport != "" || !flight.DepartureTime.IsZero() {
+		pdf.useFont("B", 10)
+		pdf.SetTextColor(70, 70, 70)
+		pdf.indent(indentItem)
+		pdf.CellFormat(0, 5, "Departure", "", 1, "L", false, 0, "")
+
+		ps.addDetailField(pdf, indentDetail, "City:", flight.DepartureCity)
+		ps.addDetailField(pdf, indentDetail, "Airport:", airportLabel(flight.DepartureAirport, flight.DepartureTerminal))
+		if !flight.DepartureTime.IsZero() {
+			ps.addDetailField(pdf, indentDetail, "Time:", formatDateTime(flight.DepartureTime))
+		}
+	}
+
+	pdf.Ln(1)
+
+	// Arrival details
+	if flight.ArrivalCity != "" || flight.ArrivalAirport != "" || !flight.ArrivalTime.IsZero() {
+		pdf.useFont("B", 10)
+		pdf.SetTextColor(70, 70, 70)
+		pdf.indent(indentItem)
+		pdf.CellFormat(0, 5, "Arrival", "", 1, "L", false, 0, "")
+
+		ps.addDetailField(pdf, indentDetail, "City:", flight.ArrivalCity)
+		ps.addDetailField(pdf, indentDetail, "Airport:", airportLabel(flight.ArrivalAirport, flight.ArrivalTerminal))
+		if !flight.ArrivalTime.IsZero() {
+			ps.addDetailField(pdf, indentDetail, "Time:", formatDateTime(flight.ArrivalTime))
+		}
+	}
+}
+
+func (ps *PDFService) addDetailField(pdf *pdfDocument, offset float64, label, value string) {
+	if value == "" {
+		return
+	}
+
+	pdf.indent(offset)
+	pdf.useFont("B", 10)
+	pdf.SetTextColor(70, 70, 70)
+	pdf.CellFormat(20, 5, label, "", 0, "L", false, 0, "")
+	pdf.useFont("", 10)
+	pdf.SetTextColor(90, 90, 90)
+	pdf.CellFormat(0, 5, value, "", 1, "L", false, 0, "")
+}
+
+// addLayover prints the connection between two legs, in red when it is short
+func (ps *PDFService) addLayover(pdf *pdfDocument, layover *models.Layover) {
+	pdf.Ln(2)
+	pdf.useFont("I", 10)
+	if layover.DurationMinutes < layover.MinimumConnectionMinutes {
+		pdf.SetTextColor(192, 57, 43)
+	} else {
+		pdf.SetTextColor(100, 100, 100)
+	}
+	pdf.indent(indentItem)
+	pdf.CellFormat(0, 5, layoverLabel(layover), "", 1, "L", false, 0, "")
+	pdf.Ln(2)
+}
+
+func (ps *PDFService) addTransfersSection(pdf *pdfDocument, transfers []models.Transfer) {
 	if len(transfers) == 0 {
 		return
 	}
@@ -510,65 +577,72 @@ func (ps *PDFService) addTransfersSection(pdf *gofpdf.Fpdf, transfers []models.T
 	ps.addSectionHeader(pdf, "Transfers")
 
 	for _, transfer := range transfers {
-		pdf.SetFont("Arial", "B", 11)
+		ps.ensureSpace(pdf, 18)
+		pdf.useFont("B", 11)
 		pdf.SetTextColor(40, 40, 40)
-		pdf.SetX(15)
+		pdf.indent(indentNone)
 		pdf.CellFormat(0, 6, toTitleCase(transfer.Mode)+" Transfer", "", 1, "L", false, 0, "")
 
-		pdf.SetFont("Arial", "", 10)
-		pdf.SetTextColor(90, 90, 90)
-		pdf.SetX(15)
-		pdf.CellFormat(0, 5, fmt.Sprintf("Pickup: %s   Drop-off: %s   Time: %s", transfer.Pickup, transfer.Dropoff, transfer.PickupTime), "", 1, "L", false, 0, "")
+		ps.addDetailField(pdf, indentItem, "Pickup:", transfer.Pickup)
+		ps.addDetailField(pdf, indentItem, "Drop-off:", transfer.Dropoff)
+		ps.addDetailField(pdf, indentItem, "Time:", transferTimeLabel(transfer))
+		ps.addDetailField(pdf, indentItem, "Vehicle:", vehicleLabel(transfer))
+		ps.addDetailField(pdf, indentItem, "Vendor:", contactLabel(transfer.Vendor))
+		ps.addDetailField(pdf, indentItem, "Driver:", contactLabel(transfer.Driver))
+		if transfer.Price > 0 {
+			ps.addDetailField(pdf, indentItem, "Price:", formatAmount(transfer.Price, transfer.Currency))
+		}
 		if strings.TrimSpace(transfer.Notes) != "" {
-			pdf.SetX(15)
+			pdf.useFont("", 10)
+			pdf.SetTextColor(90, 90, 90)
+			pdf.indent(indentItem)
 			pdf.MultiCell(0, 5, "Notes: "+transfer.Notes, "", "L", false)
 		}
+		ps.addInternalNote(pdf, indentItem, transfer.InternalNotes)
 		pdf.Ln(2)
 	}
 
 	pdf.Ln(4)
 }
 
-func (ps *PDFService) addPaymentPlanSection(pdf *gofpdf.Fpdf, plan []models.PaymentInstallment) {
+func (ps *PDFService) addPaymentPlanSection(pdf *pdfDocument, plan []models.PaymentInstallment) {
 	if len(plan) == 0 {
 		return
 	}
 
 	ps.addSectionHeader(pdf, "Payment Plan")
 
-	ordered := make([]models.PaymentInstallment, len(plan))
-	copy(ordered, plan)
-	sort.Slice(ordered, func(i, j int) bool {
-		return ordered[i].InstallmentNumber < ordered[j].InstallmentNumber
-	})
+	ordered := sortedInstallments(plan)
+	numberWidth := pdf.geometry.contentWidth() / 6
+	columnWidth := pdf.geometry.contentWidth() * 7 / 36
 
-	pdf.SetFont("Arial", "B", 10)
+	pdf.useFont("B", 10)
 	pdf.SetTextColor(60, 60, 60)
-	pdf.SetX(15)
-	pdf.CellFormat(30, 6, "Installment", "", 0, "L", false, 0, "")
-	pdf.CellFormat(35, 6, "Amount", "", 0, "L", false, 0, "")
-	pdf.CellFormat(35, 6, "Due Date", "", 0, "L", false, 0, "")
+	pdf.indent(indentNone)
+	pdf.CellFormat(numberWidth, 6, "Installment", "", 0, "L", false, 0, "")
+	pdf.CellFormat(columnWidth, 6, "Amount", "", 0, "L", false, 0, "")
+	pdf.CellFormat(columnWidth, 6, "Due Date", "", 0, "L", false, 0, "")
 	pdf.CellFormat(0, 6, "Status", "", 1, "L", false, 0, "")
 
-	pdf.SetFont("Arial", "", 10)
+	pdf.useFont("", 10)
 	pdf.SetTextColor(40, 40, 40)
 	for _, installment := range ordered {
-		status := strings.TrimSpace(installment.Status)
-		if status == "" {
-			status = "Pending"
+		pdf.indent(indentNone)
+		pdf.CellFormat(numberWidth, 6, fmt.Sprintf("#%d", installment.InstallmentNumber), "", 0, "L", false, 0, "")
+		pdf.CellFormat(columnWidth, 6, formatAmount(installment.Amount, installment.Currency), "", 0, "L", false, 0, "")
+		pdf.CellFormat(columnWidth, 6, formatDate(installment.DueDate), "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 6, installmentStatus(installment), "", 1, "L", false, 0, "")
+		if installment.InternalNotes != "" {
+			ps.addInternalNote(pdf, numberWidth, installment.InternalNotes)
+			pdf.useFont("", 10)
+			pdf.SetTextColor(40, 40, 40)
 		}
-
-		pdf.SetX(15)
-		pdf.CellFormat(30, 6, fmt.Sprintf("#%d", installment.InstallmentNumber), "", 0, "L", false, 0, "")
-		pdf.CellFormat(35, 6, formatAmount(installment.Amount, installment.Currency), "", 0, "L", false, 0, "")
-		pdf.CellFormat(35, 6, formatDate(installment.DueDate), "", 0, "L", false, 0, "")
-		pdf.CellFormat(0, 6, status, "", 1, "L", false, 0, "")
 	}
 
 	pdf.Ln(6)
 }
 
-func (ps *PDFService) addInclusionsExclusionsSection(pdf *gofpdf.Fpdf, inclusions, exclusions []string) {
+func (ps *PDFService) addInclusionsExclusionsSection(pdf *pdfDocument, inclusions, exclusions []string) {
 	if len(inclusions) == 0 && len(exclusions) == 0 {
 		return
 	}
@@ -576,30 +650,30 @@ func (ps *PDFService) addInclusionsExclusionsSection(pdf *gofpdf.Fpdf, inclusion
 	ps.addSectionHeader(pdf, "Inclusions and Exclusions")
 
 	if len(inclusions) > 0 {
-		pdf.SetFont("Arial", "B", 11)
+		pdf.useFont("B", 11)
 		pdf.SetTextColor(70, 70, 70)
-		pdf.SetX(15)
+		pdf.indent(indentNone)
 		pdf.CellFormat(0, 6, "Inclusions", "", 1, "L", false, 0, "")
 
-		pdf.SetFont("Arial", "", 10)
+		pdf.useFont("", 10)
 		pdf.SetTextColor(90, 90, 90)
 		for _, item := range inclusions {
-			pdf.SetX(20)
+			pdf.indent(indentItem)
 			pdf.CellFormat(0, 5, "- "+item, "", 1, "L", false, 0, "")
 		}
 		pdf.Ln(2)
 	}
 
 	if len(exclusions) > 0 {
-		pdf.SetFont("Arial", "B", 11)
+		pdf.useFont("B", 11)
 		pdf.SetTextColor(70, 70, 70)
-		pdf.SetX(15)
+		pdf.indent(indentNone)
 		pdf.CellFormat(0, 6, "Exclusions", "", 1, "L", false, 0, "")
 
-		pdf.SetFont("Arial", "", 10)
+		pdf.useFont("", 10)
 		pdf.SetTextColor(90, 90, 90)
 		for _, item := range exclusions {
-			pdf.SetX(20)
+			pdf.indent(indentItem)
 			pdf.CellFormat(0, 5, "- "+item, "", 1, "L", false, 0, "")
 		}
 		pdf.Ln(2)
@@ -622,6 +696,50 @@ func formatDateTime(t time.Time) string {
 	return t.Format("Jan 2, 2006 15:04")
 }
 
+// mealPlanLabel spells out a meal plan code
+func mealPlanLabel(plan string) string {
+	switch plan {
+	case models.MealPlanEP:
+		return "EP - Room only"
+	case models.MealPlanCP:
+		return "CP - Breakfast included"
+	case models.MealPlanMAP:
+		return "MAP - Breakfast and one meal"
+	case models.MealPlanAP:
+		return "AP - All meals"
+	}
+	return plan
+}
+
+// roomLabel describes a room line such as "2 x Deluxe Double, 2 guests each"
+func roomLabel(room models.HotelRoom) string {
+	label := room.Label
+	if room.RoomType != "" && room.RoomType != room.Label {
+		label = fmt.Sprintf("%s (%s)", room.Label, room.RoomType)
+	}
+	if room.Quantity > 1 {
+		label = fmt.Sprintf("%d x %s", room.Quantity, label)
+	}
+	if room.Occupancy == 1 {
+		label += ", 1 guest"
+	} else if room.Occupancy > 1 {
+		label += fmt.Sprintf(", %d guests", room.Occupancy)
+	}
+	if room.Quantity > 1 && room.Occupancy > 0 {
+		label += " each"
+	}
+	return label
+}
+
+func formatGeoPoint(point models.GeoPoint) string {
+	return fmt.Sprintf("%.5f, %.5f", point.Latitude, point.Longitude)
+}
+
+// mapURL links a coordinate to OpenStreetMap
+func mapURL(point models.GeoPoint) string {
+	return fmt.Sprintf("https://www.openstreetmap.org/?mlat=%.5f&mlon=%.5f#map=17/%.5f/%.5f", point.Latitude, point.Longitude, point.Latitude, point.Longitude)
+}
+
 func formatAmount(amount float64, currency string) string {
 	if currency == "" {
 		currency = "USD"
@@ -638,22 +756,13 @@ func toTitleCase(value string) string {
 	return strings.ToUpper(lower[:1]) + lower[1:]
 }
 
-func (ps *PDFService) savePDFToOutput(pdfBytes []byte, itinerary *models.Itinerary) error {
-	if err := os.MkdirAll("output", 0755); err != nil {
-		return fmt.Errorf("create output directory: %w", err)
-	}
-
-	fileName := ps.buildFileName(itinerary)
-	filePath := filepath.Join("output", fileName)
-
-	if err := os.WriteFile(filePath, pdfBytes, 0644); err != nil {
-		return fmt.Errorf("write PDF to %s: %w", filePath, err)
-	}
-
-	return nil
+func (ps *PDFService) buildFileName(itinerary *models.Itinerary) string {
+	return buildBaseFileName(itinerary) + ps.FileExtension()
 }
 
-func (ps *PDFService) buildFileName(itinerary *models.Itinerary) string {
+// buildBaseFileName derives an extension-less, filesystem-safe name from the
+// itinerary title and travel dates
+func buildBaseFileName(itinerary *models.Itinerary) string {
 	base := strings.TrimSpace(itinerary.Title)
 	if base == "" {
 		base = "itinerary"
@@ -673,7 +782,7 @@ func (ps *PDFService) buildFileName(itinerary *models.Itinerary) string {
 		sanitized = "itinerary"
 	}
 
-	return sanitized + ".pdf"
+	return sanitized
 }
 
 func sanitizeFileName(input string) string {
@@ -683,15 +792,26 @@ func sanitizeFileName(input string) string {
 	return strings.Trim(cleaned, "-_")
 }
 
-// addFooter adds a professional footer
-func (ps *PDFService) addFooter(pdf *gofpdf.Fpdf) {
-	// Footer area - position footer at bottom
-	pdf.SetXY(15, 270) // Approximate position for A4
-	pdf.SetFont("Arial", "", 9)
-	pdf.SetTextColor(150, 150, 150)
-	pdf.SetDrawColor(220, 220, 220)
-	pdf.Line(15, 268, 195, 268)
+// addContactSection lists the agency contact details
+func (ps *PDFService) addContactSection(pdf *pdfDocument, contact models.ContactDetails) {
+	ps.addSectionHeader(pdf, "Contact Us")
 
-	pdf.SetXY(15, 270)
-	pdf.Cell(0, 10, fmt.Sprintf("Generated by Vigovia Itinerary Builder | Page %d", pdf.PageNo()))
+	ps.addLabelValue(pdf, "Phone", contact.Phone)
+	ps.addLabelValue(pdf, "Email", contact.Email)
+	ps.addLabelValue(pdf, "Website", contact.Website)
+	ps.addLabelValue(pdf, "Address", contact.Address)
+	pdf.Ln(6)
+}
+
+// addTermsPage adds the agency terms and conditions on a page of their own
+func (ps *PDFService) addTermsPage(pdf *pdfDocument, terms string) {
+	pdf.continuation = ""
+	pdf.AddPage()
+	ps.addSectionHeader(pdf, "Terms and Conditions")
+
+	pdf.useFont("", 10)
+	pdf.SetTextColor(50, 50, 50)
+	pdf.indent(indentNone)
+	pdf.MultiCell(0, 5, terms, "", "L", false)
+	pdf.Ln(4)
 }
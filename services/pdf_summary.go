@@ -0,0 +1,116 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"vigovia-task/models"
+)
+
+// addSummary lays out the condensed variant: one line per hotel, flight and
+// day, the payment total, the inclusions and, in an operations copy, the
+// internal notes, meant to fit on a single page
+func (ps *PDFService) addSummary(pdf *pdfDocument, itinerary *models.Itinerary) {
+	ps.addSummaryHeading(pdf, "Trip Information")
+	ps.addSummaryLine(pdf, fmt.Sprintf("%s  |  %s - %s  |  %d Days",
+		itinerary.Location, formatDate(itinerary.StartDate), formatDate(itinerary.EndDate), len(itinerary.Days)))
+
+	if len(itinerary.Travellers) > 0 {
+		names := make([]string, 0, len(itinerary.Travellers))
+		for _, traveller := range itinerary.Travellers {
+			names = append(names, traveller.FullName())
+		}
+		ps.addSummaryHeading(pdf, fmt.Sprintf("Travellers (%d)", len(names)))
+		ps.addSummaryLine(pdf, strings.Join(names, ", "))
+	}
+
+	if len(itinerary.Hotels) > 0 {
+		ps.addSummaryHeading(pdf, "Hotels")
+		for _, hotel := range itinerary.Hotels {
+			line := fmt.Sprintf("%s, %s: %s - %s", hotel.Name, hotel.City, formatDate(hotel.CheckIn), formatDate(hotel.CheckOut))
+			if hotel.Nights > 0 {
+				line += " (" + nightsLabel(hotel.Nights) + ")"
+			}
+			ps.addSummaryLine(pdf, line)
+		}
+	}
+
+	if len(itinerary.Flights) > 0 {
+		ps.addSummaryHeading(pdf, "Flights")
+		for idx, flight := range itinerary.Flights {
+			ps.addSummaryLine(pdf, fmt.Sprintf("%s: %s %s -> %s %s",
+				flightTitle(idx, flight),
+				flight.DepartureAirport, formatDateTime(flight.DepartureTime),
+				flight.ArrivalAirport, formatDateTime(flight.ArrivalTime)))
+		}
+	}
+
+	if len(itinerary.Days) > 0 {
+		ps.addSummaryHeading(pdf, "Days")
+		for _, day := range itinerary.Days {
+			titles := make([]string, 0, len(day.Activities))
+			for _, group := range groupActivitiesByPeriod(day.Activities) {
+				for _, activity := range group.Activities {
+					titles = append(titles, activity.Title)
+				}
+			}
+			line := fmt.Sprintf("Day %d (%s): %s", day.DayNumber, formatDate(day.Date), day.Title)
+			if len(titles) > 0 {
+				line += " - " + strings.Join(titles, ", ")
+			}
+			ps.addSummaryLine(pdf, line)
+		}
+	}
+
+	if len(itinerary.PaymentPlan) > 0 {
+		ps.addSummaryHeading(pdf, "Payment")
+		totals := make(map[string]float64)
+		var currencies []string
+		for _, installment := range itinerary.PaymentPlan {
+			currency := strings.ToUpper(installment.Currency)
+			if _, seen := totals[currency]; !seen {
+				currencies = append(currencies, currency)
+			}
+			totals[currency] += installment.Amount
+		}
+		parts := make([]string, 0, len(currencies))
+		for _, currency := range currencies {
+			parts = append(parts, formatAmount(totals[currency], currency))
+		}
+		ps.addSummaryLine(pdf, fmt.Sprintf("Total %s in %d installments", strings.Join(parts, " + "), len(itinerary.PaymentPlan)))
+	}
+
+	if len(itinerary.Inclusions) > 0 {
+		ps.addSummaryHeading(pdf, "Inclusions")
+		ps.addSummaryLine(pdf, strings.Join(itinerary.Inclusions, "; "))
+	}
+
+	notes := internalNoteLines(itinerary)
+	if itinerary.InternalNotes != "" || len(notes) > 0 {
+		ps.addSummaryHeading(pdf, "Internal Notes")
+		if itinerary.InternalNotes != "" {
+			ps.addSummaryLine(pdf, itinerary.InternalNotes)
+		}
+		for _, line := range notes {
+			ps.addSummaryLine(pdf, line)
+		}
+	}
+}
+
+func (ps *PDFService) addSummaryHeading(pdf *pdfDocument, title string) {
+	pdf.continuation = ""
+	pdf.Ln(2)
+	pdf.useFont("B", 10)
+	pdf.setTextColorHex(pdf.theme.Colors.Primary)
+	pdf.indent(indentNone)
+	pdf.CellFormat(0, 5, title, "", 1, "L", false, 0, "")
+	ps.drawDivider(pdf)
+	pdf.Ln(1)
+}
+
+func (ps *PDFService) addSummaryLine(pdf *pdfDocument, text string) {
+	pdf.useFont("", 8)
+	pdf.SetTextColor(40, 40, 40)
+	pdf.indent(indentSession)
+	pdf.MultiCell(0, 4, text, "", "L", false)
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+
+	"vigovia-task/models"
+)
+
+// DefaultRenderCacheBytes bounds the memory held by rendered documents
+const DefaultRenderCacheBytes = 64 << 20
+
+// RenderedDocument is a rendered export together with its entity tag
+type RenderedDocument struct {
+	Data []byte
+	ETag string
+}
+
+type renderCacheEntry struct {
+	key         string
+	itineraryID string
+	document    *RenderedDocument
+}
+
+// RenderCache keeps recently rendered documents keyed on the itinerary
+// revision, theme, format, layout and copy, evicting the least recently used
+// entries once maxBytes is exceeded
+type RenderCache struct {
+	mu       sync.Mutex
+	maxBytes int
+	size     int
+	order    *list.List
+	entries  map[string]*list.Element
+}
+
+// NewRenderCache creates a cache holding at most maxBytes of documents
+func NewRenderCache(maxBytes int) *RenderCache {
+	if maxBytes <= 0 {
+		maxBytes = DefaultRenderCacheBytes
+	}
+	return &RenderCache{
+		maxBytes: maxBytes,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// Render returns the cached document for these inputs or renders and caches it
+func (rc *RenderCache) Render(itinerary *models.Itinerary, format string, renderer Renderer, opts RenderOptions) (*RenderedDocument, error) {
+	key := renderCacheKey(itinerary, format, opts)
+	if document, ok := rc.get(key); ok {
+		return document, nil
+	}
+
+	data, err := renderer.Render(itinerary, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(data)
+	document := &RenderedDocument{Data: data, ETag: fmt.Sprintf("%q", hex.EncodeToString(sum[:]))}
+	rc.put(key, itinerary.ID, document)
+	return document, nil
+}
+
+// InvalidateItinerary drops every cached document of an itinerary
+func (rc *RenderCache) InvalidateItinerary(itineraryID string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	for element := rc.order.Front(); element != nil; {
+		next := element.Next()
+		if entry := element.Value.(*renderCacheEntry); entry.itineraryID == itineraryID {
+			rc.remove(element)
+		}
+		element = next
+	}
+}
+
+func (rc *RenderCache) get(key string) (*RenderedDocument, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	element, ok := rc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	rc.order.MoveToFront(element)
+	return element.Value.(*renderCacheEntry).document, true
+}
+
+func (rc *RenderCache) put(key, itineraryID string, document *RenderedDocument) {
+	if len(document.Data) > rc.maxBytes {
+		return
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if element, ok := rc.entries[key]; ok {
+		rc.remove(element)
+	}
+
+	rc.entries[key] = rc.order.PushFront(&renderCacheEntry{key: key, itineraryID: itineraryID, document: document})
+	rc.size += len(document.Data)
+
+	for rc.size > rc.maxBytes {
+		rc.remove(rc.order.Back())
+	}
+}
+
+// remove unlinks an entry; the caller holds mu
+func (rc *RenderCache) remove(element *list.Element) {
+	entry := rc.order.Remove(element).(*renderCacheEntry)
+	delete(rc.entries, entry.key)
+	rc.size -= len(entry.document.Data)
+}
+
+// renderCacheKey identifies the inputs that determine a document's bytes
+func renderCacheKey(itinerary *models.Itinerary, format string, opts RenderOptions) string {
+	theme := opts.theme()
+	layout := opts.layout()
+	return strings.Join([]string{
+		itinerary.ID,
+		fmt.Sprint(itinerary.UpdatedAt.UnixNano()),
+		normalizeFormat(format),
+		theme.ID,
+		fmt.Sprint(theme.UpdatedAt.UnixNano()),
+		layout.PageSize,
+		layout.Orientation,
+		fmt.Sprint(layout.Margin),
+		layout.Variant,
+		fmt.Sprint(opts.Internal),
+	}, "|")
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"vigovia-task/models"
+)
+
+// countingRenderer renders size bytes and counts its renders
+type countingRenderer struct {
+	size    int
+	renders int
+}
+
+func (r *countingRenderer) Render(itinerary *models.Itinerary, opts RenderOptions) ([]byte, error) {
+	r.renders++
+	return []byte(strings.Repeat("x", r.size)), nil
+}
+
+func (r *countingRenderer) ContentType() string   { return "text/plain" }
+func (r *countingRenderer) FileExtension() string { return ".txt" }
+
+func TestRenderCacheHitsAndRevisions(t *testing.T) {
+	cache := NewRenderCache(1 << 10)
+	renderer := &countingRenderer{size: 10}
+	itinerary := &models.Itinerary{ID: "trip-1", UpdatedAt: time.Now()}
+
+	first, err := cache.Render(itinerary, FormatMarkdown, renderer, RenderOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := cache.Render(itinerary, FormatMarkdown, renderer, RenderOptions{})
+	if renderer.renders != 1 || second.ETag != first.ETag {
+		t.Fatalf("second render: %d renders, ETag %s and %s", renderer.renders, first.ETag, second.ETag)
+	}
+
+	cache.Render(itinerary, FormatMarkdown, renderer, RenderOptions{Internal: true})
+	if renderer.renders != 2 {
+		t.Errorf("the operations copy was served from the client copy")
+	}
+
+	edited := *itinerary
+	edited.UpdatedAt = itinerary.UpdatedAt.Add(time.Second)
+	cache.Render(&edited, FormatMarkdown, renderer, RenderOptions{})
+	if renderer.renders != 3 {
+		t.Errorf("an edited itinerary was served from the cache")
+	}
+
+	cache.InvalidateItinerary(itinerary.ID)
+	cache.Render(&edited, FormatMarkdown, renderer, RenderOptions{})
+	if renderer.renders != 4 {
+		t.Errorf("an invalidated itinerary was served from the cache")
+	}
+}
+
+func TestRenderCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewRenderCache(25)
+	renderer := &countingRenderer{size: 10}
+	trip := func(id string) *models.Itinerary { return &models.Itinerary{ID: id} }
+
+	cache.Render(trip("a"), FormatMarkdown, renderer, RenderOptions{})
+	cache.Render(trip("b"), FormatMarkdown, renderer, RenderOptions{})
+	cache.Render(trip("a"), FormatMarkdown, renderer, RenderOptions{})
+	cache.Render(trip("c"), FormatMarkdown, renderer, RenderOptions{})
+	if renderer.renders != 3 {
+		t.Fatalf("%d renders before eviction, want 3", renderer.renders)
+	}
+
+	cache.Render(trip("a"), FormatMarkdown, renderer, RenderOptions{})
+	if renderer.renders != 3 {
+		t.Error("the recently used document was evicted")
+	}
+	cache.Render(trip("b"), FormatMarkdown, renderer, RenderOptions{})
+	if renderer.renders != 4 {
+		t.Error("the least recently used document was kept over the size limit")
+	}
+	if cache.size > cache.maxBytes {
+		t.Errorf("cache holds %d bytes, limit %d", cache.size, cache.maxBytes)
+	}
+}
+
+func TestRenderCacheSkipsOversizedDocuments(t *testing.T) {
+	cache := NewRenderCache(5)
+	renderer := &countingRenderer{size: 10}
+	itinerary := &models.Itinerary{ID: "trip-1"}
+
+	cache.Render(itinerary, FormatMarkdown, renderer, RenderOptions{})
+	cache.Render(itinerary, FormatMarkdown, renderer, RenderOptions{})
+	if renderer.renders != 2 || cache.size != 0 {
+		t.Errorf("oversized document was cached: %d renders, %d bytes held", renderer.renders, cache.size)
+	}
+}
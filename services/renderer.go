@@ -0,0 +1,364 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"vigovia-task/models"
+)
+
+// Supported export formats
+const (
+	FormatPDF      = "pdf"
+	FormatHTML     = "html"
+	FormatMarkdown = "md"
+)
+
+// ErrNotAcceptable is returned when an Accept header refuses, with q=0,
+// every export format it names
+var ErrNotAcceptable = errors.New("none of the accepted formats can be produced; use application/pdf, text/html or text/markdown")
+
+// Renderer turns an itinerary into a downloadable document
+type Renderer interface {
+	Render(itinerary *models.Itinerary, opts RenderOptions) ([]byte, error)
+	ContentType() string
+	FileExtension() string
+}
+
+// RenderOptions controls how a document is rendered
+type RenderOptions struct {
+	// Theme is the branding applied to the document; nil selects the default theme
+	Theme *models.BrandingProfile
+	// Layout sets paper size, orientation, margins and level of detail; the
+	// zero value selects A4 portrait with the detailed layout
+	Layout PageLayout
+	// Progress, when set, receives the completed percentage of a long render
+	Progress func(percent int)
+	// Internal renders the operations copy for the agency, marked as not for
+	// clients and including internal notes and full traveller details. The
+	// client copy leaves the notes out and masks traveller details.
+	Internal bool
+}
+
+func (o RenderOptions) layout() PageLayout {
+	return o.Layout.withDefaults()
+}
+
+// content returns the itinerary as the document shows it
+func (o RenderOptions) content(itinerary *models.Itinerary) *models.Itinerary {
+	if o.Internal {
+		return itinerary
+	}
+	return ClientView(itinerary)
+}
+
+func (o RenderOptions) theme() *models.BrandingProfile {
+	if o.Theme == nil {
+		return DefaultBrandingProfile()
+	}
+	return o.Theme
+}
+
+// RendererRegistry resolves export formats to renderers
+type RendererRegistry struct {
+	renderers map[string]Renderer
+}
+
+// NewRendererRegistry creates a registry with the PDF, HTML and Markdown renderers
+func NewRendererRegistry(pdfService *PDFService) *RendererRegistry {
+	return &RendererRegistry{
+		renderers: map[string]Renderer{
+			FormatPDF:      pdfService,
+			FormatHTML:     NewHTMLRenderer(),
+			FormatMarkdown: NewMarkdownRenderer(),
+		},
+	}
+}
+
+// Get returns the renderer registered for a format
+func (rr *RendererRegistry) Get(format string) (Renderer, error) {
+	renderer, exists := rr.renderers[normalizeFormat(format)]
+	if !exists {
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+	return renderer, nil
+}
+
+// Negotiate picks a format from an explicit format value, falling back to the
+// Accept header and finally to PDF. It returns ErrNotAcceptable when the
+// header refuses every format it names.
+func (rr *RendererRegistry) Negotiate(format, accept string) (string, Renderer, error) {
+	if strings.TrimSpace(format) != "" {
+		renderer, err := rr.Get(format)
+		if err != nil {
+			return "", nil, err
+		}
+		return normalizeFormat(format), renderer, nil
+	}
+
+	format, renderer, err := rr.FromAccept(accept)
+	if err != nil {
+		return "", nil, err
+	}
+	if renderer == nil {
+		return FormatPDF, rr.renderers[FormatPDF], nil
+	}
+	return format, renderer, nil
+}
+
+// FromAccept picks the format an Accept header ranks highest by its q-values,
+// the earliest on a tie. It returns no renderer when the header names no
+// format, and ErrNotAcceptable when it refuses every one it names with q=0.
+func (rr *RendererRegistry) FromAccept(accept string) (string, Renderer, error) {
+	best, bestQuality, named := "", 0.0, false
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		var candidate string
+		switch strings.ToLower(strings.TrimSpace(params[0])) {
+		case "application/pdf":
+			candidate = FormatPDF
+		case "text/html", "application/xhtml+xml":
+			candidate = FormatHTML
+		case "text/markdown", "text/x-markdown":
+			candidate = FormatMarkdown
+		default:
+			continue
+		}
+		if _, exists := rr.renderers[candidate]; !exists {
+			continue
+		}
+		quality, ok := acceptQuality(params[1:])
+		if !ok {
+			continue
+		}
+		named = true
+		if quality > bestQuality {
+			best, bestQuality = candidate, quality
+		}
+	}
+	switch {
+	case best != "":
+		return best, rr.renderers[best], nil
+	case named:
+		return "", nil, ErrNotAcceptable
+	default:
+		return "", nil, nil
+	}
+}
+
+// acceptQuality reads the q parameter of an Accept entry, 1 when it has
+// none, reporting false when it is malformed
+func acceptQuality(params []string) (float64, bool) {
+	for _, param := range params {
+		name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		if !strings.EqualFold(strings.TrimSpace(name), "q") {
+			continue
+		}
+		quality, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || quality < 0 || quality > 1 {
+			return 0, false
+		}
+		return quality, true
+	}
+	return 1, true
+}
+
+// ExportFileName builds the download file name for an itinerary rendered by
+// the given renderer
+func ExportFileName(itinerary *models.Itinerary, renderer Renderer) string {
+	return buildBaseFileName(itinerary) + renderer.FileExtension()
+}
+
+func normalizeFormat(format string) string {
+	switch lowered := strings.ToLower(strings.TrimSpace(format)); lowered {
+	case "markdown":
+		return FormatMarkdown
+	case "htm":
+		return FormatHTML
+	default:
+		return lowered
+	}
+}
+
+// activityGroup is a set of activities sharing the same period of the day
+type activityGroup struct {
+	Label      string
+	Activities []models.Activity
+}
+
+// groupActivitiesByPeriod groups a day's activities in morning, afternoon,
+// evening order followed by any custom periods
+func groupActivitiesByPeriod(activities []models.Activity) []activityGroup {
+	grouped := make(map[string][]models.Activity)
+	var extraPeriods []string
+	for _, activity := range activities {
+		periodKey := strings.ToLower(activity.Period)
+		if _, seen := grouped[periodKey]; !seen && !isOrderedPeriod(periodKey) {
+			extraPeriods = append(extraPeriods, periodKey)
+		}
+		grouped[periodKey] = append(grouped[periodKey], activity)
+	}
+
+	groups := make([]activityGroup, 0, len(grouped))
+	for _, period := range append(append([]string{}, periodOrder...), extraPeriods...) {
+		if len(grouped[period]) == 0 {
+			continue
+		}
+		label := periodDisplay[period]
+		if label == "" {
+			label = toTitleCase(period)
+		}
+		groups = append(groups, activityGroup{Label: label, Activities: grouped[period]})
+	}
+
+	return groups
+}
+
+func isOrderedPeriod(period string) bool {
+	for _, ordered := range periodOrder {
+		if period == ordered {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedInstallments returns the payment plan ordered by installment number
+func sortedInstallments(plan []models.PaymentInstallment) []models.PaymentInstallment {
+	ordered := make([]models.PaymentInstallment, len(plan))
+	copy(ordered, plan)
+	sort.Slice(ordered, func(i, j int) bool {
+		return ordered[i].InstallmentNumber < ordered[j].InstallmentNumber
+	})
+	return ordered
+}
+
+func installmentStatus(installment models.PaymentInstallment) string {
+	status := strings.TrimSpace(installment.Status)
+	if status == "" {
+		return "Pending"
+	}
+	return status
+}
+
+func nightsLabel(nights int) string {
+	if nights == 1 {
+		return "1 night"
+	}
+	return fmt.Sprintf("%d nights", nights)
+}
+
+func flightTitle(idx int, flight models.Flight) string {
+	switch {
+	case flight.Airline != "" && flight.FlightNumber != "":
+		return fmt.Sprintf("Flight %d: %s %s", idx+1, flight.Airline, flight.FlightNumber)
+	case flight.Airline != "":
+		return fmt.Sprintf("Flight %d: %s", idx+1, flight.Airline)
+	case flight.FlightNumber != "":
+		return fmt.Sprintf("Flight %d: %s", idx+1, flight.FlightNumber)
+	default:
+		return fmt.Sprintf("Flight %d", idx+1)
+	}
+}
+
+// cabinLabel combines the cabin class and seat, e.g. "Business, seat 4A"
+func cabinLabel(flight models.Flight) string {
+	cabin := toTitleCase(strings.ReplaceAll(flight.CabinClass, "_", " "))
+	switch {
+	case cabin != "" && flight.Seat != "":
+		return fmt.Sprintf("%s, seat %s", cabin, flight.Seat)
+	case flight.Seat != "":
+		return "Seat " + flight.Seat
+	}
+	return cabin
+}
+
+// airportLabel adds the terminal to an airport, e.g. "DXB, Terminal 3"
+func airportLabel(airport, terminal string) string {
+	if airport == "" || terminal == "" {
+		return airport
+	}
+	// Terminals are entered as "3", "T3" or "Terminal 3"
+	if !strings.HasPrefix(strings.ToUpper(terminal), "T") {
+		terminal = "Terminal " + terminal
+	}
+	return fmt.Sprintf("%s, %s", airport, terminal)
+}
+
+// transferTimeLabel gives the pickup date and time, and how long after
+// landing it is for an airport pickup
+func transferTimeLabel(transfer models.Transfer) string {
+	if transfer.PickupAt.IsZero() {
+		return transfer.PickupTime
+	}
+	label := formatDateTime(transfer.PickupAt)
+	if transfer.FlightNumber != "" && transfer.PickupAfterLandingMinutes != nil {
+		label += fmt.Sprintf(" (%s after %s lands)", formatMinutes(*transfer.PickupAfterLandingMinutes), transfer.FlightNumber)
+	}
+	return label
+}
+
+// vehicleLabel combines the vehicle type and capacity, e.g. "Van, seats 6"
+func vehicleLabel(transfer models.Transfer) string {
+	vehicle := toTitleCase(transfer.VehicleType)
+	if transfer.VehicleType == models.VehicleSUV {
+		vehicle = "SUV"
+	}
+	switch {
+	case vehicle != "" && transfer.Capacity > 0:
+		return fmt.Sprintf("%s, seats %d", vehicle, transfer.Capacity)
+	case transfer.Capacity > 0:
+		return fmt.Sprintf("Seats %d", transfer.Capacity)
+	}
+	return vehicle
+}
+
+// operationsCopyLabel marks every page or section of an operations copy
+const operationsCopyLabel = "Operations copy - internal, not for clients"
+
+// internalNoteLines lists the internal notes of an itinerary's components,
+// each prefixed with the component it belongs to
+func internalNoteLines(itinerary *models.Itinerary) []string {
+	var lines []string
+	add := func(label, note string) {
+		if note != "" {
+			lines = append(lines, label+": "+note)
+		}
+	}
+	for _, hotel := range itinerary.Hotels {
+		add(hotel.Name, hotel.InternalNotes)
+	}
+	for idx, flight := range itinerary.Flights {
+		add(flightTitle(idx, flight), flight.InternalNotes)
+	}
+	for _, transfer := range itinerary.Transfers {
+		add(fmt.Sprintf("%s transfer from %s", toTitleCase(transfer.Mode), transfer.Pickup), transfer.InternalNotes)
+	}
+	for _, day := range itinerary.Days {
+		add(fmt.Sprintf("Day %d", day.DayNumber), day.InternalNotes)
+		for _, activity := range day.Activities {
+			add(fmt.Sprintf("Day %d, %s", day.DayNumber, activity.Title), activity.InternalNotes)
+		}
+	}
+	for _, installment := range sortedInstallments(itinerary.PaymentPlan) {
+		add(fmt.Sprintf("Installment #%d", installment.InstallmentNumber), installment.InternalNotes)
+	}
+	return lines
+}
+
+// contactLabel lists a transfer contact's name, phone and email
+func contactLabel(contact *models.TransferContact) string {
+	if contact == nil {
+		return ""
+	}
+	parts := []string{contact.Name}
+	for _, value := range []string{contact.Phone, contact.Email} {
+		if value != "" {
+			parts = append(parts, value)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFromAccept(t *testing.T) {
+	registry := NewRendererRegistry(&PDFService{})
+	for accept, want := range map[string]string{
+		"application/pdf":                              FormatPDF,
+		"text/html, application/pdf":                   FormatHTML,
+		"text/html;q=0, application/pdf":               FormatPDF,
+		"text/html; q=0.5, text/markdown; q=0.8":       FormatMarkdown,
+		"application/pdf;q=0.9, text/html;Q=0.9":       FormatPDF,
+		"text/html;level=1;q=0.2, application/pdf;q=1": FormatPDF,
+		"text/html;q=2, text/markdown":                 FormatMarkdown,
+		"application/json, */*":                        "",
+		"":                                             "",
+	} {
+		format, renderer, err := registry.FromAccept(accept)
+		if format != want || err != nil || (renderer == nil) != (want == "") {
+			t.Errorf("FromAccept(%q) = %q, %v; want %q", accept, format, err, want)
+		}
+	}
+}
+
+func TestNegotiateRefusedFormats(t *testing.T) {
+	registry := NewRendererRegistry(&PDFService{})
+	for _, accept := range []string{"application/pdf;q=0", "application/pdf;q=0, text/html;q=0, text/markdown;q=0"} {
+		if _, _, err := registry.Negotiate("", accept); !errors.Is(err, ErrNotAcceptable) {
+			t.Errorf("Negotiate(%q) = %v, want ErrNotAcceptable", accept, err)
+		}
+	}
+	for accept, want := range map[string]string{
+		"":                                   FormatPDF,
+		"application/json":                   FormatPDF,
+		"application/pdf;q=0, text/markdown": FormatMarkdown,
+	} {
+		if format, _, err := registry.Negotiate("", accept); format != want || err != nil {
+			t.Errorf("Negotiate(%q) = %q, %v; want %q", accept, format, err, want)
+		}
+	}
+	if format, _, err := registry.Negotiate("html", "application/pdf;q=0, text/html;q=0"); format != FormatHTML || err != nil {
+		t.Errorf("an explicit format gave %q, %v", format, err)
+	}
+}
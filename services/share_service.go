@@ -0,0 +1,207 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"vigovia-task/models"
+	"vigovia-task/storage"
+	"vigovia-task/utils"
+)
+
+// Errors for share links that exist but no longer open
+var (
+	ErrShareLinkRevoked = errors.New("this share link has been revoked")
+	ErrShareLinkExpired = errors.New("this share link has expired")
+)
+
+// errShareLinkNotFound is returned for unknown and forged tokens alike
+var errShareLinkNotFound = errors.New("share link not found")
+
+// ShareService creates signed share links that let clients view an
+// itinerary without an account
+type ShareService struct {
+	store  *storage.MemoryStore
+	secret []byte
+	mu     sync.Mutex // serialises view counting
+}
+
+// NewShareService creates a new instance of ShareService. Tokens are signed
+// with secret, so changing it invalidates every link.
+func NewShareService(store *storage.MemoryStore, secret []byte) *ShareService {
+	return &ShareService{
+		store:  store,
+		secret: secret,
+	}
+}
+
+// ShareLinkSecretFromEnv returns the signing secret named by
+// SHARE_LINK_SECRET. Without the variable a random secret is made, so links
+// stop working when the server restarts.
+func ShareLinkSecretFromEnv() ([]byte, error) {
+	if secret := strings.TrimSpace(os.Getenv("SHARE_LINK_SECRET")); secret != "" {
+		return []byte(secret), nil
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("generate share link secret: %w", err)
+	}
+	return secret, nil
+}
+
+// CreateLink creates a share link to an itinerary
+func (ss *ShareService) CreateLink(itineraryID, userID string, req *models.CreateShareLinkRequest) (*models.ShareLink, error) {
+	now := time.Now()
+	if err := utils.ValidateShareLink(req, now); err != nil {
+		return nil, err
+	}
+	if _, err := ss.store.GetByID(itineraryID); err != nil {
+		return nil, err
+	}
+
+	hidden := req.Hidden
+	if hidden == nil {
+		hidden = models.DefaultShareHidden
+	}
+
+	id := generateID("shr")
+	token := id + "." + ss.sign(id)
+	link := &models.ShareLink{
+		ID:          id,
+		ItineraryID: itineraryID,
+		Token:       token,
+		URL:         "/share/" + token,
+		Hidden:      append([]string{}, hidden...),
+		ExpiresAt:   req.ExpiresAt,
+		CreatedBy:   userID,
+		CreatedAt:   now,
+	}
+	if err := ss.store.CreateShareLink(link); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// ListLinks returns an itinerary's share links, revoked ones included
+func (ss *ShareService) ListLinks(itineraryID string) []*models.ShareLink {
+	return ss.store.GetShareLinksByItinerary(itineraryID)
+}
+
+// RevokeLink stops a share link from opening. Revoking it again changes
+// nothing.
+func (ss *ShareService) RevokeLink(itineraryID, linkID string) (*models.ShareLink, error) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	link, err := ss.store.GetShareLink(linkID)
+	if err != nil || link.ItineraryID != itineraryID {
+		return nil, fmt.Errorf("share link with id %s not found", linkID)
+	}
+	if !link.RevokedAt.IsZero() {
+		return link, nil
+	}
+
+	revoked := *link
+	revoked.RevokedAt = time.Now()
+	if err := ss.store.UpdateShareLink(&revoked); err != nil {
+		return nil, err
+	}
+	return &revoked, nil
+}
+
+// Open checks a token, counts the view and returns the shared itinerary with
+// the link's hidden parts removed and traveller details masked, along with
+// the ID of its owner, whose branding it is shown in
+func (ss *ShareService) Open(token string) (*models.Itinerary, string, error) {
+	id, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(ss.sign(id))) {
+		return nil, "", errShareLinkNotFound
+	}
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	link, err := ss.store.GetShareLink(id)
+	if err != nil {
+		return nil, "", errShareLinkNotFound
+	}
+	now := time.Now()
+	if !link.RevokedAt.IsZero() {
+		return nil, "", ErrShareLinkRevoked
+	}
+	if !link.ExpiresAt.IsZero() && !now.Before(link.ExpiresAt) {
+		return nil, "", ErrShareLinkExpired
+	}
+
+	itinerary, err := ss.store.GetByID(link.ItineraryID)
+	if err != nil {
+		return nil, "", errShareLinkNotFound
+	}
+
+	viewed := *link
+	viewed.ViewCount++
+	viewed.LastViewedAt = now
+	if err := ss.store.UpdateShareLink(&viewed); err != nil {
+		return nil, "", err
+	}
+	return redactItinerary(itinerary, viewed.Hidden), itinerary.UserID, nil
+}
+
+// sign returns the URL-safe HMAC-SHA256 signature of a link ID
+func (ss *ShareService) sign(id string) string {
+	mac := hmac.New(sha256.New, ss.secret)
+	mac.Write([]byte(id))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// redactItinerary returns a copy of the itinerary for viewers outside the
+// agency: the owner, organization, internal notes and hidden parts are
+// removed and traveller details are masked. The stored itinerary is left
+// unchanged.
+func redactItinerary(itinerary *models.Itinerary, hidden []string) *models.Itinerary {
+	hide := make(map[string]bool, len(hidden))
+	for _, part := range hidden {
+		hide[part] = true
+	}
+
+	redacted := *ClientView(itinerary)
+	redacted.UserID = ""
+	redacted.OrganizationID = ""
+
+	for i := range redacted.Transfers {
+		transfer := &redacted.Transfers[i]
+		if hide[models.ShareHidePrices] {
+			transfer.Price = 0
+			transfer.Currency = ""
+		}
+		if hide[models.ShareHideVendors] {
+			transfer.Vendor = nil
+		}
+		if hide[models.ShareHideNotes] {
+			transfer.Notes = ""
+		}
+	}
+	if hide[models.ShareHideBookingReferences] {
+		for i := range redacted.Hotels {
+			redacted.Hotels[i].ConfirmationNumber = ""
+		}
+		for i := range redacted.Flights {
+			redacted.Flights[i].BookingReference = ""
+		}
+	}
+	if hide[models.ShareHidePaymentPlan] {
+		redacted.PaymentPlan = nil
+	}
+	if hide[models.ShareHideWarnings] {
+		redacted.Warnings = nil
+	}
+	return &redacted
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"vigovia-task/models"
+	"vigovia-task/storage"
+)
+
+func TestShareTokens(t *testing.T) {
+	store := storage.NewMemoryStore()
+	shares := NewShareService(store, []byte("secret"))
+	itinerary := notedItinerary(t, store, "trip-1", "owner", "")
+
+	link, err := shares.CreateLink(itinerary.ID, "owner", &models.CreateShareLinkRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, signature, _ := strings.Cut(link.Token, ".")
+
+	shared, ownerID, err := shares.Open(link.Token)
+	if err != nil {
+		t.Fatalf("opening a valid token: %v", err)
+	}
+	if ownerID != "owner" || shared.ID != itinerary.ID || shared.UserID != "" || hasInternalNotes(shared) {
+		t.Errorf("shared itinerary %s of %q, user %q, internal notes %v", shared.ID, ownerID, shared.UserID, hasInternalNotes(shared))
+	}
+
+	forged := map[string]string{
+		"no signature":       id,
+		"altered signature":  id + "." + strings.Repeat("A", len(signature)),
+		"signature of other": "shr-other." + signature,
+		"other secret":       id + "." + NewShareService(store, []byte("other")).sign(id),
+	}
+	for name, token := range forged {
+		if _, _, err := shares.Open(token); !errors.Is(err, errShareLinkNotFound) {
+			t.Errorf("%s: Open = %v, want errShareLinkNotFound", name, err)
+		}
+	}
+
+	if stored, _ := store.GetShareLink(id); stored.ViewCount != 1 {
+		t.Errorf("view count = %d, want 1 for the one valid open", stored.ViewCount)
+	}
+}
+
+func TestShareLinkRevokedAndExpired(t *testing.T) {
+	store := storage.NewMemoryStore()
+	shares := NewShareService(store, []byte("secret"))
+	itinerary := notedItinerary(t, store, "trip-1", "owner", "")
+
+	revoked, err := shares.CreateLink(itinerary.ID, "owner", &models.CreateShareLinkRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := shares.RevokeLink(itinerary.ID, revoked.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := shares.Open(revoked.Token); !errors.Is(err, ErrShareLinkRevoked) {
+		t.Errorf("revoked link: Open = %v, want ErrShareLinkRevoked", err)
+	}
+
+	expiring, err := shares.CreateLink(itinerary.ID, "owner", &models.CreateShareLinkRequest{ExpiresAt: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := shares.Open(expiring.Token); err != nil {
+		t.Errorf("link before expiry: %v", err)
+	}
+	expired := *expiring
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	if err := store.UpdateShareLink(&expired); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := shares.Open(expiring.Token); !errors.Is(err, ErrShareLinkExpired) {
+		t.Errorf("expired link: Open = %v, want ErrShareLinkExpired", err)
+	}
+}
+
+func TestRedactItineraryHidesParts(t *testing.T) {
+	itinerary := &models.Itinerary{
+		ID:          "trip-1",
+		UserID:      "owner",
+		Transfers:   []models.Transfer{{Price: 1200, Currency: "INR", Notes: "driver Ravi"}},
+		Hotels:      []models.Hotel{{Name: "Taj Palace", ConfirmationNumber: "TAJ123"}},
+		PaymentPlan: []models.PaymentInstallment{{Amount: 1000}},
+	}
+
+	redacted := redactItinerary(itinerary, []string{models.ShareHidePrices, models.ShareHideBookingReferences, models.ShareHidePaymentPlan})
+	if redacted.Transfers[0].Price != 0 || redacted.Hotels[0].ConfirmationNumber != "" || redacted.PaymentPlan != nil {
+		t.Errorf("hidden parts shared: %+v", redacted)
+	}
+	if redacted.Transfers[0].Notes != "driver Ravi" {
+		t.Error("transfer notes were hidden without being asked to")
+	}
+	if itinerary.Transfers[0].Price != 1200 || itinerary.Hotels[0].ConfirmationNumber != "TAJ123" {
+		t.Error("redacting changed the stored itinerary")
+	}
+}
@@ -0,0 +1,248 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"vigovia-task/models"
+	"vigovia-task/storage"
+	"vigovia-task/utils"
+)
+
+// TemplateService saves itineraries as reusable templates and creates new
+// itineraries from them
+type TemplateService struct {
+	store       *storage.MemoryStore
+	itineraries *ItineraryService
+	access      *AccessService
+}
+
+// NewTemplateService creates a new instance of TemplateService
+func NewTemplateService(store *storage.MemoryStore, itineraries *ItineraryService, access *AccessService) *TemplateService {
+	return &TemplateService{
+		store:       store,
+		itineraries: itineraries,
+		access:      access,
+	}
+}
+
+// CreateTemplate saves a copy of an itinerary userID can view as a template
+// owned by userID. Travellers are left out, as a template is sold to new
+// clients.
+func (ts *TemplateService) CreateTemplate(userID string, req *models.CreateTemplateRequest) (*models.ItineraryTemplate, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	for i, tag := range req.Tags {
+		req.Tags[i] = strings.ToLower(strings.TrimSpace(tag))
+	}
+	if err := utils.ValidateTemplate(req); err != nil {
+		return nil, err
+	}
+
+	itinerary, role, err := ts.access.Authorize(req.ItineraryID, userID, models.PermissionView)
+	if err != nil {
+		return nil, err
+	}
+	if !SeesInternalNotes(role) {
+		itinerary = itinerary.WithoutInternalNotes()
+	}
+
+	content, err := itineraryContent(itinerary)
+	if err != nil {
+		return nil, err
+	}
+	content.UserID = ""
+	dropTravellers(content)
+
+	now := time.Now()
+	template := &models.ItineraryTemplate{
+		ID:                generateID("tpl"),
+		UserID:            userID,
+		Name:              req.Name,
+		Description:       strings.TrimSpace(req.Description),
+		Tags:              req.Tags,
+		SourceItineraryID: itinerary.ID,
+		BaseDate:          calendarDate(itinerary.StartDate),
+		DurationDays:      tripDays(itinerary),
+		Itinerary:         *content,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+	if err := ts.store.CreateTemplate(template); err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+// GetTemplate retrieves a template owned by userID
+func (ts *TemplateService) GetTemplate(userID, id string) (*models.ItineraryTemplate, error) {
+	template, err := ts.store.GetTemplate(id)
+	if err != nil {
+		return nil, err
+	}
+	if template.UserID != userID {
+		return nil, fmt.Errorf("template with id %s not found", id)
+	}
+	return template, nil
+}
+
+// ListTemplates returns userID's templates by name. A query keeps those whose
+// name, description, location or tags contain it, ignoring case; a tag keeps
+// those carrying it.
+func (ts *TemplateService) ListTemplates(userID, query, tag string) []*models.ItineraryTemplate {
+	query = strings.ToLower(strings.TrimSpace(query))
+	tag = strings.ToLower(strings.TrimSpace(tag))
+
+	matches := []*models.ItineraryTemplate{}
+	for _, template := range ts.store.GetTemplatesByUser(userID) {
+		if tag != "" && !containsString(template.Tags, tag) {
+			continue
+		}
+		if query != "" && !templateMatches(template, query) {
+			continue
+		}
+		matches = append(matches, template)
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if !strings.EqualFold(matches[i].Name, matches[j].Name) {
+			return strings.ToLower(matches[i].Name) < strings.ToLower(matches[j].Name)
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	return matches
+}
+
+// DeleteTemplate deletes a template owned by userID
+func (ts *TemplateService) DeleteTemplate(userID, id string) error {
+	if _, err := ts.GetTemplate(userID, id); err != nil {
+		return err
+	}
+	return ts.store.DeleteTemplate(id)
+}
+
+// Instantiate creates an itinerary for userID from a template, moving every
+// date by the days between the template's base date and the new start date
+func (ts *TemplateService) Instantiate(userID, id string, req *models.InstantiateTemplateRequest) (*models.Itinerary, error) {
+	template, err := ts.GetTemplate(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := copyContent(&template.Itinerary)
+	if err != nil {
+		return nil, err
+	}
+	shiftDates(content, daysBetween(template.BaseDate, req.StartDate))
+	content.UserID = userID
+	if title := strings.TrimSpace(req.Title); title != "" {
+		content.Title = title
+	}
+
+	return ts.itineraries.CreateItinerary(content)
+}
+
+func templateMatches(template *models.ItineraryTemplate, query string) bool {
+	fields := append([]string{template.Name, template.Description, template.Itinerary.Location}, template.Tags...)
+	for _, field := range fields {
+		if strings.Contains(strings.ToLower(field), query) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// itineraryContent copies an itinerary's content into a create request that
+// shares no slices or pointers with it
+func itineraryContent(itinerary *models.Itinerary) (*models.CreateItineraryRequest, error) {
+	return copyContent(&models.CreateItineraryRequest{
+		UserID:        itinerary.UserID,
+		Title:         itinerary.Title,
+		Description:   itinerary.Description,
+		StartDate:     itinerary.StartDate,
+		EndDate:       itinerary.EndDate,
+		Location:      itinerary.Location,
+		Destination:   itinerary.Destination,
+		Hotels:        itinerary.Hotels,
+		Flights:       itinerary.Flights,
+		Transfers:     itinerary.Transfers,
+		Days:          itinerary.Days,
+		PaymentPlan:   itinerary.PaymentPlan,
+		Inclusions:    itinerary.Inclusions,
+		Exclusions:    itinerary.Exclusions,
+		Travellers:    itinerary.Travellers,
+		InternalNotes: itinerary.InternalNotes,
+	})
+}
+
+// dropTravellers removes the travellers and their flight and room assignments
+func dropTravellers(content *models.CreateItineraryRequest) {
+	content.Travellers = nil
+	for i := range content.Flights {
+		content.Flights[i].TravellerIDs = nil
+	}
+	for i := range content.Hotels {
+		for j := range content.Hotels[i].Rooms {
+			content.Hotels[i].Rooms[j].TravellerIDs = nil
+		}
+	}
+}
+
+// copyContent deep-copies a create request through its JSON form
+func copyContent(content *models.CreateItineraryRequest) (*models.CreateItineraryRequest, error) {
+	data, err := json.Marshal(content)
+	if err != nil {
+		return nil, fmt.Errorf("copy itinerary: %w", err)
+	}
+	var copied models.CreateItineraryRequest
+	if err := json.Unmarshal(data, &copied); err != nil {
+		return nil, fmt.Errorf("copy itinerary: %w", err)
+	}
+	return &copied, nil
+}
+
+// shiftDates moves every trip date by whole days, keeping times of day.
+// Traveller birth dates and passport expiries are not trip dates.
+func shiftDates(content *models.CreateItineraryRequest, days int) {
+	if days == 0 {
+		return
+	}
+	shift := func(t time.Time) time.Time {
+		if t.IsZero() {
+			return t
+		}
+		return t.AddDate(0, 0, days)
+	}
+
+	content.StartDate = shift(content.StartDate)
+	content.EndDate = shift(content.EndDate)
+	for i := range content.Days {
+		content.Days[i].Date = shift(content.Days[i].Date)
+	}
+	for i := range content.Hotels {
+		content.Hotels[i].CheckIn = shift(content.Hotels[i].CheckIn)
+		content.Hotels[i].CheckOut = shift(content.Hotels[i].CheckOut)
+	}
+	for i := range content.Flights {
+		content.Flights[i].DepartureTime = shift(content.Flights[i].DepartureTime)
+		content.Flights[i].ArrivalTime = shift(content.Flights[i].ArrivalTime)
+	}
+	for i := range content.Transfers {
+		content.Transfers[i].PickupAt = shift(content.Transfers[i].PickupAt)
+	}
+	for i := range content.PaymentPlan {
+		content.PaymentPlan[i].DueDate = shift(content.PaymentPlan[i].DueDate)
+	}
+}
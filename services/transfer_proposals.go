@@ -0,0 +1,258 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"vigovia-task/models"
+	"vigovia-task/utils"
+)
+
+// Buffers used to time proposed transfers
+const (
+	domesticArrivalBuffer      = 30 // minutes from landing to pickup
+	internationalArrivalBuffer = 60
+	domesticCheckInBuffer      = 2 * time.Hour // at the airport before departure
+	internationalCheckInBuffer = 3 * time.Hour
+	airportDriveTime           = time.Hour
+)
+
+// stayMatchWindow is how far apart a flight and a hotel check-in or
+// check-out may be for the flight to count as the way to or from the stay
+const stayMatchWindow = 36 * time.Hour
+
+// proposeTransfers suggests the airport pickups, departure drop-offs and
+// moves between hotels that an itinerary's flights and stays call for,
+// leaving out those its transfers already cover
+func proposeTransfers(itinerary *models.Itinerary) []models.TransferProposal {
+	stays := append([]models.Hotel(nil), itinerary.Hotels...)
+	sort.SliceStable(stays, func(i, j int) bool {
+		return stays[i].CheckIn.Before(stays[j].CheckIn)
+	})
+	trips := flightTrips(itinerary.Flights)
+	vehicle := suggestedVehicle(len(itinerary.Travellers))
+
+	arrivals := make(map[int]flightTrip)
+	departures := make(map[int]flightTrip)
+	for _, trip := range trips {
+		if idx, ok := closestStay(stays, trip.Last.ArrivalTime, func(stay models.Hotel) time.Time { return stay.CheckIn }); ok {
+			if current, taken := arrivals[idx]; !taken || trip.Last.ArrivalTime.After(current.Last.ArrivalTime) {
+				arrivals[idx] = trip
+			}
+		}
+		if idx, ok := closestStay(stays, trip.First.DepartureTime, func(stay models.Hotel) time.Time { return stay.CheckOut }); ok {
+			if current, taken := departures[idx]; !taken || trip.First.DepartureTime.Before(current.First.DepartureTime) {
+				departures[idx] = trip
+			}
+		}
+	}
+
+	var proposals []models.TransferProposal
+	for i, stay := range stays {
+		if trip, ok := arrivals[i]; ok {
+			proposals = append(proposals, arrivalProposal(trip, stay, vehicle))
+		}
+		if trip, ok := departures[i]; ok {
+			proposals = append(proposals, departureProposal(trip, stay, vehicle))
+		}
+	}
+	for i := 1; i < len(stays); i++ {
+		from, to := stays[i-1], stays[i]
+		_, flies := departures[i-1]
+		if flies || strings.EqualFold(from.Name, to.Name) {
+			continue
+		}
+		proposals = append(proposals, intercityProposal(from, to, vehicle))
+	}
+
+	// Each open proposal covers later ones as an existing transfer would, so
+	// the same trip is only proposed once
+	covered := append([]models.Transfer(nil), itinerary.Transfers...)
+	var open []models.TransferProposal
+	for _, proposal := range proposals {
+		transfers := normalizeTransfers([]models.Transfer{proposal.Transfer}, itinerary.Flights, itinerary.Hotels)
+		proposal.Transfer = transfers[0]
+		// The transfer gets its ID when the proposal is accepted
+		proposal.Transfer.ID = ""
+		if !transferCovered(proposal.Transfer, covered) {
+			open = append(open, proposal)
+			covered = append(covered, proposal.Transfer)
+		}
+	}
+	return open
+}
+
+// flightTrip is a single flight or a whole connecting journey, from the
+// first leg's departure to the last leg's arrival
+type flightTrip struct {
+	First models.Flight
+	Last  models.Flight
+}
+
+func flightTrips(flights []models.Flight) []flightTrip {
+	groups := groupFlights(flights)
+	trips := make([]flightTrip, 0, len(groups))
+	for _, group := range groups {
+		trips = append(trips, flightTrip{
+			First: group.Legs[0].Flight,
+			Last:  group.Legs[len(group.Legs)-1].Flight,
+		})
+	}
+	return trips
+}
+
+// international reports whether a trip crosses a border. Unknown airports
+// count as international so the longer buffers apply.
+func (t flightTrip) international() bool {
+	origin, ok := utils.LookupAirport(t.First.DepartureAirport)
+	if !ok {
+		return true
+	}
+	destination, ok := utils.LookupAirport(t.Last.ArrivalAirport)
+	return !ok || origin.CountryCode != destination.CountryCode
+}
+
+// closestStay finds the stay whose check-in or check-out, as picked by at,
+// is nearest to a flight time and within stayMatchWindow of it. Ties go to
+// the earlier stay.
+func closestStay(stays []models.Hotel, flightTime time.Time, at func(models.Hotel) time.Time) (int, bool) {
+	best, found := 0, false
+	for i, stay := range stays {
+		gap := absDuration(at(stay).Sub(flightTime))
+		if gap > stayMatchWindow {
+			continue
+		}
+		if !found || gap < absDuration(at(stays[best]).Sub(flightTime)) {
+			best, found = i, true
+		}
+	}
+	return best, found
+}
+
+func arrivalProposal(trip flightTrip, stay models.Hotel, vehicle string) models.TransferProposal {
+	flight := trip.Last
+	buffer := domesticArrivalBuffer
+	if trip.international() {
+		buffer = internationalArrivalBuffer
+	}
+
+	transfer := models.Transfer{
+		Mode:                      "private car",
+		FlightNumber:              flight.FlightNumber,
+		PickupAt:                  flight.ArrivalTime.Add(time.Duration(buffer) * time.Minute),
+		PickupAfterLandingMinutes: &buffer,
+		Dropoff:                   stay.Name,
+		VehicleType:               vehicle,
+	}
+	// A transfer linked to the hotel must pick up before check-in
+	if transfer.PickupAt.Before(stay.CheckIn) {
+		transfer.HotelName = stay.Name
+	}
+
+	return models.TransferProposal{
+		ID:   proposalID(models.TransferProposalArrival, flight.FlightNumber, flight.ArrivalTime),
+		Kind: models.TransferProposalArrival,
+		Reason: fmt.Sprintf("%s lands at %s on %s; pickup %s later for arrival formalities, then on to %s",
+			flight.FlightNumber, flight.ArrivalAirport, formatDateTime(flight.ArrivalTime), formatMinutes(buffer), stay.Name),
+		Transfer: transfer,
+	}
+}
+
+func departureProposal(trip flightTrip, stay models.Hotel, vehicle string) models.TransferProposal {
+	flight := trip.First
+	checkIn := domesticCheckInBuffer
+	if trip.international() {
+		checkIn = internationalCheckInBuffer
+	}
+
+	return models.TransferProposal{
+		ID:   proposalID(models.TransferProposalDeparture, flight.FlightNumber, flight.DepartureTime),
+		Kind: models.TransferProposalDeparture,
+		Reason: fmt.Sprintf("%s departs %s on %s; pickup allows %s to reach the airport and %s there before departure",
+			flight.FlightNumber, flight.DepartureAirport, formatDateTime(flight.DepartureTime),
+			formatMinutes(int(airportDriveTime.Minutes())), formatMinutes(int(checkIn.Minutes()))),
+		Transfer: models.Transfer{
+			Mode:        "private car",
+			Pickup:      stay.Name,
+			Dropoff:     airportLabel(airportName(flight.DepartureAirport), flight.DepartureTerminal),
+			PickupAt:    flight.DepartureTime.Add(-checkIn - airportDriveTime),
+			VehicleType: vehicle,
+			Notes:       "Drop-off for flight " + flight.FlightNumber,
+		},
+	}
+}
+
+func intercityProposal(from, to models.Hotel, vehicle string) models.TransferProposal {
+	transfer := models.Transfer{
+		Mode:        "private car",
+		Pickup:      from.Name,
+		Dropoff:     to.Name,
+		PickupAt:    from.CheckOut,
+		VehicleType: vehicle,
+	}
+	if from.CheckOut.Before(to.CheckIn) {
+		transfer.HotelName = to.Name
+	}
+
+	return models.TransferProposal{
+		ID:   proposalID(models.TransferProposalIntercity, from.Name, from.CheckOut),
+		Kind: models.TransferProposalIntercity,
+		Reason: fmt.Sprintf("Check-out from %s on %s and check-in at %s on %s with no flight between them",
+			from.Name, formatDateTime(from.CheckOut), to.Name, formatDateTime(to.CheckIn)),
+		Transfer: transfer,
+	}
+}
+
+// proposalID names a proposal after its kind, flight or hotel and date, e.g.
+// "arrival-ek073-20241114"
+func proposalID(kind, subject string, at time.Time) string {
+	slug := strings.Join(strings.FieldsFunc(strings.ToLower(subject), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	}), "-")
+	return fmt.Sprintf("%s-%s-%s", kind, slug, at.Format("20060102"))
+}
+
+// suggestedVehicle picks the smallest vehicle that seats the travellers
+func suggestedVehicle(travellers int) string {
+	switch {
+	case travellers == 0:
+		return ""
+	case travellers <= 3:
+		return models.VehicleSedan
+	case travellers <= 6:
+		return models.VehicleSUV
+	case travellers <= 10:
+		return models.VehicleVan
+	case travellers <= 20:
+		return models.VehicleMinibus
+	default:
+		return models.VehicleCoach
+	}
+}
+
+// transferCovered reports whether an existing transfer meets the same flight
+// or makes the same trip on the day of the proposed pickup. A transfer
+// without a pickup date covers the trip on any day.
+func transferCovered(proposed models.Transfer, transfers []models.Transfer) bool {
+	for _, transfer := range transfers {
+		if !transfer.PickupAt.IsZero() && !proposed.PickupAt.IsZero() && !calendarDate(transfer.PickupAt).Equal(calendarDate(proposed.PickupAt)) {
+			continue
+		}
+		if proposed.FlightNumber != "" && transfer.FlightNumber == proposed.FlightNumber {
+			return true
+		}
+		if strings.EqualFold(transfer.Pickup, proposed.Pickup) && strings.EqualFold(transfer.Dropoff, proposed.Dropoff) {
+			return true
+		}
+	}
+	return false
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
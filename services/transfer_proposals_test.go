@@ -0,0 +1,100 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"vigovia-task/models"
+)
+
+// proposalTrip flies into Mumbai, stays at two hotels and flies home from Goa
+func proposalTrip() *models.Itinerary {
+	at := func(day, hour int) time.Time { return time.Date(2026, 12, day, hour, 0, 0, 0, time.UTC) }
+	return &models.Itinerary{
+		Flights: []models.Flight{
+			{ID: "f1", FlightNumber: "EK500", DepartureAirport: "DXB", DepartureTime: at(1, 4), ArrivalAirport: "BOM", ArrivalTime: at(1, 9)},
+			{ID: "f2", FlightNumber: "6E100", DepartureAirport: "GOI", DepartureTime: at(5, 15), ArrivalAirport: "DEL", ArrivalTime: at(5, 18)},
+		},
+		Hotels: []models.Hotel{
+			{ID: "h1", Name: "Taj Mahal Palace", CheckIn: at(1, 14), CheckOut: at(3, 11)},
+			{ID: "h2", Name: "Taj Exotica", CheckIn: at(3, 18), CheckOut: at(5, 10)},
+		},
+	}
+}
+
+func proposalKinds(proposals []models.TransferProposal) []string {
+	kinds := make([]string, len(proposals))
+	for i, proposal := range proposals {
+		kinds[i] = proposal.Kind
+	}
+	return kinds
+}
+
+func TestProposeTransfers(t *testing.T) {
+	proposals := proposeTransfers(proposalTrip())
+
+	want := []string{models.TransferProposalArrival, models.TransferProposalDeparture, models.TransferProposalIntercity}
+	if got := proposalKinds(proposals); len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Fatalf("proposal kinds = %v, want %v", got, want)
+	}
+	arrival := proposals[0].Transfer
+	if arrival.FlightNumber != "EK500" || arrival.Dropoff != "Taj Mahal Palace" || !arrival.PickupAt.Equal(time.Date(2026, 12, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("arrival transfer = %+v, want EK500 to Taj Mahal Palace an hour after landing", arrival)
+	}
+	// Domestic flight: two hours at the airport and an hour's drive
+	if departure := proposals[1].Transfer; !departure.PickupAt.Equal(time.Date(2026, 12, 5, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("departure pickup at %v, want 12:00", departure.PickupAt)
+	}
+	if intercity := proposals[2].Transfer; intercity.Pickup != "Taj Mahal Palace" || intercity.Dropoff != "Taj Exotica" {
+		t.Errorf("intercity transfer from %q to %q", intercity.Pickup, intercity.Dropoff)
+	}
+}
+
+func TestProposeTransfersOncePerTrip(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2026, 12, 3, hour, 0, 0, 0, time.UTC) }
+	itinerary := proposalTrip()
+	// Day rooms shuttle the group between the two hotels twice on the 3rd
+	itinerary.Hotels = []models.Hotel{
+		{ID: "h1", Name: "Taj Mahal Palace", CheckIn: time.Date(2026, 12, 1, 14, 0, 0, 0, time.UTC), CheckOut: at(9)},
+		{ID: "h2", Name: "Taj Exotica", CheckIn: at(10), CheckOut: at(12)},
+		{ID: "h3", Name: "Taj Mahal Palace", CheckIn: at(13), CheckOut: at(15)},
+		{ID: "h4", Name: "Taj Exotica", CheckIn: at(16), CheckOut: time.Date(2026, 12, 5, 10, 0, 0, 0, time.UTC)},
+	}
+
+	proposals := proposeTransfers(itinerary)
+	ids := make(map[string]bool)
+	for _, proposal := range proposals {
+		if ids[proposal.ID] {
+			t.Errorf("proposal %s is listed twice", proposal.ID)
+		}
+		ids[proposal.ID] = true
+	}
+	// The arrival, the departure and one move each way between the hotels
+	if len(proposals) != 4 {
+		t.Errorf("%d proposals, want 4: %v", len(proposals), proposalKinds(proposals))
+	}
+}
+
+func TestTransferCoveredOnTheSameDay(t *testing.T) {
+	itinerary := proposalTrip()
+	move := func(day int) models.Transfer {
+		return models.Transfer{ID: "t1", Pickup: "taj mahal palace", Dropoff: "Taj Exotica", PickupAt: time.Date(2026, 12, day, 9, 0, 0, 0, time.UTC)}
+	}
+
+	for _, tc := range []struct {
+		name     string
+		transfer models.Transfer
+		want     int
+	}{
+		{"another day's transfer on the route", move(2), 3},
+		{"the same day's transfer on the route", move(3), 2},
+		{"an undated transfer on the route", models.Transfer{ID: "t1", Pickup: "Taj Mahal Palace", Dropoff: "Taj Exotica"}, 2},
+		{"another day's pickup for the flight", models.Transfer{ID: "t1", FlightNumber: "EK500", PickupAt: time.Date(2026, 12, 2, 10, 0, 0, 0, time.UTC)}, 3},
+		{"the flight's pickup", models.Transfer{ID: "t1", FlightNumber: "EK500", PickupAt: time.Date(2026, 12, 1, 10, 0, 0, 0, time.UTC)}, 2},
+	} {
+		itinerary.Transfers = []models.Transfer{tc.transfer}
+		if got := len(proposeTransfers(itinerary)); got != tc.want {
+			t.Errorf("with %s: %d proposals, want %d", tc.name, got, tc.want)
+		}
+	}
+}
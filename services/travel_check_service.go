@@ -0,0 +1,225 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"vigovia-task/assets"
+	"vigovia-task/models"
+	"vigovia-task/utils"
+)
+
+// bundledVisaRules names the embedded rules used when VISA_RULES_FILE is not
+// set
+const bundledVisaRules = "reference/visa_rules.json"
+
+// passportValidityMonths is how long many countries expect a passport to
+// remain valid after the traveller leaves
+const passportValidityMonths = 6
+
+// TravelCheckService checks travellers' passports and visa requirements
+// against an itinerary's dates and destination, and its flight connections
+type TravelCheckService struct {
+	rules map[string]models.VisaRule
+}
+
+// NewTravelCheckService creates a TravelCheckService using the given visa rules
+func NewTravelCheckService(rules []models.VisaRule) *TravelCheckService {
+	indexed := make(map[string]models.VisaRule, len(rules))
+	for _, rule := range rules {
+		indexed[visaRuleKey(rule.Nationality, rule.Destination)] = rule
+	}
+	return &TravelCheckService{rules: indexed}
+}
+
+// VisaRulesFromEnv loads the rules file named by VISA_RULES_FILE, or the
+// rules embedded in the binary without the variable
+func VisaRulesFromEnv() ([]models.VisaRule, error) {
+	if path := strings.TrimSpace(os.Getenv("VISA_RULES_FILE")); path != "" {
+		return LoadVisaRules(path)
+	}
+	data, err := fs.ReadFile(assets.Reference, bundledVisaRules)
+	if err != nil {
+		return nil, fmt.Errorf("read bundled visa rules: %w", err)
+	}
+	return parseVisaRules(data, bundledVisaRules)
+}
+
+// LoadVisaRules reads and validates a JSON visa rules file
+func LoadVisaRules(path string) ([]models.VisaRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read visa rules: %w", err)
+	}
+	return parseVisaRules(data, path)
+}
+
+// parseVisaRules validates JSON visa rules read from source
+func parseVisaRules(data []byte, source string) ([]models.VisaRule, error) {
+	var ruleSet models.VisaRuleSet
+	if err := json.Unmarshal(data, &ruleSet); err != nil {
+		return nil, fmt.Errorf("parse visa rules %s: %w", source, err)
+	}
+
+	seen := make(map[string]bool, len(ruleSet.Rules))
+	for i := range ruleSet.Rules {
+		rule := &ruleSet.Rules[i]
+		rule.Nationality = strings.ToUpper(strings.TrimSpace(rule.Nationality))
+		rule.Destination = strings.ToUpper(strings.TrimSpace(rule.Destination))
+		rule.Requirement = strings.ToLower(strings.TrimSpace(rule.Requirement))
+		if err := utils.ValidateVisaRule(rule); err != nil {
+			return nil, fmt.Errorf("%s: %w", source, err)
+		}
+
+		key := visaRuleKey(rule.Nationality, rule.Destination)
+		if seen[key] {
+			return nil, fmt.Errorf("%s: duplicate visa rule %s->%s", source, rule.Nationality, rule.Destination)
+		}
+		seen[key] = true
+	}
+
+	return ruleSet.Rules, nil
+}
+
+// Check returns the passport and visa warnings of every traveller on the
+// itinerary followed by the warnings about its connections and airports
+func (tc *TravelCheckService) Check(itinerary *models.Itinerary) []models.TravelWarning {
+	var warnings []models.TravelWarning
+	for _, traveller := range itinerary.Travellers {
+		if warning, ok := checkPassport(itinerary, traveller); ok {
+			warnings = append(warnings, warning)
+		}
+		if warning, ok := tc.checkVisa(itinerary, traveller); ok {
+			warnings = append(warnings, warning)
+		}
+	}
+	warnings = append(warnings, connectionWarnings(itinerary.Flights)...)
+	return append(warnings, unknownAirportWarnings(itinerary.Flights)...)
+}
+
+// unknownAirportWarnings flags each airport code missing from the reference
+// data once, naming the first flight that uses it
+func unknownAirportWarnings(flights []models.Flight) []models.TravelWarning {
+	var warnings []models.TravelWarning
+	flagged := make(map[string]bool)
+	for _, flight := range flights {
+		for _, code := range []string{flight.DepartureAirport, flight.ArrivalAirport} {
+			if _, ok := utils.LookupAirport(code); ok || flagged[code] {
+				continue
+			}
+			flagged[code] = true
+			warnings = append(warnings, models.TravelWarning{
+				Code:     "unknown_airport",
+				Severity: models.WarningSeverityWarning,
+				Message:  fmt.Sprintf("Airport %s of flight %s is not in the reference data; check the code", code, flight.FlightNumber),
+			})
+		}
+	}
+	return warnings
+}
+
+// checkPassport flags passports that expire during the trip or within six months after it
+func checkPassport(itinerary *models.Itinerary, traveller models.Traveller) (models.TravelWarning, bool) {
+	expiry := traveller.PassportExpiry
+	if !expiry.After(itinerary.EndDate) {
+		return models.TravelWarning{
+			Code:        "passport_expired",
+			Severity:    models.WarningSeverityError,
+			TravellerID: traveller.ID,
+			Message: fmt.Sprintf("Passport of %s expires on %s, before the trip ends on %s",
+				traveller.FullName(), formatDate(expiry), formatDate(itinerary.EndDate)),
+		}, true
+	}
+
+	if expiry.Before(itinerary.EndDate.AddDate(0, passportValidityMonths, 0)) {
+		return models.TravelWarning{
+			Code:        "passport_validity",
+			Severity:    models.WarningSeverityWarning,
+			TravellerID: traveller.ID,
+			Message: fmt.Sprintf("Passport of %s expires on %s, less than %d months after the trip ends",
+				traveller.FullName(), formatDate(expiry), passportValidityMonths),
+		}, true
+	}
+
+	return models.TravelWarning{}, false
+}
+
+// checkVisa applies the visa rule for the traveller's nationality and the
+// itinerary's destination country
+func (tc *TravelCheckService) checkVisa(itinerary *models.Itinerary, traveller models.Traveller) (models.TravelWarning, bool) {
+	destination := itinerary.Destination
+	if destination == "" || traveller.Nationality == destination {
+		return models.TravelWarning{}, false
+	}
+
+	warning := models.TravelWarning{TravellerID: traveller.ID}
+	rule, ok := tc.rule(traveller.Nationality, destination)
+	if !ok {
+		warning.Code = "visa_rule_missing"
+		warning.Severity = models.WarningSeverityInfo
+		warning.Message = fmt.Sprintf("No visa rule for %s nationals travelling to %s; check entry requirements for %s",
+			traveller.Nationality, destination, traveller.FullName())
+		return warning, true
+	}
+
+	switch rule.Requirement {
+	case models.VisaRequired:
+		warning.Code = "visa_required"
+		warning.Severity = models.WarningSeverityWarning
+		warning.Message = fmt.Sprintf("%s needs a visa for %s", traveller.FullName(), destination)
+	case models.VisaElectronic:
+		warning.Code = "e_visa_required"
+		warning.Severity = models.WarningSeverityWarning
+		warning.Message = fmt.Sprintf("%s needs an e-visa for %s before departure", traveller.FullName(), destination)
+	case models.VisaOnArrival:
+		warning.Code = "visa_on_arrival"
+		warning.Severity = models.WarningSeverityInfo
+		warning.Message = fmt.Sprintf("%s can get a visa on arrival in %s", traveller.FullName(), destination)
+	default:
+		days := tripDays(itinerary)
+		if rule.MaxStayDays == 0 || days <= rule.MaxStayDays {
+			return models.TravelWarning{}, false
+		}
+		warning.Code = "visa_free_stay_exceeded"
+		warning.Severity = models.WarningSeverityWarning
+		warning.Message = fmt.Sprintf("%s may stay visa-free in %s for %d days but the trip lasts %d days",
+			traveller.FullName(), destination, rule.MaxStayDays, days)
+	}
+
+	if rule.Notes != "" {
+		warning.Message += ". " + rule.Notes
+	}
+	return warning, true
+}
+
+// rule finds the rule for a nationality, falling back to the destination's "*" rule
+func (tc *TravelCheckService) rule(nationality, destination string) (models.VisaRule, bool) {
+	if rule, ok := tc.rules[visaRuleKey(nationality, destination)]; ok {
+		return rule, true
+	}
+	rule, ok := tc.rules[visaRuleKey("*", destination)]
+	return rule, ok
+}
+
+func visaRuleKey(nationality, destination string) string {
+	return nationality + "->" + destination
+}
+
+// tripDays counts the calendar days from the start to the end date inclusive
+func tripDays(itinerary *models.Itinerary) int {
+	return daysBetween(itinerary.StartDate, itinerary.EndDate) + 1
+}
+
+// calendarDate is midnight UTC on the date of t
+func calendarDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// daysBetween counts the calendar days from one date to another
+func daysBetween(from, to time.Time) int {
+	return int(calendarDate(to).Sub(calendarDate(from)).Hours() / 24)
+}
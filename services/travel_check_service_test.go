@@ -0,0 +1,80 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"vigovia-task/models"
+)
+
+func TestBundledVisaRules(t *testing.T) {
+	t.Setenv("VISA_RULES_FILE", "")
+	rules, err := VisaRulesFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) == 0 {
+		t.Fatal("no bundled visa rules")
+	}
+	for _, rule := range rules {
+		if rule.Nationality == "*" {
+			t.Errorf("bundled rule for every nationality travelling to %s", rule.Destination)
+		}
+	}
+}
+
+func TestVisaRulesFileMustExist(t *testing.T) {
+	t.Setenv("VISA_RULES_FILE", t.TempDir()+"/missing.json")
+	if _, err := VisaRulesFromEnv(); err == nil {
+		t.Error("a missing VISA_RULES_FILE was accepted")
+	}
+}
+
+func TestVisaChecks(t *testing.T) {
+	t.Setenv("VISA_RULES_FILE", "")
+	rules, err := VisaRulesFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checks := NewTravelCheckService(rules)
+	start := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+	passportExpiry := start.AddDate(5, 0, 0)
+
+	for _, tc := range []struct {
+		nationality, destination string
+		days                     int
+		want                     string
+	}{
+		{"IN", "TH", 10, ""},
+		{"IN", "TH", 70, "visa_free_stay_exceeded"},
+		{"IN", "FR", 7, "visa_required"},
+		{"GB", "FR", 7, "visa_rule_missing"},
+		{"BR", "TH", 7, "visa_rule_missing"},
+		{"IN", "IN", 7, ""},
+	} {
+		itinerary := &models.Itinerary{
+			Destination: tc.destination,
+			StartDate:   start,
+			EndDate:     start.AddDate(0, 0, tc.days-1),
+			Travellers:  []models.Traveller{{ID: "t1", FirstName: "Asha", LastName: "Rao", Nationality: tc.nationality, PassportExpiry: passportExpiry}},
+		}
+		got := ""
+		if warnings := checks.Check(itinerary); len(warnings) > 0 {
+			got = warnings[0].Code
+		}
+		if got != tc.want {
+			t.Errorf("%s national in %s for %d days: warning %q, want %q", tc.nationality, tc.destination, tc.days, got, tc.want)
+		}
+	}
+}
+
+func TestUnknownAirportWarnings(t *testing.T) {
+	flights := []models.Flight{
+		{FlightNumber: "AI101", DepartureAirport: "DEL", ArrivalAirport: "AGR"},
+		{FlightNumber: "AI102", DepartureAirport: "AGR", ArrivalAirport: "DEL"},
+	}
+	warnings := unknownAirportWarnings(flights)
+	if len(warnings) != 1 || warnings[0].Code != "unknown_airport" {
+		t.Fatalf("warnings = %+v, want one unknown_airport warning for AGR", warnings)
+	}
+}
@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ErrDocumentNotFound is returned when a key is missing from a DocumentStore
+var ErrDocumentNotFound = errors.New("document not found")
+
+// DocumentStore persists exported files by key
+type DocumentStore interface {
+	// Put stores data under key, replacing any existing object
+	Put(key string, data []byte, contentType string) error
+	// Get returns the data stored under key or ErrDocumentNotFound
+	Get(key string) ([]byte, error)
+	// Exists reports whether key is present
+	Exists(key string) (bool, error)
+}
+
+// Document storage backends selectable with DOCUMENT_STORE
+const (
+	DocumentStoreLocal = "local"
+	DocumentStoreS3    = "s3"
+)
+
+// DocumentStoreFromEnv builds the document store configured by the
+// environment:
+//
+//	DOCUMENT_STORE        local (default) or s3
+//	DOCUMENT_ROOT         root directory of the local store (default "output")
+//	S3_ENDPOINT           e.g. https://s3.eu-west-1.amazonaws.com or http://localhost:9000
+//	S3_BUCKET, S3_REGION  bucket name and signing region (default us-east-1)
+//	S3_ACCESS_KEY_ID, S3_SECRET_ACCESS_KEY
+//	S3_PREFIX             optional key prefix
+//	S3_PATH_STYLE         true for MinIO and other stand-ins (default true)
+func DocumentStoreFromEnv() (DocumentStore, error) {
+	switch backend := strings.ToLower(strings.TrimSpace(os.Getenv("DOCUMENT_STORE"))); backend {
+	case "", DocumentStoreLocal:
+		return NewLocalDocumentStore(envOrDefault("DOCUMENT_ROOT", "output")), nil
+	case DocumentStoreS3:
+		pathStyle := true
+		if value := os.Getenv("S3_PATH_STYLE"); value != "" {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid S3_PATH_STYLE %q", value)
+			}
+			pathStyle = parsed
+		}
+		return NewS3DocumentStore(S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			Region:          envOrDefault("S3_REGION", "us-east-1"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			Prefix:          os.Getenv("S3_PREFIX"),
+			PathStyle:       pathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("unsupported DOCUMENT_STORE %q", backend)
+	}
+}
+
+func envOrDefault(name, fallback string) string {
+	if value := strings.TrimSpace(os.Getenv(name)); value != "" {
+		return value
+	}
+	return fallback
+}
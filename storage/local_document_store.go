@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalDocumentStore keeps documents as files below a root directory
+type LocalDocumentStore struct {
+	root string
+}
+
+// NewLocalDocumentStore creates a store rooted at root; the directory is
+// created on the first write
+func NewLocalDocumentStore(root string) *LocalDocumentStore {
+	return &LocalDocumentStore{root: root}
+}
+
+// Put implements DocumentStore. The file is written to a temporary name and
+// renamed so readers never see a partial document.
+func (ls *LocalDocumentStore) Put(key string, data []byte, contentType string) error {
+	path, err := ls.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create document directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("write document %s: %w", key, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write document %s: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write document %s: %w", key, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("write document %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// Get implements DocumentStore
+func (ls *LocalDocumentStore) Get(key string) ([]byte, error) {
+	path, err := ls.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrDocumentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read document %s: %w", key, err)
+	}
+
+	return data, nil
+}
+
+// Exists implements DocumentStore
+func (ls *LocalDocumentStore) Exists(key string) (bool, error) {
+	path, err := ls.path(key)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("stat document %s: %w", key, err)
+	}
+
+	return true, nil
+}
+
+// path maps a key to a file below the root, rejecting keys that escape it
+func (ls *LocalDocumentStore) path(key string) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid document key %q", key)
+	}
+	return filepath.Join(ls.root, cleaned), nil
+}
@@ -2,27 +2,53 @@ package storage
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 
 	"vigovia-task/models"
 )
 
-// MemoryStore is an in-memory storage for itineraries and users
+// MemoryStore is an in-memory storage for itineraries and users. Itinerary
+// listings are always scoped to one organization, so no query returns
+// another tenant's itineraries.
 type MemoryStore struct {
-	itineraries map[string]*models.Itinerary
-	users       map[string]*models.User      // key: user ID
-	usersByEmail map[string]*models.User     // key: email for quick lookup
-	tokens      map[string]string            // key: token, value: user ID
-	mu          sync.RWMutex
+	itineraries      map[string]*models.Itinerary
+	users            map[string]*models.User // key: user ID
+	usersByEmail     map[string]*models.User // key: lowercased email for quick lookup
+	tokens           map[string]string       // key: token, value: user ID
+	brandingProfiles map[string]*models.BrandingProfile
+	documents        map[string]*models.Document
+	exportJobs       map[string]*models.ExportJob
+	templates        map[string]*models.ItineraryTemplate
+	accessGrants     map[string]*models.AccessGrant
+	shareLinks       map[string]*models.ShareLink
+	organizations    map[string]*models.Organization
+	invitations      map[string]*models.OrganizationInvitation
+	comments         map[string]*models.Comment
+	approvals        map[string]*models.Approval
+	catalogue        map[string]*models.CatalogueEntry
+	mu               sync.RWMutex
 }
 
 // NewMemoryStore creates a new instance of MemoryStore
 func NewMemoryStore() *MemoryStore {
 	return &MemoryStore{
-		itineraries:  make(map[string]*models.Itinerary),
-		users:        make(map[string]*models.User),
-		usersByEmail: make(map[string]*models.User),
-		tokens:       make(map[string]string),
+		itineraries:      make(map[string]*models.Itinerary),
+		users:            make(map[string]*models.User),
+		usersByEmail:     make(map[string]*models.User),
+		tokens:           make(map[string]string),
+		brandingProfiles: make(map[string]*models.BrandingProfile),
+		documents:        make(map[string]*models.Document),
+		exportJobs:       make(map[string]*models.ExportJob),
+		templates:        make(map[string]*models.ItineraryTemplate),
+		accessGrants:     make(map[string]*models.AccessGrant),
+		shareLinks:       make(map[string]*models.ShareLink),
+		organizations:    make(map[string]*models.Organization),
+		invitations:      make(map[string]*models.OrganizationInvitation),
+		comments:         make(map[string]*models.Comment),
+		approvals:        make(map[string]*models.Approval),
+		catalogue:        make(map[string]*models.CatalogueEntry),
 	}
 }
 
@@ -52,14 +78,17 @@ func (ms *MemoryStore) GetByID(id string) (*models.Itinerary, error) {
 	return itinerary, nil
 }
 
-// GetAll retrieves all itineraries
-func (ms *MemoryStore) GetAll() []*models.Itinerary {
+// GetByOrganization retrieves the itineraries of one organization. An empty
+// organization ID selects personal itineraries, which belong to none.
+func (ms *MemoryStore) GetByOrganization(organizationID string) []*models.Itinerary {
 	ms.mu.RLock()
 	defer ms.mu.RUnlock()
 
-	itineraries := make([]*models.Itinerary, 0, len(ms.itineraries))
+	itineraries := make([]*models.Itinerary, 0)
 	for _, itinerary := range ms.itineraries {
-		itineraries = append(itineraries, itinerary)
+		if itinerary.OrganizationID == organizationID {
+			itineraries = append(itineraries, itinerary)
+		}
 	}
 
 	return itineraries
@@ -78,7 +107,8 @@ func (ms *MemoryStore) Update(id string, itinerary *models.Itinerary) error {
 	return nil
 }
 
-// Delete removes an itinerary
+// Delete removes an itinerary along with its access grants, share links,
+// comments and approvals
 func (ms *MemoryStore) Delete(id string) error {
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
@@ -88,6 +118,26 @@ func (ms *MemoryStore) Delete(id string) error {
 	}
 
 	delete(ms.itineraries, id)
+	for grantID, grant := range ms.accessGrants {
+		if grant.ItineraryID == id {
+			delete(ms.accessGrants, grantID)
+		}
+	}
+	for linkID, link := range ms.shareLinks {
+		if link.ItineraryID == id {
+			delete(ms.shareLinks, linkID)
+		}
+	}
+	for commentID, comment := range ms.comments {
+		if comment.ItineraryID == id {
+			delete(ms.comments, commentID)
+		}
+	}
+	for approvalID, approval := range ms.approvals {
+		if approval.ItineraryID == id {
+			delete(ms.approvals, approvalID)
+		}
+	}
 	return nil
 }
 
@@ -102,21 +152,22 @@ func (ms *MemoryStore) CreateUser(user *models.User) error {
 		return fmt.Errorf("user with id %s already exists", user.ID)
 	}
 
-	if _, exists := ms.usersByEmail[user.Email]; exists {
+	if _, exists := ms.usersByEmail[emailKey(user.Email)]; exists {
 		return fmt.Errorf("user with email %s already exists", user.Email)
 	}
 
 	ms.users[user.ID] = user
-	ms.usersByEmail[user.Email] = user
+	ms.usersByEmail[emailKey(user.Email)] = user
 	return nil
 }
 
-// GetUserByEmail retrieves a user by email
+// GetUserByEmail retrieves a user by email, ignoring case and surrounding
+// spaces
 func (ms *MemoryStore) GetUserByEmail(email string) (*models.User, error) {
 	ms.mu.RLock()
 	defer ms.mu.RUnlock()
 
-	user, exists := ms.usersByEmail[email]
+	user, exists := ms.usersByEmail[emailKey(email)]
 	if !exists {
 		return nil, fmt.Errorf("user with email %s not found", email)
 	}
@@ -124,6 +175,11 @@ func (ms *MemoryStore) GetUserByEmail(email string) (*models.User, error) {
 	return user, nil
 }
 
+// emailKey is the form of an email users are looked up by
+func emailKey(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
 // GetUserByID retrieves a user by ID
 func (ms *MemoryStore) GetUserByID(id string) (*models.User, error) {
 	ms.mu.RLock()
@@ -137,6 +193,41 @@ func (ms *MemoryStore) GetUserByID(id string) (*models.User, error) {
 	return user, nil
 }
 
+// UpdateUser replaces an existing user
+func (ms *MemoryStore) UpdateUser(user *models.User) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	existing, exists := ms.users[user.ID]
+	if !exists {
+		return fmt.Errorf("user with id %s not found", user.ID)
+	}
+
+	delete(ms.usersByEmail, emailKey(existing.Email))
+	ms.users[user.ID] = user
+	ms.usersByEmail[emailKey(user.Email)] = user
+	return nil
+}
+
+// GetUsersByOrganization retrieves the members of an organization, oldest
+// account first
+func (ms *MemoryStore) GetUsersByOrganization(organizationID string) []*models.User {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	users := make([]*models.User, 0)
+	for _, user := range ms.users {
+		if user.OrganizationID == organizationID {
+			users = append(users, user)
+		}
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].CreatedAt.Before(users[j].CreatedAt)
+	})
+	return users
+}
+
 // StoreToken stores an authentication token
 func (ms *MemoryStore) StoreToken(token, userID string) error {
 	ms.mu.Lock()
@@ -167,3 +258,684 @@ func (ms *MemoryStore) DeleteToken(token string) error {
 	delete(ms.tokens, token)
 	return nil
 }
+
+// Branding profile methods
+
+// CreateBrandingProfile stores a new branding profile
+func (ms *MemoryStore) CreateBrandingProfile(profile *models.BrandingProfile) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, exists := ms.brandingProfiles[profile.ID]; exists {
+		return fmt.Errorf("branding profile with id %s already exists", profile.ID)
+	}
+
+	ms.brandingProfiles[profile.ID] = profile
+	return nil
+}
+
+// GetBrandingProfile retrieves a branding profile by ID
+func (ms *MemoryStore) GetBrandingProfile(id string) (*models.BrandingProfile, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	profile, exists := ms.brandingProfiles[id]
+	if !exists {
+		return nil, fmt.Errorf("branding profile with id %s not found", id)
+	}
+
+	return profile, nil
+}
+
+// GetBrandingProfilesByUser retrieves all branding profiles owned by a user
+func (ms *MemoryStore) GetBrandingProfilesByUser(userID string) []*models.BrandingProfile {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	profiles := make([]*models.BrandingProfile, 0)
+	for _, profile := range ms.brandingProfiles {
+		if profile.UserID == userID {
+			profiles = append(profiles, profile)
+		}
+	}
+
+	return profiles
+}
+
+// UpdateBrandingProfile updates an existing branding profile
+func (ms *MemoryStore) UpdateBrandingProfile(id string, profile *models.BrandingProfile) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, exists := ms.brandingProfiles[id]; !exists {
+		return fmt.Errorf("branding profile with id %s not found", id)
+	}
+
+	ms.brandingProfiles[id] = profile
+	return nil
+}
+
+// DeleteBrandingProfile removes a branding profile
+func (ms *MemoryStore) DeleteBrandingProfile(id string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, exists := ms.brandingProfiles[id]; !exists {
+		return fmt.Errorf("branding profile with id %s not found", id)
+	}
+
+	delete(ms.brandingProfiles, id)
+	return nil
+}
+
+// CreateDocument stores the record of an archived document
+func (ms *MemoryStore) CreateDocument(document *models.Document) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, exists := ms.documents[document.ID]; exists {
+		return fmt.Errorf("document with id %s already exists", document.ID)
+	}
+
+	ms.documents[document.ID] = document
+	return nil
+}
+
+// GetDocument retrieves a document record by ID
+func (ms *MemoryStore) GetDocument(id string) (*models.Document, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	document, exists := ms.documents[id]
+	if !exists {
+		return nil, fmt.Errorf("document with id %s not found", id)
+	}
+
+	return document, nil
+}
+
+// GetDocumentsByItinerary retrieves all document records of an itinerary,
+// oldest first
+func (ms *MemoryStore) GetDocumentsByItinerary(itineraryID string) []*models.Document {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	documents := make([]*models.Document, 0)
+	for _, document := range ms.documents {
+		if document.ItineraryID == itineraryID {
+			documents = append(documents, document)
+		}
+	}
+
+	sort.Slice(documents, func(i, j int) bool {
+		return documents[i].CreatedAt.Before(documents[j].CreatedAt)
+	})
+
+	return documents
+}
+
+// CreateExportJob stores a new export job
+func (ms *MemoryStore) CreateExportJob(job *models.ExportJob) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, exists := ms.exportJobs[job.ID]; exists {
+		return fmt.Errorf("export job with id %s already exists", job.ID)
+	}
+
+	ms.exportJobs[job.ID] = job
+	return nil
+}
+
+// GetExportJob retrieves an export job by ID
+func (ms *MemoryStore) GetExportJob(id string) (*models.ExportJob, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	job, exists := ms.exportJobs[id]
+	if !exists {
+		return nil, fmt.Errorf("export job with id %s not found", id)
+	}
+
+	return job, nil
+}
+
+// UpdateExportJob replaces an existing export job. Jobs are replaced rather
+// than modified in place so readers never observe a partial update.
+func (ms *MemoryStore) UpdateExportJob(job *models.ExportJob) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, exists := ms.exportJobs[job.ID]; !exists {
+		return fmt.Errorf("export job with id %s not found", job.ID)
+	}
+
+	ms.exportJobs[job.ID] = job
+	return nil
+}
+
+// CountActiveExportJobs counts the queued and running export jobs of a user
+func (ms *MemoryStore) CountActiveExportJobs(userID string) int {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	count := 0
+	for _, job := range ms.exportJobs {
+		if job.UserID == userID && (job.Status == models.ExportJobQueued || job.Status == models.ExportJobRunning) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Itinerary template methods
+
+// CreateTemplate stores a new itinerary template
+func (ms *MemoryStore) CreateTemplate(template *models.ItineraryTemplate) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, exists := ms.templates[template.ID]; exists {
+		return fmt.Errorf("template with id %s already exists", template.ID)
+	}
+
+	ms.templates[template.ID] = template
+	return nil
+}
+
+// GetTemplate retrieves an itinerary template by ID
+func (ms *MemoryStore) GetTemplate(id string) (*models.ItineraryTemplate, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	template, exists := ms.templates[id]
+	if !exists {
+		return nil, fmt.Errorf("template with id %s not found", id)
+	}
+
+	return template, nil
+}
+
+// GetTemplatesByUser retrieves all itinerary templates owned by a user
+func (ms *MemoryStore) GetTemplatesByUser(userID string) []*models.ItineraryTemplate {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	templates := make([]*models.ItineraryTemplate, 0)
+	for _, template := range ms.templates {
+		if template.UserID == userID {
+			templates = append(templates, template)
+		}
+	}
+
+	return templates
+}
+
+// DeleteTemplate removes an itinerary template
+func (ms *MemoryStore) DeleteTemplate(id string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, exists := ms.templates[id]; !exists {
+		return fmt.Errorf("template with id %s not found", id)
+	}
+
+	delete(ms.templates, id)
+	return nil
+}
+
+// Access grant methods
+
+// CreateAccessGrant stores a new access grant
+func (ms *MemoryStore) CreateAccessGrant(grant *models.AccessGrant) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, exists := ms.accessGrants[grant.ID]; exists {
+		return fmt.Errorf("access grant with id %s already exists", grant.ID)
+	}
+
+	ms.accessGrants[grant.ID] = grant
+	return nil
+}
+
+// GetAccessGrantsByItinerary retrieves the access grants of an itinerary,
+// oldest first
+func (ms *MemoryStore) GetAccessGrantsByItinerary(itineraryID string) []*models.AccessGrant {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	grants := make([]*models.AccessGrant, 0)
+	for _, grant := range ms.accessGrants {
+		if grant.ItineraryID == itineraryID {
+			grants = append(grants, grant)
+		}
+	}
+
+	sort.Slice(grants, func(i, j int) bool {
+		return grants[i].CreatedAt.Before(grants[j].CreatedAt)
+	})
+	return grants
+}
+
+// GetAccessGrantsByEmail retrieves the access grants sent to an email,
+// oldest first
+func (ms *MemoryStore) GetAccessGrantsByEmail(email string) []*models.AccessGrant {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	grants := make([]*models.AccessGrant, 0)
+	for _, grant := range ms.accessGrants {
+		if grant.Email == email {
+			grants = append(grants, grant)
+		}
+	}
+
+	sort.Slice(grants, func(i, j int) bool {
+		return grants[i].CreatedAt.Before(grants[j].CreatedAt)
+	})
+	return grants
+}
+
+// UpdateAccessGrant replaces an existing access grant
+func (ms *MemoryStore) UpdateAccessGrant(grant *models.AccessGrant) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, exists := ms.accessGrants[grant.ID]; !exists {
+		return fmt.Errorf("access grant with id %s not found", grant.ID)
+	}
+
+	ms.accessGrants[grant.ID] = grant
+	return nil
+}
+
+// DeleteAccessGrant removes an access grant
+func (ms *MemoryStore) DeleteAccessGrant(id string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, exists := ms.accessGrants[id]; !exists {
+		return fmt.Errorf("access grant with id %s not found", id)
+	}
+
+	delete(ms.accessGrants, id)
+	return nil
+}
+
+// Share link methods
+
+// CreateShareLink stores a new share link
+func (ms *MemoryStore) CreateShareLink(link *models.ShareLink) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, exists := ms.shareLinks[link.ID]; exists {
+		return fmt.Errorf("share link with id %s already exists", link.ID)
+	}
+
+	ms.shareLinks[link.ID] = link
+	return nil
+}
+
+// GetShareLink retrieves a share link by ID
+func (ms *MemoryStore) GetShareLink(id string) (*models.ShareLink, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	link, exists := ms.shareLinks[id]
+	if !exists {
+		return nil, fmt.Errorf("share link with id %s not found", id)
+	}
+
+	return link, nil
+}
+
+// GetShareLinksByItinerary retrieves the share links of an itinerary, oldest
+// first
+func (ms *MemoryStore) GetShareLinksByItinerary(itineraryID string) []*models.ShareLink {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	links := make([]*models.ShareLink, 0)
+	for _, link := range ms.shareLinks {
+		if link.ItineraryID == itineraryID {
+			links = append(links, link)
+		}
+	}
+
+	sort.Slice(links, func(i, j int) bool {
+		return links[i].CreatedAt.Before(links[j].CreatedAt)
+	})
+	return links
+}
+
+// UpdateShareLink replaces an existing share link
+func (ms *MemoryStore) UpdateShareLink(link *models.ShareLink) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, exists := ms.shareLinks[link.ID]; !exists {
+		return fmt.Errorf("share link with id %s not found", link.ID)
+	}
+
+	ms.shareLinks[link.ID] = link
+	return nil
+}
+
+// Organization methods
+
+// CreateOrganization stores a new organization
+func (ms *MemoryStore) CreateOrganization(organization *models.Organization) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, exists := ms.organizations[organization.ID]; exists {
+		return fmt.Errorf("organization with id %s already exists", organization.ID)
+	}
+
+	ms.organizations[organization.ID] = organization
+	return nil
+}
+
+// GetOrganization retrieves an organization by ID
+func (ms *MemoryStore) GetOrganization(id string) (*models.Organization, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	organization, exists := ms.organizations[id]
+	if !exists {
+		return nil, fmt.Errorf("organization with id %s not found", id)
+	}
+
+	return organization, nil
+}
+
+// UpdateOrganization replaces an existing organization
+func (ms *MemoryStore) UpdateOrganization(organization *models.Organization) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, exists := ms.organizations[organization.ID]; !exists {
+		return fmt.Errorf("organization with id %s not found", organization.ID)
+	}
+
+	ms.organizations[organization.ID] = organization
+	return nil
+}
+
+// CreateInvitation stores a new organization invitation
+func (ms *MemoryStore) CreateInvitation(invitation *models.OrganizationInvitation) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, exists := ms.invitations[invitation.ID]; exists {
+		return fmt.Errorf("invitation with id %s already exists", invitation.ID)
+	}
+
+	ms.invitations[invitation.ID] = invitation
+	return nil
+}
+
+// GetInvitation retrieves an organization invitation by ID
+func (ms *MemoryStore) GetInvitation(id string) (*models.OrganizationInvitation, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	invitation, exists := ms.invitations[id]
+	if !exists {
+		return nil, fmt.Errorf("invitation with id %s not found", id)
+	}
+
+	return invitation, nil
+}
+
+// GetInvitationsByOrganization retrieves the invitations of an
+// organization, oldest first
+func (ms *MemoryStore) GetInvitationsByOrganization(organizationID string) []*models.OrganizationInvitation {
+	return ms.invitationsWhere(func(invitation *models.OrganizationInvitation) bool {
+		return invitation.OrganizationID == organizationID
+	})
+}
+
+// GetInvitationsByEmail retrieves the invitations sent to an email, oldest
+// first
+func (ms *MemoryStore) GetInvitationsByEmail(email string) []*models.OrganizationInvitation {
+	return ms.invitationsWhere(func(invitation *models.OrganizationInvitation) bool {
+		return invitation.Email == email
+	})
+}
+
+func (ms *MemoryStore) invitationsWhere(keep func(*models.OrganizationInvitation) bool) []*models.OrganizationInvitation {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	invitations := make([]*models.OrganizationInvitation, 0)
+	for _, invitation := range ms.invitations {
+		if keep(invitation) {
+			invitations = append(invitations, invitation)
+		}
+	}
+
+	sort.Slice(invitations, func(i, j int) bool {
+		return invitations[i].CreatedAt.Before(invitations[j].CreatedAt)
+	})
+	return invitations
+}
+
+// UpdateInvitation replaces an existing organization invitation
+func (ms *MemoryStore) UpdateInvitation(invitation *models.OrganizationInvitation) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, exists := ms.invitations[invitation.ID]; !exists {
+		return fmt.Errorf("invitation with id %s not found", invitation.ID)
+	}
+
+	ms.invitations[invitation.ID] = invitation
+	return nil
+}
+
+// DeleteInvitation removes an organization invitation
+func (ms *MemoryStore) DeleteInvitation(id string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, exists := ms.invitations[id]; !exists {
+		return fmt.Errorf("invitation with id %s not found", id)
+	}
+
+	delete(ms.invitations, id)
+	return nil
+}
+
+// Comment methods
+
+// CreateComment stores a new comment
+func (ms *MemoryStore) CreateComment(comment *models.Comment) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, exists := ms.comments[comment.ID]; exists {
+		return fmt.Errorf("comment with id %s already exists", comment.ID)
+	}
+
+	ms.comments[comment.ID] = comment
+	return nil
+}
+
+// GetComment retrieves a comment by ID
+func (ms *MemoryStore) GetComment(id string) (*models.Comment, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	comment, exists := ms.comments[id]
+	if !exists {
+		return nil, fmt.Errorf("comment with id %s not found", id)
+	}
+
+	return comment, nil
+}
+
+// GetCommentsByItinerary retrieves the comments on an itinerary, oldest first
+func (ms *MemoryStore) GetCommentsByItinerary(itineraryID string) []*models.Comment {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	comments := make([]*models.Comment, 0)
+	for _, comment := range ms.comments {
+		if comment.ItineraryID == itineraryID {
+			comments = append(comments, comment)
+		}
+	}
+
+	sort.Slice(comments, func(i, j int) bool {
+		return comments[i].CreatedAt.Before(comments[j].CreatedAt)
+	})
+	return comments
+}
+
+// UpdateComment replaces an existing comment
+func (ms *MemoryStore) UpdateComment(comment *models.Comment) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, exists := ms.comments[comment.ID]; !exists {
+		return fmt.Errorf("comment with id %s not found", comment.ID)
+	}
+
+	ms.comments[comment.ID] = comment
+	return nil
+}
+
+// DeleteComment removes a comment
+func (ms *MemoryStore) DeleteComment(id string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, exists := ms.comments[id]; !exists {
+		return fmt.Errorf("comment with id %s not found", id)
+	}
+
+	delete(ms.comments, id)
+	return nil
+}
+
+// Approval methods
+
+// CreateApproval stores a new approval request
+func (ms *MemoryStore) CreateApproval(approval *models.Approval) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, exists := ms.approvals[approval.ID]; exists {
+		return fmt.Errorf("approval with id %s already exists", approval.ID)
+	}
+
+	ms.approvals[approval.ID] = approval
+	return nil
+}
+
+// GetApprovalsByItinerary retrieves the approval requests of an itinerary,
+// oldest first
+func (ms *MemoryStore) GetApprovalsByItinerary(itineraryID string) []*models.Approval {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	approvals := make([]*models.Approval, 0)
+	for _, approval := range ms.approvals {
+		if approval.ItineraryID == itineraryID {
+			approvals = append(approvals, approval)
+		}
+	}
+
+	sort.Slice(approvals, func(i, j int) bool {
+		return approvals[i].CreatedAt.Before(approvals[j].CreatedAt)
+	})
+	return approvals
+}
+
+// UpdateApproval replaces an existing approval request
+func (ms *MemoryStore) UpdateApproval(approval *models.Approval) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, exists := ms.approvals[approval.ID]; !exists {
+		return fmt.Errorf("approval with id %s not found", approval.ID)
+	}
+
+	ms.approvals[approval.ID] = approval
+	return nil
+}
+
+// Catalogue methods
+
+// CreateCatalogueEntry stores a new catalogue entry
+func (ms *MemoryStore) CreateCatalogueEntry(entry *models.CatalogueEntry) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, exists := ms.catalogue[entry.ID]; exists {
+		return fmt.Errorf("catalogue entry with id %s already exists", entry.ID)
+	}
+
+	ms.catalogue[entry.ID] = entry
+	return nil
+}
+
+// GetCatalogueEntry retrieves a catalogue entry by ID
+func (ms *MemoryStore) GetCatalogueEntry(id string) (*models.CatalogueEntry, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	entry, exists := ms.catalogue[id]
+	if !exists {
+		return nil, fmt.Errorf("catalogue entry with id %s not found", id)
+	}
+
+	return entry, nil
+}
+
+// GetCatalogueByOrganization retrieves the catalogue entries of an
+// organization; an empty ID selects the entries of users outside any
+// organization
+func (ms *MemoryStore) GetCatalogueByOrganization(organizationID string) []*models.CatalogueEntry {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	entries := make([]*models.CatalogueEntry, 0)
+	for _, entry := range ms.catalogue {
+		if entry.OrganizationID == organizationID {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
+
+// UpdateCatalogueEntry replaces an existing catalogue entry
+func (ms *MemoryStore) UpdateCatalogueEntry(entry *models.CatalogueEntry) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, exists := ms.catalogue[entry.ID]; !exists {
+		return fmt.Errorf("catalogue entry with id %s not found", entry.ID)
+	}
+
+	ms.catalogue[entry.ID] = entry
+	return nil
+}
+
+// DeleteCatalogueEntry removes a catalogue entry
+func (ms *MemoryStore) DeleteCatalogueEntry(id string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, exists := ms.catalogue[id]; !exists {
+		return fmt.Errorf("catalogue entry with id %s not found", id)
+	}
+
+	delete(ms.catalogue, id)
+	return nil
+}
@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"encoding/csv"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"vigovia-task/assets"
+	"vigovia-task/models"
+)
+
+// flightNumberPattern matches a two-character IATA or three-letter ICAO
+// airline designator followed by up to four digits and an optional suffix
+var flightNumberPattern = regexp.MustCompile(`^(?:[A-Z][A-Z0-9]|[0-9][A-Z]|[A-Z]{3})[0-9]{1,4}[A-Z]?$`)
+
+var (
+	referenceOnce sync.Once
+	airports      map[string]models.Airport
+	airlines      map[string]models.Airline
+)
+
+// LookupAirport finds an airport by its IATA or ICAO code
+func LookupAirport(code string) (models.Airport, bool) {
+	referenceOnce.Do(loadReference)
+	airport, ok := airports[strings.ToUpper(strings.TrimSpace(code))]
+	return airport, ok
+}
+
+// LookupAirline finds an airline by its IATA or ICAO designator
+func LookupAirline(designator string) (models.Airline, bool) {
+	referenceOnce.Do(loadReference)
+	airline, ok := airlines[strings.ToUpper(strings.TrimSpace(designator))]
+	return airline, ok
+}
+
+// NormalizeFlightNumber upper-cases a flight number and drops its spaces,
+// so "ek 511" becomes "EK511"
+func NormalizeFlightNumber(number string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(number), ""))
+}
+
+// AirlineDesignator returns the airline part of a normalized flight number
+func AirlineDesignator(flightNumber string) string {
+	if len(flightNumber) > 3 && isLetter(flightNumber[0]) && isLetter(flightNumber[1]) && isLetter(flightNumber[2]) {
+		return flightNumber[:3]
+	}
+	if len(flightNumber) > 2 {
+		return flightNumber[:2]
+	}
+	return ""
+}
+
+func isLetter(b byte) bool {
+	return b >= 'A' && b <= 'Z'
+}
+
+// loadReference indexes the embedded CSV files. The files ship with the
+// binary, so a malformed one is a programming error.
+func loadReference() {
+	airportRows := readReference("reference/airports.csv", 7)
+	airports = make(map[string]models.Airport, 2*len(airportRows))
+	for _, row := range airportRows {
+		airport := models.Airport{
+			IATA:        row[0],
+			ICAO:        row[1],
+			Name:        row[2],
+			City:        row[3],
+			CountryCode: row[4],
+			Country:     row[5],
+			TimeZone:    row[6],
+		}
+		airports[airport.IATA] = airport
+		airports[airport.ICAO] = airport
+	}
+
+	airlineRows := readReference("reference/airlines.csv", 4)
+	airlines = make(map[string]models.Airline, 2*len(airlineRows))
+	for _, row := range airlineRows {
+		airline := models.Airline{IATA: row[0], ICAO: row[1], Name: row[2], CountryCode: row[3]}
+		airlines[airline.IATA] = airline
+		airlines[airline.ICAO] = airline
+	}
+}
+
+// readReference returns the rows of an embedded CSV file without its header
+func readReference(name string, fields int) [][]string {
+	file, err := assets.Reference.Open(name)
+	if err != nil {
+		panic(fmt.Sprintf("open %s: %v", name, err))
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = fields
+	rows, err := reader.ReadAll()
+	if err != nil {
+		panic(fmt.Sprintf("parse %s: %v", name, err))
+	}
+	return rows[1:]
+}
@@ -1,13 +1,34 @@
 package utils
 
 import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/mail"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
 
 	"vigovia-task/models"
 )
 
+var (
+	hexColorPattern       = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+	countryCodePattern    = regexp.MustCompile(`^[A-Z]{2}$`)
+	passportNumberPattern = regexp.MustCompile(`^[A-Z0-9]{6,9}$`)
+	phonePattern          = regexp.MustCompile(`^\+?[0-9 ()-]{6,20}$`)
+	confirmationPattern   = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9/-]{2,39}$`)
+	bookingRefPattern     = regexp.MustCompile(`^[A-Z0-9]{5,8}$`)
+	seatPattern           = regexp.MustCompile(`^[0-9]{1,2}[A-K]$`)
+	currencyPattern       = regexp.MustCompile(`^[A-Z]{3}$`)
+	iataAirportPattern    = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
 var validPeriods = map[string]struct{}{
 	models.ActivityPeriodMorning:   {},
 	models.ActivityPeriodAfternoon: {},
@@ -28,6 +49,10 @@ func ValidateItinerary(req *models.CreateItineraryRequest) error {
 		return NewValidationError("location is required")
 	}
 
+	if err := ValidateCountryCode(req.Destination, "destination_country"); err != nil {
+		return err
+	}
+
 	if req.StartDate.IsZero() {
 		return NewValidationError("start_date is required")
 	}
@@ -80,6 +105,14 @@ func ValidateItinerary(req *models.CreateItineraryRequest) error {
 		}
 	}
 
+	if err := ValidateJourneys(req.Flights); err != nil {
+		return err
+	}
+
+	if err := ValidateTransferLinks(req.Transfers, req.Flights, req.Hotels); err != nil {
+		return err
+	}
+
 	for _, transfer := range req.Transfers {
 		if err := ValidateTransfer(&transfer); err != nil {
 			return err
@@ -92,12 +125,22 @@ func ValidateItinerary(req *models.CreateItineraryRequest) error {
 		}
 	}
 
+	if err := ValidateActivityIDs(req.Days); err != nil {
+		return err
+	}
+
 	for _, installment := range req.PaymentPlan {
 		if err := ValidatePaymentInstallment(&installment); err != nil {
 			return err
 		}
 	}
 
+	for _, traveller := range req.Travellers {
+		if err := ValidateTraveller(&traveller); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -141,6 +184,38 @@ func ValidateHotel(hotel *models.Hotel) error {
 		return NewValidationError("hotel nights must be greater than zero")
 	}
 
+	switch hotel.MealPlan {
+	case "", models.MealPlanEP, models.MealPlanCP, models.MealPlanMAP, models.MealPlanAP:
+	default:
+		return NewValidationError(fmt.Sprintf("meal_plan of hotel %s must be EP, CP, MAP or AP", hotel.Name))
+	}
+
+	if hotel.Phone != "" && !phonePattern.MatchString(hotel.Phone) {
+		return NewValidationError(fmt.Sprintf("phone of hotel %s is not a valid phone number", hotel.Name))
+	}
+
+	if hotel.ConfirmationNumber != "" && !confirmationPattern.MatchString(hotel.ConfirmationNumber) {
+		return NewValidationError(fmt.Sprintf("confirmation_number of hotel %s must be 3 to 40 letters, digits, '-' or '/'", hotel.Name))
+	}
+
+	if point := hotel.Coordinates; point != nil {
+		if point.Latitude < -90 || point.Latitude > 90 || point.Longitude < -180 || point.Longitude > 180 {
+			return NewValidationError(fmt.Sprintf("coordinates of hotel %s are out of range", hotel.Name))
+		}
+	}
+
+	for _, room := range hotel.Rooms {
+		if strings.TrimSpace(room.Label) == "" {
+			return NewValidationError(fmt.Sprintf("room label or room_type is required for hotel %s", hotel.Name))
+		}
+		if room.Quantity < 1 {
+			return NewValidationError(fmt.Sprintf("quantity of room %s at hotel %s must be at least 1", room.Label, hotel.Name))
+		}
+		if room.Occupancy < 0 || room.Occupancy > 10 {
+			return NewValidationError(fmt.Sprintf("occupancy of room %s at hotel %s must be between 1 and 10 when given", room.Label, hotel.Name))
+		}
+	}
+
 	return nil
 }
 
@@ -154,6 +229,10 @@ func ValidateFlight(flight *models.Flight) error {
 		return NewValidationError("flight number is required")
 	}
 
+	if !flightNumberPattern.MatchString(flight.FlightNumber) {
+		return NewValidationError(fmt.Sprintf("flight number %s must be an airline designator followed by 1 to 4 digits, such as AF123", flight.FlightNumber))
+	}
+
 	if strings.TrimSpace(flight.DepartureCity) == "" || strings.TrimSpace(flight.DepartureAirport) == "" {
 		return NewValidationError("flight departure city and airport are required")
 	}
@@ -170,10 +249,74 @@ func ValidateFlight(flight *models.Flight) error {
 		return NewValidationError("flight arrival_time is required")
 	}
 
+	if !ValidAirportCode(flight.DepartureAirport) {
+		return NewValidationError(fmt.Sprintf("departure_airport %s of flight %s must be a three-letter IATA code or a known ICAO code", flight.DepartureAirport, flight.FlightNumber))
+	}
+
+	if !ValidAirportCode(flight.ArrivalAirport) {
+		return NewValidationError(fmt.Sprintf("arrival_airport %s of flight %s must be a three-letter IATA code or a known ICAO code", flight.ArrivalAirport, flight.FlightNumber))
+	}
+
 	if flight.ArrivalTime.Before(flight.DepartureTime) {
 		return NewValidationError("flight arrival_time must be after departure_time")
 	}
 
+	if flight.BookingReference != "" && !bookingRefPattern.MatchString(flight.BookingReference) {
+		return NewValidationError(fmt.Sprintf("booking_reference of flight %s must be 5 to 8 letters or digits", flight.FlightNumber))
+	}
+
+	switch flight.CabinClass {
+	case "", models.CabinEconomy, models.CabinPremiumEconomy, models.CabinBusiness, models.CabinFirst:
+	default:
+		return NewValidationError(fmt.Sprintf("cabin_class of flight %s must be economy, premium_economy, business or first", flight.FlightNumber))
+	}
+
+	if flight.Seat != "" && !seatPattern.MatchString(flight.Seat) {
+		return NewValidationError(fmt.Sprintf("seat of flight %s must be a row and letter such as 14C", flight.FlightNumber))
+	}
+
+	if len(flight.DepartureTerminal) > 10 || len(flight.ArrivalTerminal) > 10 {
+		return NewValidationError(fmt.Sprintf("terminals of flight %s cannot exceed 10 characters", flight.FlightNumber))
+	}
+
+	if len(flight.BaggageAllowance) > 100 {
+		return NewValidationError(fmt.Sprintf("baggage_allowance of flight %s cannot exceed 100 characters", flight.FlightNumber))
+	}
+
+	return nil
+}
+
+// ValidAirportCode reports whether code is an airport in the reference data
+// or at least shaped like an IATA code. Airports missing from the reference
+// data are flagged by the travel checks rather than rejected.
+func ValidAirportCode(code string) bool {
+	if _, ok := LookupAirport(code); ok {
+		return true
+	}
+	return iataAirportPattern.MatchString(strings.ToUpper(strings.TrimSpace(code)))
+}
+
+// ValidateJourneys ensures the legs of each journey do not overlap: every leg
+// must depart after the previous one lands
+func ValidateJourneys(flights []models.Flight) error {
+	legs := make(map[string][]models.Flight)
+	for _, flight := range flights {
+		if flight.JourneyID != "" {
+			legs[flight.JourneyID] = append(legs[flight.JourneyID], flight)
+		}
+	}
+
+	for id, journey := range legs {
+		sort.SliceStable(journey, func(i, j int) bool {
+			return journey[i].DepartureTime.Before(journey[j].DepartureTime)
+		})
+		for i := 1; i < len(journey); i++ {
+			if !journey[i].DepartureTime.After(journey[i-1].ArrivalTime) {
+				return NewValidationError(fmt.Sprintf("flight %s of journey %s departs before flight %s lands", journey[i].FlightNumber, id, journey[i-1].FlightNumber))
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -195,9 +338,138 @@ func ValidateTransfer(transfer *models.Transfer) error {
 		return NewValidationError("transfer pickup_time is required")
 	}
 
+	switch transfer.VehicleType {
+	case "", models.VehicleSedan, models.VehicleSUV, models.VehicleVan, models.VehicleMinibus, models.VehicleCoach:
+	default:
+		return NewValidationError("transfer vehicle_type must be sedan, suv, van, minibus or coach")
+	}
+
+	if transfer.Capacity < 0 || transfer.Capacity > 60 {
+		return NewValidationError("transfer capacity must be between 1 and 60")
+	}
+
+	if transfer.PickupAfterLandingMinutes != nil && (*transfer.PickupAfterLandingMinutes < 0 || *transfer.PickupAfterLandingMinutes > 24*60) {
+		return NewValidationError("transfer pickup_after_landing_minutes must be between 0 and 1440")
+	}
+
+	if transfer.Price < 0 {
+		return NewValidationError("transfer price cannot be negative")
+	}
+
+	if transfer.Price > 0 && transfer.Currency == "" {
+		return NewValidationError("transfer currency is required when a price is given")
+	}
+
+	if transfer.Currency != "" && !currencyPattern.MatchString(transfer.Currency) {
+		return NewValidationError("transfer currency must be a 3-letter ISO code")
+	}
+
+	if err := validateTransferContact(transfer.Vendor, "vendor"); err != nil {
+		return err
+	}
+
+	return validateTransferContact(transfer.Driver, "driver")
+}
+
+func validateTransferContact(contact *models.TransferContact, field string) error {
+	if contact == nil {
+		return nil
+	}
+
+	if strings.TrimSpace(contact.Name) == "" {
+		return NewValidationError(fmt.Sprintf("transfer %s name is required", field))
+	}
+
+	if contact.Phone != "" && !phonePattern.MatchString(contact.Phone) {
+		return NewValidationError(fmt.Sprintf("transfer %s phone is not a valid phone number", field))
+	}
+
+	if contact.Email != "" {
+		if _, err := mail.ParseAddress(contact.Email); err != nil {
+			return NewValidationError(fmt.Sprintf("transfer %s email is not a valid email address", field))
+		}
+	}
+
+	return nil
+}
+
+// ValidateTransferLinks checks the flights and hotels that transfers refer
+// to: a flight pickup must be after the flight lands, and a hotel transfer
+// must pick up before the hotel's check-in.
+func ValidateTransferLinks(transfers []models.Transfer, flights []models.Flight, hotels []models.Hotel) error {
+	for _, transfer := range transfers {
+		if transfer.FlightNumber != "" {
+			flight, err := findTransferFlight(transfer.FlightNumber, flights)
+			if err != nil {
+				return err
+			}
+			if transfer.PickupAt.IsZero() {
+				return NewValidationError(fmt.Sprintf("transfer for flight %s needs pickup_at or pickup_after_landing_minutes", flight.FlightNumber))
+			}
+			if transfer.PickupAt.Before(flight.ArrivalTime) {
+				return NewValidationError(fmt.Sprintf("transfer pickup at %s is before flight %s lands at %s",
+					transfer.PickupAt.Format(time.RFC3339), flight.FlightNumber, flight.ArrivalTime.Format(time.RFC3339)))
+			}
+			if transfer.Capacity > 0 && transfer.Capacity < len(flight.TravellerIDs) {
+				return NewValidationError(fmt.Sprintf("transfer for flight %s seats %d but %d travellers are on the flight",
+					flight.FlightNumber, transfer.Capacity, len(flight.TravellerIDs)))
+			}
+		}
+
+		if transfer.HotelName != "" {
+			hotel, err := findTransferHotel(transfer.HotelName, hotels)
+			if err != nil {
+				return err
+			}
+			if transfer.PickupAt.IsZero() {
+				return NewValidationError(fmt.Sprintf("transfer to %s needs pickup_at", hotel.Name))
+			}
+			if !transfer.PickupAt.Before(hotel.CheckIn) {
+				return NewValidationError(fmt.Sprintf("transfer pickup at %s is not before the check-in at %s on %s",
+					transfer.PickupAt.Format(time.RFC3339), hotel.Name, hotel.CheckIn.Format(time.RFC3339)))
+			}
+		}
+	}
+
 	return nil
 }
 
+// findTransferFlight finds the one flight with the given number
+func findTransferFlight(number string, flights []models.Flight) (models.Flight, error) {
+	var found []models.Flight
+	for _, flight := range flights {
+		if flight.FlightNumber == number {
+			found = append(found, flight)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return models.Flight{}, NewValidationError(fmt.Sprintf("transfer references unknown flight %s", number))
+	case 1:
+		return found[0], nil
+	default:
+		return models.Flight{}, NewValidationError(fmt.Sprintf("transfer references flight %s which appears more than once", number))
+	}
+}
+
+// findTransferHotel finds the one hotel with the given name, ignoring case
+func findTransferHotel(name string, hotels []models.Hotel) (models.Hotel, error) {
+	var found []models.Hotel
+	for _, hotel := range hotels {
+		if strings.EqualFold(strings.TrimSpace(hotel.Name), name) {
+			found = append(found, hotel)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return models.Hotel{}, NewValidationError(fmt.Sprintf("transfer references unknown hotel %s", name))
+	case 1:
+		return found[0], nil
+	default:
+		return models.Hotel{}, NewValidationError(fmt.Sprintf("transfer references hotel %s which appears more than once", name))
+	}
+}
+
 // ValidateDayPlan ensures each day plan has mandatory details.
 func ValidateDayPlan(day *models.DayPlan) error {
 	if day.DayNumber <= 0 {
@@ -225,6 +497,23 @@ func ValidateDayPlan(day *models.DayPlan) error {
 	return nil
 }
 
+// ValidateActivityIDs checks that no two activities in the day plans share an ID
+func ValidateActivityIDs(days []models.DayPlan) error {
+	seen := make(map[string]bool)
+	for _, day := range days {
+		for _, activity := range day.Activities {
+			if activity.ID == "" {
+				continue
+			}
+			if seen[activity.ID] {
+				return NewValidationError(fmt.Sprintf("activity id %s is used more than once", activity.ID))
+			}
+			seen[activity.ID] = true
+		}
+	}
+	return nil
+}
+
 // ValidateActivity validates an activity
 func ValidateActivity(activity *models.Activity) error {
 	if strings.TrimSpace(activity.Title) == "" {
@@ -275,6 +564,323 @@ func ValidatePaymentInstallment(installment *models.PaymentInstallment) error {
 	return nil
 }
 
+// ValidateTraveller ensures a traveller has the details needed for bookings.
+// Nationality and passport number are expected in upper case.
+func ValidateTraveller(traveller *models.Traveller) error {
+	if strings.TrimSpace(traveller.FirstName) == "" || strings.TrimSpace(traveller.LastName) == "" {
+		return NewValidationError("traveller first_name and last_name are required")
+	}
+
+	name := traveller.FullName()
+	if traveller.DateOfBirth.IsZero() {
+		return NewValidationError(fmt.Sprintf("date_of_birth is required for traveller %s", name))
+	}
+	if traveller.DateOfBirth.After(time.Now()) {
+		return NewValidationError(fmt.Sprintf("date_of_birth of traveller %s cannot be in the future", name))
+	}
+
+	if !countryCodePattern.MatchString(traveller.Nationality) {
+		return NewValidationError(fmt.Sprintf("nationality of traveller %s must be a two-letter ISO country code", name))
+	}
+
+	if !passportNumberPattern.MatchString(traveller.PassportNumber) {
+		return NewValidationError(fmt.Sprintf("passport_number of traveller %s must be 6 to 9 letters or digits", name))
+	}
+	if traveller.PassportExpiry.IsZero() {
+		return NewValidationError(fmt.Sprintf("passport_expiry is required for traveller %s", name))
+	}
+	if !traveller.PassportExpiry.After(traveller.DateOfBirth) {
+		return NewValidationError(fmt.Sprintf("passport_expiry of traveller %s must be after date_of_birth", name))
+	}
+
+	contact := traveller.EmergencyContact
+	if strings.TrimSpace(contact.Name) == "" || strings.TrimSpace(contact.Phone) == "" {
+		return NewValidationError(fmt.Sprintf("emergency_contact name and phone are required for traveller %s", name))
+	}
+	if !phonePattern.MatchString(contact.Phone) {
+		return NewValidationError(fmt.Sprintf("emergency_contact phone of traveller %s is not a valid phone number", name))
+	}
+
+	return nil
+}
+
+// ValidateCountryCode checks an optional two-letter ISO country code
+func ValidateCountryCode(code, field string) error {
+	if code != "" && !countryCodePattern.MatchString(code) {
+		return NewValidationError(fmt.Sprintf("%s must be a two-letter ISO country code", field))
+	}
+	return nil
+}
+
+// ValidateVisaRule checks an entry of the visa rules file
+func ValidateVisaRule(rule *models.VisaRule) error {
+	if rule.Nationality != "*" && !countryCodePattern.MatchString(rule.Nationality) {
+		return NewValidationError(fmt.Sprintf("visa rule nationality %q must be a two-letter ISO country code or *", rule.Nationality))
+	}
+	if !countryCodePattern.MatchString(rule.Destination) {
+		return NewValidationError(fmt.Sprintf("visa rule destination %q must be a two-letter ISO country code", rule.Destination))
+	}
+	switch rule.Requirement {
+	case models.VisaFree, models.VisaOnArrival, models.VisaElectronic, models.VisaRequired:
+	default:
+		return NewValidationError(fmt.Sprintf("visa rule %s->%s has unknown requirement %q", rule.Nationality, rule.Destination, rule.Requirement))
+	}
+	if rule.MaxStayDays < 0 {
+		return NewValidationError(fmt.Sprintf("visa rule %s->%s max_stay_days cannot be negative", rule.Nationality, rule.Destination))
+	}
+	return nil
+}
+
+// ValidateTravellerAssignments ensures traveller IDs are unique and that
+// flights and hotel rooms only reference travellers on the itinerary
+func ValidateTravellerAssignments(travellers []models.Traveller, hotels []models.Hotel, flights []models.Flight) error {
+	known := make(map[string]bool, len(travellers))
+	for _, traveller := range travellers {
+		if known[traveller.ID] {
+			return NewValidationError(fmt.Sprintf("duplicate traveller id %s", traveller.ID))
+		}
+		known[traveller.ID] = true
+	}
+
+	for _, flight := range flights {
+		seen := make(map[string]bool)
+		for _, id := range flight.TravellerIDs {
+			if !known[id] {
+				return NewValidationError(fmt.Sprintf("flight %s references unknown traveller %s", flight.FlightNumber, id))
+			}
+			if seen[id] {
+				return NewValidationError(fmt.Sprintf("traveller %s is assigned to flight %s twice", id, flight.FlightNumber))
+			}
+			seen[id] = true
+		}
+	}
+
+	for _, hotel := range hotels {
+		seen := make(map[string]bool)
+		capacity, sized := 0, len(hotel.Rooms) > 0
+		for _, room := range hotel.Rooms {
+			if room.Capacity() > 0 && len(room.TravellerIDs) > room.Capacity() {
+				return NewValidationError(fmt.Sprintf("hotel %s room %s sleeps %d but has %d travellers assigned", hotel.Name, room.Label, room.Capacity(), len(room.TravellerIDs)))
+			}
+			capacity += room.Capacity()
+			sized = sized && room.Capacity() > 0
+			for _, id := range room.TravellerIDs {
+				if !known[id] {
+					return NewValidationError(fmt.Sprintf("hotel %s room %s references unknown traveller %s", hotel.Name, room.Label, id))
+				}
+				if seen[id] {
+					return NewValidationError(fmt.Sprintf("traveller %s is assigned to more than one room at hotel %s", id, hotel.Name))
+				}
+				seen[id] = true
+			}
+		}
+		// Only check the whole booking when every room states its occupancy
+		if sized && capacity < len(travellers) {
+			return NewValidationError(fmt.Sprintf("rooms at hotel %s sleep %d but the itinerary has %d travellers", hotel.Name, capacity, len(travellers)))
+		}
+	}
+
+	return nil
+}
+
+// ValidateBrandingProfile ensures a branding profile is usable for rendering.
+func ValidateBrandingProfile(req *models.BrandingProfileRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return NewValidationError("branding name is required")
+	}
+
+	colors := map[string]string{
+		"primary": req.Colors.Primary,
+		"accent":  req.Colors.Accent,
+		"heading": req.Colors.Heading,
+		"divider": req.Colors.Divider,
+	}
+	for label, value := range colors {
+		if value != "" && !hexColorPattern.MatchString(value) {
+			return NewValidationError(fmt.Sprintf("branding %s colour must be a hex value like #2980B9", label))
+		}
+	}
+
+	if len(req.Logo) > 0 {
+		if _, _, err := image.DecodeConfig(bytes.NewReader(req.Logo)); err != nil {
+			return NewValidationError("branding logo must be a PNG or JPEG image")
+		}
+	}
+
+	if len(req.Fonts.Regular) > 0 || len(req.Fonts.Bold) > 0 {
+		if strings.TrimSpace(req.Fonts.Family) == "" {
+			return NewValidationError("branding font family is required when font files are provided")
+		}
+		if len(req.Fonts.Regular) == 0 {
+			return NewValidationError("branding regular font file is required")
+		}
+		for _, font := range [][]byte{req.Fonts.Regular, req.Fonts.Bold} {
+			if len(font) > 0 && !isTrueTypeFont(font) {
+				return NewValidationError("branding fonts must be TrueType (.ttf) files")
+			}
+		}
+	}
+
+	return nil
+}
+
+func isTrueTypeFont(data []byte) bool {
+	return len(data) > 4 && (bytes.Equal(data[:4], []byte{0x00, 0x01, 0x00, 0x00}) || string(data[:4]) == "true")
+}
+
+// ValidateTemplate checks the name and tags of a new itinerary template.
+// Tags are expected trimmed and in lower case.
+func ValidateTemplate(req *models.CreateTemplateRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return NewValidationError("template name is required")
+	}
+
+	if len(req.Name) > 100 {
+		return NewValidationError("template name cannot exceed 100 characters")
+	}
+
+	if len(req.Tags) > 10 {
+		return NewValidationError("a template can have at most 10 tags")
+	}
+
+	for _, tag := range req.Tags {
+		if tag == "" || len(tag) > 30 {
+			return NewValidationError("template tags must be 1 to 30 characters")
+		}
+	}
+
+	return nil
+}
+
+// ValidateInvite checks a collaborator invitation. The owner role cannot be
+// given away.
+func ValidateInvite(req *models.InviteCollaboratorRequest) error {
+	if _, err := mail.ParseAddress(req.Email); err != nil {
+		return NewValidationError("collaborator email is invalid")
+	}
+
+	switch req.Role {
+	case models.RoleEditor, models.RoleApprover, models.RoleViewer:
+		return nil
+	default:
+		return NewValidationError("collaborator role must be editor, approver or viewer")
+	}
+}
+
+// ValidateOrganization checks an organization's name and settings. The
+// boilerplate lists may be empty but not hold empty entries.
+func ValidateOrganization(req *models.OrganizationRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return NewValidationError("organization name is required")
+	}
+
+	settings := req.Settings
+	if settings.DefaultCurrency != "" && !currencyPattern.MatchString(settings.DefaultCurrency) {
+		return NewValidationError("default currency must be a 3-letter ISO code")
+	}
+	if len(settings.Inclusions) > 0 {
+		if err := ValidateStringList(settings.Inclusions, "inclusion"); err != nil {
+			return err
+		}
+	}
+	if len(settings.Exclusions) > 0 {
+		if err := ValidateStringList(settings.Exclusions, "exclusion"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateOrganizationRole checks a member role
+func ValidateOrganizationRole(role string) error {
+	switch role {
+	case models.OrgRoleAdmin, models.OrgRoleAgent:
+		return nil
+	default:
+		return NewValidationError("member role must be admin or agent")
+	}
+}
+
+// MaxCommentLength is the longest comment accepted, in characters
+const MaxCommentLength = 5000
+
+// ValidateComment checks a comment's text
+func ValidateComment(body string) error {
+	if body == "" {
+		return NewValidationError("comment body is required")
+	}
+	if len([]rune(body)) > MaxCommentLength {
+		return NewValidationError(fmt.Sprintf("comment body must be at most %d characters", MaxCommentLength))
+	}
+	return nil
+}
+
+// ValidateApprovalResponse checks an answer to an approval request
+func ValidateApprovalResponse(req *models.RespondApprovalRequest) error {
+	switch req.Decision {
+	case models.ApprovalApproved:
+		return nil
+	case models.ApprovalChangesRequested:
+		if strings.TrimSpace(req.Comment) == "" {
+			return NewValidationError("a comment is required when requesting changes")
+		}
+		return nil
+	default:
+		return NewValidationError("decision must be approved or changes_requested")
+	}
+}
+
+// ValidateCatalogueEntry checks a catalogue entry. Every field but the title
+// is optional.
+func ValidateCatalogueEntry(req *models.CatalogueEntryRequest) error {
+	if strings.TrimSpace(req.Title) == "" {
+		return NewValidationError("catalogue entry title is required")
+	}
+	if req.Period != "" {
+		if _, ok := validPeriods[req.Period]; !ok {
+			return NewValidationError("catalogue entry period must be morning, afternoon, or evening")
+		}
+	}
+	if req.Price < 0 {
+		return NewValidationError("catalogue entry price cannot be negative")
+	}
+	if req.Price > 0 && req.Currency == "" {
+		return NewValidationError("catalogue entry currency is required when a price is given")
+	}
+	if req.Currency != "" && !currencyPattern.MatchString(req.Currency) {
+		return NewValidationError("catalogue entry currency must be a 3-letter ISO code")
+	}
+	if point := req.Coordinates; point != nil {
+		if point.Latitude < -90 || point.Latitude > 90 || point.Longitude < -180 || point.Longitude > 180 {
+			return NewValidationError("catalogue entry coordinates are out of range")
+		}
+	}
+	if len(req.Tags) > 0 {
+		if err := ValidateStringList(req.Tags, "tag"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateShareLink checks a share link request. An expiry must be in the
+// future.
+func ValidateShareLink(req *models.CreateShareLinkRequest, now time.Time) error {
+	if !req.ExpiresAt.IsZero() && !req.ExpiresAt.After(now) {
+		return NewValidationError("expires_at must be in the future")
+	}
+
+	for _, hidden := range req.Hidden {
+		switch hidden {
+		case models.ShareHidePrices, models.ShareHideVendors, models.ShareHideNotes,
+			models.ShareHideBookingReferences, models.ShareHidePaymentPlan, models.ShareHideWarnings:
+		default:
+			return NewValidationError(fmt.Sprintf("cannot hide %q: hidden must list prices, vendors, notes, booking_references, payment_plan or warnings", hidden))
+		}
+	}
+	return nil
+}
+
 // ValidationError represents a validation error
 type ValidationError struct {
 	Message string
@@ -289,7 +895,10 @@ func NewValidationError(message string) *ValidationError {
 	return &ValidationError{Message: message}
 }
 
-// GenerateID generates a unique ID based on current timestamp
+// GenerateID generates a unique ID from the current timestamp and a random
+// suffix, so IDs created in the same second do not collide
 func GenerateID() string {
-	return time.Now().Format("20060102150405")
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return time.Now().Format("20060102150405") + "-" + hex.EncodeToString(suffix)
 }
@@ -0,0 +1,19 @@
+package utils
+
+import "testing"
+
+func TestValidAirportCode(t *testing.T) {
+	for code, want := range map[string]bool{
+		"CDG":  true,  // in the reference data
+		"LFPG": true,  // ICAO code in the reference data
+		"agr":  true,  // well-formed IATA code missing from the reference data
+		"JDH":  true,  // well-formed IATA code missing from the reference data
+		"JIAP": false, // neither IATA nor a known ICAO code
+		"J1P":  false,
+		"":     false,
+	} {
+		if got := ValidAirportCode(code); got != want {
+			t.Errorf("ValidAirportCode(%q) = %v, want %v", code, got, want)
+		}
+	}
+}